@@ -18,7 +18,10 @@ package capacity
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/akrzos/kubeSize/internal/capacity"
 	"github.com/akrzos/kubeSize/internal/kube"
@@ -26,126 +29,456 @@ import (
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
 )
 
 var clusterCmd = &cobra.Command{
 	Use:     "cluster",
 	Aliases: []string{"c"},
 	Short:   "Get cluster capacity data",
-	Long:    `Get metrics and data related to cluster capacity`,
-	PreRun: func(cmd *cobra.Command, args []string) {
+	Long: `Get metrics and data related to cluster capacity
+
+WorkloadAvailableCPU and WorkloadAvailableMemory are the available capacity (allocatable - requests) of nodes which
+accept general workloads, excluding cordoned nodes and nodes with any NoSchedule or NoExecute taint. The
+node.kubernetes.io/not-ready and node.kubernetes.io/unreachable NoExecute taints are not blocking as every pod
+tolerates them by default. The --schedulable-only flag applies the same exclusion to the allocatable and available
+totals.
+
+The --schedulable columns are narrower, SchedulableNodeCount and SchedulableAllocatableCPU/Memory only exclude
+cordoned nodes and control-plane nodes tainted NoSchedule. The table shows the workload available capacity alongside
+them.`,
+	PreRunE: func(cmd *cobra.Command, args []string) error {
 		if err := output.ValidateOutput(*cmd); err != nil {
-			fmt.Fprintf(os.Stderr, "error: %v\n", err)
-			os.Exit(1)
+			return err
+		}
+		flat, _ := cmd.Flags().GetBool("flat")
+		format, _ := cmd.Flags().GetString("output")
+		contexts, _ := cmd.Flags().GetStringArray("context")
+		if flat && (format != "json" || len(contexts) > 1) {
+			return errors.New("--flat requires -o json and at most one --context")
+		}
+		failOver, _ := cmd.Flags().GetFloat64("fail-over")
+		watch, _ := cmd.Flags().GetBool("watch")
+		if failOver < 0 || (failOver > 0 && watch) {
+			return errors.New("--fail-over must be 0 or greater and can not be used with --watch")
 		}
+		return nil
 	},
 	RunE: func(cmd *cobra.Command, args []string) error {
+		return runWatch(cmd, runCluster)
+	},
+}
 
-		clientset, err := kube.CreateClientSet(KubernetesConfigFlags)
-		if err != nil {
-			return errors.Wrap(err, "failed to create clientset")
+// runCluster collects and displays cluster capacity data
+func runCluster(ctx context.Context, cmd *cobra.Command) error {
+	contexts, _ := cmd.Flags().GetStringArray("context")
+	if len(contexts) > 1 {
+		return runClusterContexts(ctx, cmd, contexts)
+	}
+
+	configFlags := KubernetesConfigFlags
+	if len(contexts) == 1 {
+		configFlags = contextConfigFlags(contexts[0])
+	}
+
+	clusterCapacityData, displayUsage, err := collectClusterData(ctx, cmd, configFlags)
+	if err != nil {
+		return err
+	}
+
+	displayOptions := getDisplayOptions(cmd)
+	displayOptions.Schedulable, _ = cmd.Flags().GetBool("schedulable")
+	displayOptions.WorkloadAvailable = displayOptions.Schedulable
+	displayOptions.Usage = displayUsage
+	displayOptions.Flat, _ = cmd.Flags().GetBool("flat")
+	if displayOptions.Metadata != nil && len(contexts) == 1 {
+		displayOptions.Metadata = getMetadata(configFlags)
+	}
+
+	overcommit, _ := cmd.Flags().GetBool("overcommit")
+	if err := writeOutput(cmd, func(out io.Writer) {
+		output.DisplayClusterData(out, *clusterCapacityData, displayOptions)
+		if overcommit {
+			output.DisplayOvercommit(out, "", *clusterCapacityData, displayOptions)
 		}
+		if displayOptions.Explain {
+			output.DisplayExplain(out, displayOptions)
+		}
+	}); err != nil {
+		return err
+	}
+	return checkFailOver(cmd, map[string]*output.ClusterCapacityData{"cluster": clusterCapacityData}, []string{"cluster"})
+}
 
-		nodes, err := clientset.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{})
-		if err != nil {
-			return errors.Wrap(err, "failed to list nodes")
+// checkFailOver returns an exitError with code 2 when the cpu or memory requests utilization of any of the clusters
+// exceeds --fail-over percent of allocatable, nil when --fail-over is 0
+func checkFailOver(cmd *cobra.Command, clusterCapacityData map[string]*output.ClusterCapacityData, names []string) error {
+	failOver, _ := cmd.Flags().GetFloat64("fail-over")
+	if failOver <= 0 {
+		return nil
+	}
+	over := make([]string, 0)
+	for _, name := range names {
+		data := clusterCapacityData[name]
+		if data.RequestsCPUUtilization > failOver || data.RequestsMemoryUtilization > failOver {
+			over = append(over, fmt.Sprintf("%s cpu %.1f%% memory %.1f%%", name, data.RequestsCPUUtilization, data.RequestsMemoryUtilization))
 		}
+	}
+	if len(over) == 0 {
+		return nil
+	}
+	return &exitError{code: 2, message: fmt.Sprintf("requests utilization exceeds --fail-over %g%%: %s", failOver, strings.Join(over, ", "))}
+}
 
-		totalPodsList, err := clientset.CoreV1().Pods("").List(context.TODO(), metav1.ListOptions{})
+// runClusterContexts collects and displays cluster capacity data for each kubeconfig context. Each context is bounded by
+// --timeout-per-context, contexts which fail or time out are skipped and summarized after the reachable contexts output.
+func runClusterContexts(ctx context.Context, cmd *cobra.Command, contexts []string) error {
+	timeout, _ := cmd.Flags().GetDuration("timeout-per-context")
+	contextCapacityData := make(map[string]*output.ClusterCapacityData)
+	contextNames := make([]string, 0, len(contexts))
+	unreachable := make([]string, 0)
+	displayUsage := false
+	for i := range contexts {
+		clusterCapacityData, contextUsage, err := collectContextData(ctx, cmd, contextConfigFlags(contexts[i]), timeout)
 		if err != nil {
-			return errors.Wrap(err, "failed to list pods")
+			unreachable = append(unreachable, fmt.Sprintf("%s: %v", contexts[i], err))
+			continue
 		}
+		contextCapacityData[contexts[i]] = clusterCapacityData
+		contextNames = append(contextNames, contexts[i])
+		displayUsage = displayUsage || contextUsage
+	}
+	if len(contextNames) == 0 {
+		return errors.Errorf("failed to collect cluster capacity data from every context:\n  %s", strings.Join(unreachable, "\n  "))
+	}
 
-		// Note you can have non-terminated pod not assigned to a node (Ex Pending) thus cluster vs node/node-role counts can differ
-		fieldSelector, err := fields.ParseSelector("status.phase!=" + string(corev1.PodSucceeded) + ",status.phase!=" + string(corev1.PodFailed))
-		if err != nil {
-			return errors.Wrap(err, "failed to create fieldSelector")
+	displayOptions := getDisplayOptions(cmd)
+	displayOptions.Schedulable, _ = cmd.Flags().GetBool("schedulable")
+	displayOptions.WorkloadAvailable = displayOptions.Schedulable
+	displayOptions.Usage = displayUsage
+	if displayOptions.Metadata != nil {
+		displayOptions.Metadata.Context = strings.Join(contextNames, ",")
+		displayOptions.Metadata.Server = ""
+	}
+
+	overcommit, _ := cmd.Flags().GetBool("overcommit")
+	if err := writeOutput(cmd, func(out io.Writer) {
+		output.DisplayContextData(out, contextCapacityData, contextNames, displayOptions)
+		if overcommit {
+			for _, contextName := range contextNames {
+				output.DisplayOvercommit(out, contextName, *contextCapacityData[contextName], displayOptions)
+			}
 		}
-		totalNonTermPodsList, err := clientset.CoreV1().Pods("").List(context.TODO(), metav1.ListOptions{FieldSelector: fieldSelector.String()})
+		if displayOptions.Explain {
+			output.DisplayExplain(out, displayOptions)
+		}
+	}); err != nil {
+		return err
+	}
+
+	if len(unreachable) > 0 {
+		fmt.Fprintf(os.Stderr, "warning: %d of %d contexts unreachable:\n  %s\n", len(unreachable), len(contexts), strings.Join(unreachable, "\n  "))
+	}
+	return checkFailOver(cmd, contextCapacityData, contextNames)
+}
+
+// collectContextData collects the cluster capacity data of a context, a timeout greater than 0 bounds every request
+// made for the context
+func collectContextData(ctx context.Context, cmd *cobra.Command, configFlags *genericclioptions.ConfigFlags, timeout time.Duration) (*output.ClusterCapacityData, bool, error) {
+	if timeout <= 0 {
+		return collectClusterData(ctx, cmd, configFlags)
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	clusterCapacityData, displayUsage, err := collectClusterData(ctx, cmd, configFlags)
+	if err != nil && ctx.Err() == context.DeadlineExceeded {
+		return nil, false, errors.Errorf("timed out after %s", timeout)
+	}
+	return clusterCapacityData, displayUsage, err
+}
+
+// collectClusterData collects the cluster capacity data of the context selected by configFlags, the returned bool is
+// false when usage was not requested or the metrics API is unavailable
+func collectClusterData(ctx context.Context, cmd *cobra.Command, configFlags *genericclioptions.ConfigFlags) (*output.ClusterCapacityData, bool, error) {
+	gpuResourceName, _ := cmd.Flags().GetString("gpu-resource-name")
+	gpuResource := corev1.ResourceName(gpuResourceName)
+	extraResources := getExtraResources(cmd)
+	excludeDaemonSets, _ := cmd.Flags().GetBool("exclude-daemonsets")
+	excludeDaemonSetCounts, _ := cmd.Flags().GetBool("exclude-daemonsets-from-counts")
+	schedulableOnly, _ := cmd.Flags().GetBool("schedulable-only")
+	excludeUnready, _ := cmd.Flags().GetBool("exclude-unready")
+
+	clientset, err := kube.CreateClientSet(configFlags)
+	if err != nil {
+		return nil, false, errors.Wrap(err, "failed to create clientset")
+	}
+
+	chunkSize, _ := cmd.Flags().GetInt64("chunk-size")
+
+	nodes, err := kube.ListNodes(ctx, clientset, metav1.ListOptions{}, chunkSize)
+	if err != nil {
+		return nil, false, errors.Wrap(err, "failed to list nodes")
+	}
+
+	// Capacity and allocatable remain cluster wide, only pod counts and requests are scoped by --namespace
+	nsFlag, _ := cmd.Flags().GetString("namespace")
+	podListOptions := metav1.ListOptions{}
+	nonTermPodSelector := "status.phase!=" + string(corev1.PodSucceeded) + ",status.phase!=" + string(corev1.PodFailed)
+
+	if nsFlag != "" {
+		podNamespaceFieldSelector, err := fields.ParseSelector("metadata.namespace=" + nsFlag)
 		if err != nil {
-			return errors.Wrap(err, "failed to list non-term pods")
+			return nil, false, errors.Wrap(err, "failed to create fieldSelector")
 		}
+		podListOptions = metav1.ListOptions{FieldSelector: podNamespaceFieldSelector.String()}
+		nonTermPodSelector += ",metadata.namespace=" + nsFlag
+	}
 
-		clusterCapacityData := new(output.ClusterCapacityData)
+	podListOptions, err = withFieldSelector(cmd, podListOptions)
+	if err != nil {
+		return nil, false, err
+	}
+	totalPodsList, err := kube.ListPods(ctx, clientset, "", podListOptions, chunkSize)
+	if err != nil {
+		return nil, false, errors.Wrap(err, "failed to list pods")
+	}
 
-		for _, node := range nodes.Items {
-			clusterCapacityData.TotalNodeCount++
-			for _, condition := range node.Status.Conditions {
-				if (condition.Type == "Ready") && condition.Status == corev1.ConditionTrue {
-					clusterCapacityData.TotalReadyNodeCount++
-				}
-			}
-			if node.Spec.Unschedulable {
-				clusterCapacityData.TotalUnschedulableNodeCount++
+	// Note you can have non-terminated pod not assigned to a node (Ex Pending) thus cluster vs node/node-role counts can differ
+	fieldSelector, err := fields.ParseSelector(nonTermPodSelector)
+	if err != nil {
+		return nil, false, errors.Wrap(err, "failed to create fieldSelector")
+	}
+	nonTermPodListOptions, err := withFieldSelector(cmd, metav1.ListOptions{FieldSelector: fieldSelector.String()})
+	if err != nil {
+		return nil, false, err
+	}
+	totalNonTermPodsList, err := kube.ListPods(ctx, clientset, "", nonTermPodListOptions, chunkSize)
+	if err != nil {
+		return nil, false, errors.Wrap(err, "failed to list non-term pods")
+	}
+	totalPodsList.Items = namespacePods(cmd, totalPodsList.Items)
+	totalNonTermPodsList.Items = namespacePods(cmd, totalNonTermPodsList.Items)
+
+	clusterCapacityData := new(output.ClusterCapacityData)
+	workloadNodes := sets.NewString()
+	// Nodes excluded from allocatable by --schedulable-only or --exclude-unready, the requests of their pods are excluded too
+	excludedNodes := sets.NewString()
+
+	for _, node := range nodes.Items {
+		clusterCapacityData.TotalNodeCount++
+		ready := capacity.IsNodeReady(node)
+		if ready {
+			clusterCapacityData.TotalReadyNodeCount++
+		}
+		if node.Spec.Unschedulable {
+			clusterCapacityData.TotalUnschedulableNodeCount++
+		}
+		if excludeUnready && !ready {
+			excludedNodes.Insert(node.Name)
+			if capacity.IsSchedulable(node) {
+				clusterCapacityData.SchedulableNodeCount++
 			}
-			clusterCapacityData.TotalCapacityPods.Add(*node.Status.Capacity.Pods())
-			clusterCapacityData.TotalCapacityCPU.Add(*node.Status.Capacity.Cpu())
-			clusterCapacityData.TotalCapacityMemory.Add(*node.Status.Capacity.Memory())
-			clusterCapacityData.TotalCapacityEphemeralStorage.Add(*node.Status.Capacity.StorageEphemeral())
+			continue
+		}
+		clusterCapacityData.TotalCapacityPods.Add(*node.Status.Capacity.Pods())
+		clusterCapacityData.TotalCapacityCPU.Add(*node.Status.Capacity.Cpu())
+		clusterCapacityData.TotalCapacityMemory.Add(*node.Status.Capacity.Memory())
+		clusterCapacityData.TotalCapacityEphemeralStorage.Add(*node.Status.Capacity.StorageEphemeral())
+		clusterCapacityData.TotalCapacityGPU.Add(node.Status.Capacity[gpuResource])
+		clusterCapacityData.Hugepages = addHugepages(clusterCapacityData.Hugepages, node.Status.Capacity, hugepagesCapacity)
+		clusterCapacityData.ExtraResources = addExtraResources(clusterCapacityData.ExtraResources, extraResources, node.Status.Capacity, extraResourcesCapacity)
+		acceptsWorkloads := capacity.AcceptsWorkloads(node)
+		if acceptsWorkloads {
+			workloadNodes.Insert(node.Name)
+			clusterCapacityData.WorkloadAvailableCPU.Add(*node.Status.Allocatable.Cpu())
+			clusterCapacityData.WorkloadAvailableMemory.Add(*node.Status.Allocatable.Memory())
+		}
+		if schedulableOnly && !acceptsWorkloads {
+			excludedNodes.Insert(node.Name)
+		} else {
 			clusterCapacityData.TotalAllocatablePods.Add(*node.Status.Allocatable.Pods())
 			clusterCapacityData.TotalAllocatableCPU.Add(*node.Status.Allocatable.Cpu())
 			clusterCapacityData.TotalAllocatableMemory.Add(*node.Status.Allocatable.Memory())
 			clusterCapacityData.TotalAllocatableEphemeralStorage.Add(*node.Status.Allocatable.StorageEphemeral())
+			clusterCapacityData.TotalAllocatableGPU.Add(node.Status.Allocatable[gpuResource])
+			clusterCapacityData.Hugepages = addHugepages(clusterCapacityData.Hugepages, node.Status.Allocatable, hugepagesAllocatable)
+			clusterCapacityData.ExtraResources = addExtraResources(clusterCapacityData.ExtraResources, extraResources, node.Status.Allocatable, extraResourcesAllocatable)
+			clusterCapacityData.TotalReservedCPU.Add(capacity.Reserved(node, corev1.ResourceCPU))
+			clusterCapacityData.TotalReservedMemory.Add(capacity.Reserved(node, corev1.ResourceMemory))
+			clusterCapacityData.TotalReservedEphemeralStorage.Add(capacity.Reserved(node, corev1.ResourceEphemeralStorage))
 		}
-		clusterCapacityData.TotalUnreadyNodeCount = clusterCapacityData.TotalNodeCount - clusterCapacityData.TotalReadyNodeCount
+		if capacity.IsSchedulable(node) {
+			clusterCapacityData.SchedulableNodeCount++
+			clusterCapacityData.SchedulableAllocatableCPU.Add(*node.Status.Allocatable.Cpu())
+			clusterCapacityData.SchedulableAllocatableMemory.Add(*node.Status.Allocatable.Memory())
+		}
+	}
+	clusterCapacityData.TotalUnreadyNodeCount = clusterCapacityData.TotalNodeCount - clusterCapacityData.TotalReadyNodeCount
 
-		clusterCapacityData.TotalPodCount = len(totalPodsList.Items)
-		clusterCapacityData.TotalNonTermPodCount = len(totalNonTermPodsList.Items)
+	// Usage is node wide unless --namespace is set, then only the usage of pods in the namespace is summed
+	displayUsage, _ := cmd.Flags().GetBool("usage")
+	if displayUsage {
+		var usages map[string]corev1.ResourceList
+		if nsFlag != "" {
+			usages = getPodUsage(ctx, configFlags, nsFlag)
+		} else {
+			usages = getNodeUsage(ctx, configFlags)
+		}
+		if usages == nil {
+			displayUsage = false
+		}
+		for _, usage := range usages {
+			clusterCapacityData.TotalUsageCPU.Add(*usage.Cpu())
+			clusterCapacityData.TotalUsageMemory.Add(*usage.Memory())
+		}
+	}
 
-		for _, pod := range totalNonTermPodsList.Items {
-			for _, container := range pod.Spec.Containers {
-				clusterCapacityData.TotalRequestsCPU.Add(*container.Resources.Requests.Cpu())
-				clusterCapacityData.TotalLimitsCPU.Add(*container.Resources.Limits.Cpu())
-				clusterCapacityData.TotalRequestsMemory.Add(*container.Resources.Requests.Memory())
-				clusterCapacityData.TotalLimitsMemory.Add(*container.Resources.Limits.Memory())
-				clusterCapacityData.TotalRequestsEphemeralStorage.Add(*container.Resources.Requests.StorageEphemeral())
-				clusterCapacityData.TotalLimitsEphemeralStorage.Add(*container.Resources.Limits.StorageEphemeral())
+	// --ephemeral-usage subtracts the ephemeral storage pods actually use rather than their requests, which are rarely set
+	var ephemeralUsage map[string]resource.Quantity
+	if useEphemeralUsage, _ := cmd.Flags().GetBool("ephemeral-usage"); useEphemeralUsage {
+		includedNodes := make([]string, 0, len(nodes.Items))
+		for _, node := range nodes.Items {
+			if !excludedNodes.Has(node.Name) {
+				includedNodes = append(includedNodes, node.Name)
 			}
 		}
+		ephemeralUsage = getEphemeralUsage(ctx, clientset, includedNodes)
+		for _, usage := range ephemeralUsage {
+			clusterCapacityData.TotalUsageEphemeralStorage.Add(usage)
+		}
+	}
 
-		// Populate derived capacity data values
-		clusterCapacityData.TotalAvailablePods = int(clusterCapacityData.TotalAllocatablePods.Value()) - clusterCapacityData.TotalNonTermPodCount
-		clusterCapacityData.TotalAvailableCPU = clusterCapacityData.TotalAllocatableCPU
-		clusterCapacityData.TotalAvailableCPU.Sub(clusterCapacityData.TotalRequestsCPU)
-		clusterCapacityData.TotalAvailableMemory = clusterCapacityData.TotalAllocatableMemory
-		clusterCapacityData.TotalAvailableMemory.Sub(clusterCapacityData.TotalRequestsMemory)
-		clusterCapacityData.TotalAvailableEphemeralStorage = clusterCapacityData.TotalAllocatableEphemeralStorage
-		clusterCapacityData.TotalAvailableEphemeralStorage.Sub(clusterCapacityData.TotalRequestsEphemeralStorage)
-
-		// Populate "Human" readable capacity data values
-		clusterCapacityData.TotalCapacityCPUCores = capacity.ReadableCPU(clusterCapacityData.TotalCapacityCPU)
-		clusterCapacityData.TotalCapacityMemoryGiB = capacity.ReadableMem(clusterCapacityData.TotalCapacityMemory)
-		clusterCapacityData.TotalCapacityEphemeralStorageGB = capacity.ReadableStorage(clusterCapacityData.TotalCapacityEphemeralStorage)
-		clusterCapacityData.TotalAllocatableCPUCores = capacity.ReadableCPU(clusterCapacityData.TotalAllocatableCPU)
-		clusterCapacityData.TotalAllocatableMemoryGiB = capacity.ReadableMem(clusterCapacityData.TotalAllocatableMemory)
-		clusterCapacityData.TotalAllocatableEphemeralStorageGB = capacity.ReadableStorage(clusterCapacityData.TotalAllocatableEphemeralStorage)
-		clusterCapacityData.TotalAvailableCPUCores = capacity.ReadableCPU(clusterCapacityData.TotalAvailableCPU)
-		clusterCapacityData.TotalAvailableMemoryGiB = capacity.ReadableMem(clusterCapacityData.TotalAvailableMemory)
-		clusterCapacityData.TotalAvailableEphemeralStorageGB = capacity.ReadableStorage(clusterCapacityData.TotalAvailableEphemeralStorage)
-		clusterCapacityData.TotalRequestsCPUCores = capacity.ReadableCPU(clusterCapacityData.TotalRequestsCPU)
-		clusterCapacityData.TotalLimitsCPUCores = capacity.ReadableCPU(clusterCapacityData.TotalLimitsCPU)
-		clusterCapacityData.TotalRequestsMemoryGiB = capacity.ReadableMem(clusterCapacityData.TotalRequestsMemory)
-		clusterCapacityData.TotalLimitsMemoryGiB = capacity.ReadableMem(clusterCapacityData.TotalLimitsMemory)
-		clusterCapacityData.TotalRequestsEphemeralStorageGB = capacity.ReadableStorage(clusterCapacityData.TotalRequestsEphemeralStorage)
-		clusterCapacityData.TotalLimitsEphemeralStorageGB = capacity.ReadableStorage(clusterCapacityData.TotalLimitsEphemeralStorage)
-
-		displayDefault, _ := cmd.Flags().GetBool("default-format")
-
-		displayEphemeralStorage, _ := cmd.Flags().GetBool("ephemeral-storage")
+	clusterCapacityData.TotalPodCount = len(totalPodsList.Items)
+	clusterCapacityData.TotalNonTermPodCount = len(totalNonTermPodsList.Items)
+	for _, pod := range totalPodsList.Items {
+		if excludeDaemonSetCounts && capacity.IsDaemonSetPod(pod) {
+			clusterCapacityData.TotalPodCount--
+			continue
+		}
+		countPodPhase(pod.Status.Phase, &clusterCapacityData.RunningPodCount, &clusterCapacityData.PendingPodCount, &clusterCapacityData.SucceededPodCount, &clusterCapacityData.FailedPodCount)
+	}
+	for _, pod := range totalNonTermPodsList.Items {
+		if excludeDaemonSetCounts && capacity.IsDaemonSetPod(pod) {
+			clusterCapacityData.TotalNonTermPodCount--
+			continue
+		}
+		// QoS counts cover the same pods as the QoS requests, pods on excluded nodes are left out of both
+		if !excludedNodes.Has(pod.Spec.NodeName) {
+			countPodQOS(clusterCapacityData, capacity.PodQOSClass(pod))
+		}
+	}
 
-		displayNoHeaders, _ := cmd.Flags().GetBool("no-headers")
+	// Non-term pods on nodes excluded by --schedulable-only or --exclude-unready, these do not consume the remaining
+	// allocatable pods
+	excludedNonTermPodCount := 0
+	for _, pod := range totalNonTermPodsList.Items {
+		if excludeDaemonSets && capacity.IsDaemonSetPod(pod) {
+			continue
+		}
+		podRequests, podLimits := capacity.PodRequestsAndLimits(pod)
+		if workloadNodes.Has(pod.Spec.NodeName) {
+			clusterCapacityData.WorkloadAvailableCPU.Sub(*podRequests.Cpu())
+			clusterCapacityData.WorkloadAvailableMemory.Sub(*podRequests.Memory())
+		} else if excludedNodes.Has(pod.Spec.NodeName) {
+			if !(excludeDaemonSetCounts && capacity.IsDaemonSetPod(pod)) {
+				excludedNonTermPodCount++
+			}
+			continue
+		}
+		clusterCapacityData.TotalRequestsCPU.Add(*podRequests.Cpu())
+		addQOSRequests(clusterCapacityData, capacity.PodQOSClass(pod), podRequests)
+		clusterCapacityData.TotalLimitsCPU.Add(*podLimits.Cpu())
+		clusterCapacityData.TotalRequestsMemory.Add(*podRequests.Memory())
+		clusterCapacityData.TotalLimitsMemory.Add(*podLimits.Memory())
+		clusterCapacityData.TotalRequestsEphemeralStorage.Add(*podRequests.StorageEphemeral())
+		clusterCapacityData.TotalLimitsEphemeralStorage.Add(*podLimits.StorageEphemeral())
+		clusterCapacityData.TotalRequestsGPU.Add(podRequests[gpuResource])
+		clusterCapacityData.Hugepages = addHugepages(clusterCapacityData.Hugepages, podRequests, hugepagesRequests)
+		clusterCapacityData.ExtraResources = addExtraResources(clusterCapacityData.ExtraResources, extraResources, podRequests, extraResourcesRequests)
+	}
 
-		displayFormat, _ := cmd.Flags().GetString("output")
+	// Populate derived capacity data values
+	clusterCapacityData.TotalAvailablePods = int(clusterCapacityData.TotalAllocatablePods.Value()) - clusterCapacityData.TotalNonTermPodCount + excludedNonTermPodCount
+	clusterCapacityData.TotalAvailableCPU = clusterCapacityData.TotalAllocatableCPU
+	clusterCapacityData.TotalAvailableCPU.Sub(clusterCapacityData.TotalRequestsCPU)
+	clusterCapacityData.TotalAvailableMemory = clusterCapacityData.TotalAllocatableMemory
+	clusterCapacityData.TotalAvailableMemory.Sub(clusterCapacityData.TotalRequestsMemory)
+	clusterCapacityData.TotalAvailableEphemeralStorage = clusterCapacityData.TotalAllocatableEphemeralStorage
+	if ephemeralUsage != nil {
+		clusterCapacityData.TotalAvailableEphemeralStorage.Sub(clusterCapacityData.TotalUsageEphemeralStorage)
+	} else {
+		clusterCapacityData.TotalAvailableEphemeralStorage.Sub(clusterCapacityData.TotalRequestsEphemeralStorage)
+	}
+	clusterCapacityData.TotalAvailableGPU = clusterCapacityData.TotalAllocatableGPU
+	clusterCapacityData.TotalAvailableGPU.Sub(clusterCapacityData.TotalRequestsGPU)
+	clusterCapacityData.TotalLimitsAvailableCPU = clusterCapacityData.TotalAllocatableCPU.DeepCopy()
+	clusterCapacityData.TotalLimitsAvailableCPU.Sub(clusterCapacityData.TotalLimitsCPU)
+	clusterCapacityData.TotalLimitsAvailableMemory = clusterCapacityData.TotalAllocatableMemory.DeepCopy()
+	clusterCapacityData.TotalLimitsAvailableMemory.Sub(clusterCapacityData.TotalLimitsMemory)
+	clampAvailable(cmd, &clusterCapacityData.TotalAvailablePods, &clusterCapacityData.TotalAvailableCPU, &clusterCapacityData.TotalAvailableMemory,
+		&clusterCapacityData.TotalAvailableEphemeralStorage, &clusterCapacityData.TotalAvailableGPU, &clusterCapacityData.TotalLimitsAvailableCPU,
+		&clusterCapacityData.TotalLimitsAvailableMemory, &clusterCapacityData.WorkloadAvailableCPU, &clusterCapacityData.WorkloadAvailableMemory)
+	setHugepagesAvailable(cmd, clusterCapacityData.Hugepages)
 
-		output.DisplayClusterData(*clusterCapacityData, displayDefault, !displayNoHeaders, displayEphemeralStorage, displayFormat)
+	// Populate "Human" readable capacity data values
+	clusterCapacityData.TotalCapacityCPUCores = capacity.ReadableCPU(clusterCapacityData.TotalCapacityCPU)
+	clusterCapacityData.TotalCapacityMemoryGiB = capacity.ReadableMem(clusterCapacityData.TotalCapacityMemory)
+	clusterCapacityData.TotalCapacityEphemeralStorageGB = capacity.ReadableStorage(clusterCapacityData.TotalCapacityEphemeralStorage)
+	clusterCapacityData.TotalAllocatableCPUCores = capacity.ReadableCPU(clusterCapacityData.TotalAllocatableCPU)
+	clusterCapacityData.TotalAllocatableMemoryGiB = capacity.ReadableMem(clusterCapacityData.TotalAllocatableMemory)
+	clusterCapacityData.TotalAllocatableEphemeralStorageGB = capacity.ReadableStorage(clusterCapacityData.TotalAllocatableEphemeralStorage)
+	clusterCapacityData.TotalAvailableCPUCores = capacity.ReadableCPU(clusterCapacityData.TotalAvailableCPU)
+	clusterCapacityData.TotalAvailableMemoryGiB = capacity.ReadableMem(clusterCapacityData.TotalAvailableMemory)
+	clusterCapacityData.TotalAvailableEphemeralStorageGB = capacity.ReadableStorage(clusterCapacityData.TotalAvailableEphemeralStorage)
+	clusterCapacityData.TotalReservedCPUCores = capacity.ReadableCPU(clusterCapacityData.TotalReservedCPU)
+	clusterCapacityData.TotalReservedMemoryGiB = capacity.ReadableMem(clusterCapacityData.TotalReservedMemory)
+	clusterCapacityData.TotalReservedEphemeralStorageGB = capacity.ReadableStorage(clusterCapacityData.TotalReservedEphemeralStorage)
+	clusterCapacityData.TotalLimitsAvailableCPUCores = capacity.ReadableCPU(clusterCapacityData.TotalLimitsAvailableCPU)
+	clusterCapacityData.TotalLimitsAvailableMemoryGiB = capacity.ReadableMem(clusterCapacityData.TotalLimitsAvailableMemory)
+	clusterCapacityData.TotalCapacityGPUCount = int(clusterCapacityData.TotalCapacityGPU.Value())
+	clusterCapacityData.TotalAllocatableGPUCount = int(clusterCapacityData.TotalAllocatableGPU.Value())
+	clusterCapacityData.TotalRequestsGPUCount = int(clusterCapacityData.TotalRequestsGPU.Value())
+	clusterCapacityData.TotalAvailableGPUCount = int(clusterCapacityData.TotalAvailableGPU.Value())
+	setHugepagesReadable(clusterCapacityData.Hugepages)
+	clusterCapacityData.RequestsCPUUtilization = capacity.Utilization(clusterCapacityData.TotalRequestsCPU, clusterCapacityData.TotalAllocatableCPU)
+	clusterCapacityData.RequestsMemoryUtilization = capacity.Utilization(clusterCapacityData.TotalRequestsMemory, clusterCapacityData.TotalAllocatableMemory)
+	clusterCapacityData.PodUtilization = capacity.Utilization(*resource.NewQuantity(int64(clusterCapacityData.TotalNonTermPodCount), resource.DecimalSI), clusterCapacityData.TotalAllocatablePods)
+	setOvercommit(clusterCapacityData)
+	setCapacityPct(clusterCapacityData)
+	clusterCapacityData.TotalRequestsCPUCores = capacity.ReadableCPU(clusterCapacityData.TotalRequestsCPU)
+	clusterCapacityData.TotalLimitsCPUCores = capacity.ReadableCPU(clusterCapacityData.TotalLimitsCPU)
+	clusterCapacityData.TotalRequestsMemoryGiB = capacity.ReadableMem(clusterCapacityData.TotalRequestsMemory)
+	clusterCapacityData.TotalLimitsMemoryGiB = capacity.ReadableMem(clusterCapacityData.TotalLimitsMemory)
+	clusterCapacityData.TotalRequestsEphemeralStorageGB = capacity.ReadableStorage(clusterCapacityData.TotalRequestsEphemeralStorage)
+	clusterCapacityData.TotalLimitsEphemeralStorageGB = capacity.ReadableStorage(clusterCapacityData.TotalLimitsEphemeralStorage)
+	clusterCapacityData.SchedulableAllocatableCPUCores = capacity.ReadableCPU(clusterCapacityData.SchedulableAllocatableCPU)
+	clusterCapacityData.SchedulableAllocatableMemoryGiB = capacity.ReadableMem(clusterCapacityData.SchedulableAllocatableMemory)
+	clusterCapacityData.WorkloadAvailableCPUCores = capacity.ReadableCPU(clusterCapacityData.WorkloadAvailableCPU)
+	clusterCapacityData.WorkloadAvailableMemoryGiB = capacity.ReadableMem(clusterCapacityData.WorkloadAvailableMemory)
+	clusterCapacityData.TotalUsageCPUCores = capacity.ReadableCPU(clusterCapacityData.TotalUsageCPU)
+	clusterCapacityData.TotalUsageMemoryGiB = capacity.ReadableMem(clusterCapacityData.TotalUsageMemory)
+	clusterCapacityData.TotalUsageEphemeralStorageGB = capacity.ReadableStorage(clusterCapacityData.TotalUsageEphemeralStorage)
+	setQOSReadable(clusterCapacityData)
 
-		return nil
-	},
+	return clusterCapacityData, displayUsage, nil
 }
 
 func init() {
 	rootCmd.AddCommand(clusterCmd)
-	clusterCmd.Flags().BoolP("ephemeral-storage", "e", false, "Include ephemeral storage capacity data in table output")
+	addCapacityFlags(clusterCmd)
+	clusterCmd.Flags().BoolP("usage", "", false, "Include actual cpu and memory usage from the metrics API (metrics-server) in table output, the usage of pods in --namespace when set")
+	clusterCmd.Flags().BoolP("schedulable", "", false, "Include schedulable node count and allocatable capacity, excluding cordoned and control-plane tainted nodes, and the workload available capacity in table output")
+	clusterCmd.Flags().BoolP("schedulable-only", "", false, "Exclude cordoned nodes and nodes with a NoSchedule or NoExecute taint (other than node.kubernetes.io/not-ready and node.kubernetes.io/unreachable) from the allocatable and available totals")
+	clusterCmd.Flags().StringArrayP("context", "", nil, "The name of the kubeconfig context to use, repeat to query multiple contexts")
+	clusterCmd.Flags().BoolP("flat", "", false, "Output json as a single level object of the cluster totals with snake_case keys (Ex total_allocatable_cpu_cores), requires -o json")
+	clusterCmd.Flags().BoolP("ephemeral-usage", "", false, "Compute available ephemeral storage from the ephemeral storage pods use, read from the kubelet stats summary, instead of their requests")
+	clusterCmd.Flags().BoolP("exclude-unready", "", false, "Exclude the capacity and allocatable of nodes which are not Ready, along with the requests of their pods, node counts are unaffected")
+	clusterCmd.Flags().BoolP("qos", "", false, "Include Guaranteed, Burstable and BestEffort non-terminated pod counts and their cpu and memory requests in table output")
+	clusterCmd.Flags().BoolP("overcommit", "", false, "Print whether cpu and memory requests exceed allocatable (Ex Memory overcommitted: 1.12x) after table output")
+	clusterCmd.Flags().Float64P("fail-over", "", 0, "Exit with code 2 after the output when cpu or memory requests exceed this percent of allocatable, 0 disables the check")
+	clusterCmd.Flags().DurationP("timeout-per-context", "", 0, "Maximum time to query each context when --context is repeated, unreachable contexts are skipped and summarized. 0 disables the timeout")
 }
@@ -0,0 +1,48 @@
+/*
+Copyright © 2021 Alex Krzos akrzos@redhat.com
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package capacity
+
+import (
+	"testing"
+
+	"github.com/akrzos/kubeSize/internal/output"
+	"github.com/pkg/errors"
+)
+
+func TestCheckFailOver(t *testing.T) {
+	clusterCapacityData := map[string]*output.ClusterCapacityData{
+		"prod":    {RequestsCPUUtilization: 70, RequestsMemoryUtilization: 91},
+		"staging": {RequestsCPUUtilization: 40, RequestsMemoryUtilization: 50},
+	}
+	names := []string{"prod", "staging"}
+
+	if err := checkFailOver(clusterCmd, clusterCapacityData, names); err != nil {
+		t.Errorf("checkFailOver without --fail-over = %v, want nil", err)
+	}
+	setFlags(t, clusterCmd, map[string]string{"fail-over": "95"})
+	if err := checkFailOver(clusterCmd, clusterCapacityData, names); err != nil {
+		t.Errorf("checkFailOver --fail-over 95 = %v, want nil", err)
+	}
+	setFlags(t, clusterCmd, map[string]string{"fail-over": "90"})
+	err := checkFailOver(clusterCmd, clusterCapacityData, names)
+	var exit *exitError
+	if !errors.As(err, &exit) || exit.code != 2 {
+		t.Fatalf("checkFailOver --fail-over 90 = %v, want an exit code 2 error", err)
+	}
+	if want := "requests utilization exceeds --fail-over 90%: prod cpu 70.0% memory 91.0%"; exit.message != want {
+		t.Errorf("message = %q, want %q", exit.message, want)
+	}
+}
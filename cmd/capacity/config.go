@@ -0,0 +1,99 @@
+/*
+Copyright © 2021 Alex Krzos akrzos@redhat.com
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package capacity
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/yaml"
+)
+
+// configPaths returns the config files searched in order when --config is not set, $XDG_CONFIG_HOME/kubesize/config.yaml
+// (default ~/.config/kubesize/config.yaml) then ~/.kubesize.yaml
+func configPaths() []string {
+	paths := make([]string, 0, 2)
+	home, _ := os.UserHomeDir()
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if configHome == "" && home != "" {
+		configHome = filepath.Join(home, ".config")
+	}
+	if configHome != "" {
+		paths = append(paths, filepath.Join(configHome, "kubesize", "config.yaml"))
+	}
+	if home != "" {
+		paths = append(paths, filepath.Join(home, ".kubesize.yaml"))
+	}
+	return paths
+}
+
+// loadConfig sets the flags of cmd which were not set on the command line to the values in the --config file, or the
+// first config file found in configPaths. Keys are flag names, keys which are not flags of cmd are ignored so one file
+// can hold the defaults of every sub-command.
+func loadConfig(cmd *cobra.Command) error {
+	path, _ := cmd.Flags().GetString("config")
+	if path == "" {
+		for _, configPath := range configPaths() {
+			if _, err := os.Stat(configPath); err == nil {
+				path = configPath
+				break
+			}
+		}
+		if path == "" {
+			return nil
+		}
+	}
+	configData, err := ioutil.ReadFile(path)
+	if err != nil {
+		return errors.Wrap(err, "failed to read config file")
+	}
+	config := make(map[string]interface{})
+	if err := yaml.Unmarshal(configData, &config); err != nil {
+		return errors.Wrapf(err, "failed to parse config file %s", path)
+	}
+	for name, value := range config {
+		flag := cmd.Flags().Lookup(name)
+		if flag == nil || flag.Changed || name == "config" {
+			continue
+		}
+		if err := setConfigFlag(flag, value); err != nil {
+			return errors.Wrapf(err, "invalid %s value in config file %s", name, path)
+		}
+	}
+	return nil
+}
+
+// setConfigFlag sets a flag to a config file value, a list sets each value of a slice or array flag
+func setConfigFlag(flag *pflag.Flag, value interface{}) error {
+	values, ok := value.([]interface{})
+	if !ok {
+		return flag.Value.Set(fmt.Sprint(value))
+	}
+	strValues := make([]string, 0, len(values))
+	for _, v := range values {
+		strValues = append(strValues, fmt.Sprint(v))
+	}
+	slice, ok := flag.Value.(pflag.SliceValue)
+	if !ok {
+		return errors.New("a list is only valid for flags accepting multiple values")
+	}
+	return slice.Replace(strValues)
+}
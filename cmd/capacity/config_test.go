@@ -0,0 +1,70 @@
+/*
+Copyright © 2021 Alex Krzos akrzos@redhat.com
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package capacity
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestLoadConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "kubesize.yaml")
+	config := "output: json\nprecision: 2\nutilization: true\nextra-resources: [example.com/fpga, example.com/gpu]\nunknown-flag: ignored\n"
+	if err := ioutil.WriteFile(path, []byte(config), 0600); err != nil {
+		t.Fatal(err)
+	}
+	cmd := &cobra.Command{}
+	cmd.Flags().String("config", "", "")
+	cmd.Flags().StringP("output", "o", "table", "")
+	cmd.Flags().Int("precision", 1, "")
+	cmd.Flags().Bool("utilization", false, "")
+	cmd.Flags().StringSlice("extra-resources", nil, "")
+	if err := cmd.ParseFlags([]string{"--config", path, "--precision", "3"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := loadConfig(cmd); err != nil {
+		t.Fatalf("loadConfig: %v", err)
+	}
+	if output, _ := cmd.Flags().GetString("output"); output != "json" {
+		t.Errorf("output = %s, want json from the config file", output)
+	}
+	if precision, _ := cmd.Flags().GetInt("precision"); precision != 3 {
+		t.Errorf("precision = %d, want 3 from the command line", precision)
+	}
+	if utilization, _ := cmd.Flags().GetBool("utilization"); !utilization {
+		t.Error("utilization = false, want true from the config file")
+	}
+	if extraResources, _ := cmd.Flags().GetStringSlice("extra-resources"); len(extraResources) != 2 || extraResources[1] != "example.com/gpu" {
+		t.Errorf("extra-resources = %v, want [example.com/fpga example.com/gpu]", extraResources)
+	}
+}
+
+func TestLoadConfigInvalidValue(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "kubesize.yaml")
+	if err := ioutil.WriteFile(path, []byte("precision: two\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	cmd := &cobra.Command{}
+	cmd.Flags().String("config", path, "")
+	cmd.Flags().Int("precision", 1, "")
+	if err := loadConfig(cmd); err == nil {
+		t.Error("loadConfig with a non-integer precision succeeded, want an error")
+	}
+}
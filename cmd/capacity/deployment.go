@@ -0,0 +1,149 @@
+/*
+Copyright © 2021 Alex Krzos akrzos@redhat.com
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package capacity
+
+import (
+	"context"
+	"io"
+	"sort"
+
+	"github.com/akrzos/kubeSize/internal/capacity"
+	"github.com/akrzos/kubeSize/internal/kube"
+	"github.com/akrzos/kubeSize/internal/output"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var deploymentCmd = &cobra.Command{
+	Use:     "deployment",
+	Aliases: []string{"deploy"},
+	Short:   "Get deployment capacity data",
+	Long:    `Get requests and limits of non-terminated pods grouped by their owning deployment`,
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		return output.ValidateOutput(*cmd)
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runWatch(cmd, runDeployment)
+	},
+}
+
+// runDeployment collects and displays deployment capacity data
+func runDeployment(ctx context.Context, cmd *cobra.Command) error {
+	// Like kubectl get deployments, the kubeconfig context namespace is used unless --namespace or --all-namespaces is set
+	namespace := ""
+	if allNamespaces, _ := cmd.Flags().GetBool("all-namespaces"); !allNamespaces {
+		contextNamespace, _, err := KubernetesConfigFlags.ToRawKubeConfigLoader().Namespace()
+		if err != nil {
+			return errors.Wrap(err, "failed to read namespace")
+		}
+		namespace = contextNamespace
+	}
+
+	clientset, err := kube.CreateClientSet(KubernetesConfigFlags)
+	if err != nil {
+		return errors.Wrap(err, "failed to create clientset")
+	}
+
+	chunkSize, _ := cmd.Flags().GetInt64("chunk-size")
+
+	replicaSets, err := clientset.AppsV1().ReplicaSets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return errors.Wrap(err, "failed to list replicasets")
+	}
+
+	podListOptions, err := withFieldSelector(cmd, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+
+	pods, err := kube.ListPods(ctx, clientset, namespace, podListOptions, chunkSize)
+	if err != nil {
+		return errors.Wrap(err, "failed to list pods")
+	}
+
+	// Deployments own pods through a ReplicaSet, map each ReplicaSet to its owning deployment
+	replicaSetDeployments := make(map[string]string)
+	for _, replicaSet := range replicaSets.Items {
+		for _, owner := range replicaSet.OwnerReferences {
+			if owner.Kind == "Deployment" {
+				replicaSetDeployments[replicaSet.Namespace+"/"+replicaSet.Name] = owner.Name
+			}
+		}
+	}
+
+	deploymentCapacityData := make(map[string]*output.DeploymentCapacityData)
+	deploymentNames := make([]string, 0)
+	deploymentCapacityData["*total*"] = &output.DeploymentCapacityData{Name: "*total*"}
+	deploymentCapacityData["*standalone*"] = &output.DeploymentCapacityData{Name: "*standalone*"}
+
+	for _, pod := range pods.Items {
+		deploymentName := "*standalone*"
+		for _, owner := range pod.OwnerReferences {
+			if owner.Kind != "ReplicaSet" {
+				continue
+			}
+			if deployment, ok := replicaSetDeployments[pod.Namespace+"/"+owner.Name]; ok {
+				deploymentName = pod.Namespace + "/" + deployment
+				if _, ok := deploymentCapacityData[deploymentName]; !ok {
+					deploymentNames = append(deploymentNames, deploymentName)
+					deploymentCapacityData[deploymentName] = &output.DeploymentCapacityData{Name: deployment, Namespace: pod.Namespace}
+				}
+			}
+		}
+		deploymentCapacityData[deploymentName].TotalPodCount++
+		deploymentCapacityData["*total*"].TotalPodCount++
+		if (pod.Status.Phase != corev1.PodSucceeded) && (pod.Status.Phase != corev1.PodFailed) {
+			podRequests, podLimits := capacity.PodRequestsAndLimits(pod)
+			for _, k := range []string{deploymentName, "*total*"} {
+				deploymentCapacityData[k].TotalNonTermPodCount++
+				deploymentCapacityData[k].TotalRequestsCPU.Add(*podRequests.Cpu())
+				deploymentCapacityData[k].TotalLimitsCPU.Add(*podLimits.Cpu())
+				deploymentCapacityData[k].TotalRequestsMemory.Add(*podRequests.Memory())
+				deploymentCapacityData[k].TotalLimitsMemory.Add(*podLimits.Memory())
+			}
+		}
+	}
+
+	sort.Strings(deploymentNames)
+	reverseNames(cmd, deploymentNames)
+	deploymentNames = append(deploymentNames, "*standalone*")
+
+	// Populate "Human" readable capacity data values
+	for _, deployment := range append([]string{"*total*"}, deploymentNames...) {
+		deploymentCapacityData[deployment].TotalRequestsCPUCores = capacity.ReadableCPU(deploymentCapacityData[deployment].TotalRequestsCPU)
+		deploymentCapacityData[deployment].TotalLimitsCPUCores = capacity.ReadableCPU(deploymentCapacityData[deployment].TotalLimitsCPU)
+		deploymentCapacityData[deployment].TotalRequestsMemoryGiB = capacity.ReadableMem(deploymentCapacityData[deployment].TotalRequestsMemory)
+		deploymentCapacityData[deployment].TotalLimitsMemoryGiB = capacity.ReadableMem(deploymentCapacityData[deployment].TotalLimitsMemory)
+	}
+
+	displayTotal, _ := cmd.Flags().GetBool("display-total")
+
+	if displayTotal {
+		deploymentNames = append(deploymentNames, "*total*")
+	}
+
+	return writeOutput(cmd, func(out io.Writer) {
+		output.DisplayDeploymentData(out, deploymentCapacityData, deploymentNames, getDisplayOptions(cmd))
+	})
+}
+
+func init() {
+	rootCmd.AddCommand(deploymentCmd)
+	deploymentCmd.Flags().BoolP("all-namespaces", "A", false, "List deployments across all namespaces")
+	deploymentCmd.Flags().BoolP("display-total", "t", false, "Display sum of all deployment capacity data in table output")
+}
@@ -0,0 +1,273 @@
+/*
+Copyright © 2021 Alex Krzos akrzos@redhat.com
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package capacity
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/akrzos/kubeSize/internal/capacity"
+	"github.com/akrzos/kubeSize/internal/kube"
+	"github.com/akrzos/kubeSize/internal/output"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+)
+
+const zoneLabel string = "topology.kubernetes.io/zone"
+
+// nodeAvailableData is the remaining schedulable capacity of a node after non-terminated pod requests
+type nodeAvailableData struct {
+	node   corev1.Node
+	ready  bool
+	cpu    resource.Quantity
+	memory resource.Quantity
+	pods   int
+}
+
+var diagnoseCmd = &cobra.Command{
+	Use:     "diagnose",
+	Aliases: []string{"diag"},
+	Short:   "Get hints why pending pods can not be scheduled",
+	Long:    `Compare the requests of each pending pod with per node available capacity, taints and node selectors to suggest why it can not schedule`,
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		return output.ValidateOutput(*cmd)
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runWatch(cmd, runDiagnose)
+	},
+}
+
+// runDiagnose collects and displays scheduling hints for pending pods
+func runDiagnose(ctx context.Context, cmd *cobra.Command) error {
+	clientset, err := kube.CreateClientSet(KubernetesConfigFlags)
+	if err != nil {
+		return errors.Wrap(err, "failed to create clientset")
+	}
+
+	chunkSize, _ := cmd.Flags().GetInt64("chunk-size")
+
+	nodes, err := kube.ListNodes(ctx, clientset, metav1.ListOptions{}, chunkSize)
+	if err != nil {
+		return errors.Wrap(err, "failed to list nodes")
+	}
+
+	fieldSelector, err := fields.ParseSelector("status.phase!=" + string(corev1.PodSucceeded) + ",status.phase!=" + string(corev1.PodFailed))
+	if err != nil {
+		return errors.Wrap(err, "failed to create fieldSelector")
+	}
+
+	// Node availability accounts for every non-terminated pod, --field-selector only narrows the pending pods diagnosed
+	nonTermPods, err := kube.ListPods(ctx, clientset, "", metav1.ListOptions{FieldSelector: fieldSelector.String()}, chunkSize)
+	if err != nil {
+		return errors.Wrap(err, "failed to list pods")
+	}
+
+	nsFlag, _ := cmd.Flags().GetString("namespace")
+
+	nodesAvailableData := make(map[string]*nodeAvailableData)
+	nodeNames := make([]string, 0, len(nodes.Items))
+	for _, node := range nodes.Items {
+		nodeNames = append(nodeNames, node.Name)
+		nodesAvailableData[node.Name] = &nodeAvailableData{
+			node:   node,
+			cpu:    node.Status.Allocatable.Cpu().DeepCopy(),
+			memory: node.Status.Allocatable.Memory().DeepCopy(),
+			pods:   int(node.Status.Allocatable.Pods().Value()),
+		}
+		for _, condition := range node.Status.Conditions {
+			if (condition.Type == "Ready") && condition.Status == corev1.ConditionTrue {
+				nodesAvailableData[node.Name].ready = true
+			}
+		}
+	}
+	sort.Strings(nodeNames)
+
+	var pendingPods []corev1.Pod
+	for _, pod := range nonTermPods.Items {
+		if pod.Spec.NodeName == "" {
+			if pod.Status.Phase == corev1.PodPending && (nsFlag == "" || pod.Namespace == nsFlag) {
+				pendingPods = append(pendingPods, pod)
+			}
+			continue
+		}
+		if nodeData, ok := nodesAvailableData[pod.Spec.NodeName]; ok {
+			nodeData.pods--
+			podRequests, _ := capacity.PodRequestsAndLimits(pod)
+			nodeData.cpu.Sub(*podRequests.Cpu())
+			nodeData.memory.Sub(*podRequests.Memory())
+		}
+	}
+
+	if podFieldSelector, _ := cmd.Flags().GetString("field-selector"); podFieldSelector != "" {
+		pendingFieldSelector, err := fields.ParseSelector("status.phase=" + string(corev1.PodPending) + ",spec.nodeName=")
+		if err != nil {
+			return errors.Wrap(err, "failed to create fieldSelector")
+		}
+		pendingListOptions, err := withFieldSelector(cmd, metav1.ListOptions{FieldSelector: pendingFieldSelector.String()})
+		if err != nil {
+			return err
+		}
+		pendingPodList, err := kube.ListPods(ctx, clientset, nsFlag, pendingListOptions, chunkSize)
+		if err != nil {
+			return errors.Wrap(err, "failed to list pending pods")
+		}
+		pendingPods = pendingPodList.Items
+	}
+
+	podDiagnosisData := make(map[string]*output.PodDiagnosisData)
+	podNames := make([]string, 0, len(pendingPods))
+	for _, pod := range pendingPods {
+		podName := pod.Namespace + "/" + pod.Name
+		podNames = append(podNames, podName)
+		podData := &output.PodDiagnosisData{Namespace: pod.Namespace, Pod: pod.Name}
+		podRequests, _ := capacity.PodRequestsAndLimits(pod)
+		podData.RequestsCPU.Add(*podRequests.Cpu())
+		podData.RequestsMemory.Add(*podRequests.Memory())
+		podData.RequestsCPUCores = capacity.ReadableCPU(podData.RequestsCPU)
+		podData.RequestsMemoryGiB = capacity.ReadableMem(podData.RequestsMemory)
+		podData.FitNodeCount, podData.Hints = diagnosePod(pod, podData, nodeNames, nodesAvailableData)
+		podDiagnosisData[podName] = podData
+	}
+	sort.Strings(podNames)
+	reverseNames(cmd, podNames)
+
+	return writeOutput(cmd, func(out io.Writer) {
+		output.DisplayDiagnosisData(out, podDiagnosisData, podNames, getDisplayOptions(cmd))
+	})
+}
+
+// diagnosePod filters the nodes the same way the scheduler predicates would and returns the number of nodes the pod
+// fits on along with hints describing which filter excluded the remaining nodes
+func diagnosePod(pod corev1.Pod, podData *output.PodDiagnosisData, nodeNames []string, nodesAvailableData map[string]*nodeAvailableData) (int, []string) {
+	var hints []string
+	var notReady, selectorMismatch, untolerated, noPods, noCPU, noMemory, fit int
+	var maxCPU, maxMemory resource.Quantity
+	eligible := 0
+	for _, nodeName := range nodeNames {
+		nodeData := nodesAvailableData[nodeName]
+		if !nodeData.ready || nodeData.node.Spec.Unschedulable {
+			notReady++
+			continue
+		}
+		if !matchesNodeSelector(pod, nodeData.node) {
+			selectorMismatch++
+			continue
+		}
+		if !toleratesTaints(pod, nodeData.node) {
+			untolerated++
+			continue
+		}
+		eligible++
+		if nodeData.cpu.Cmp(maxCPU) > 0 {
+			maxCPU = nodeData.cpu.DeepCopy()
+		}
+		if nodeData.memory.Cmp(maxMemory) > 0 {
+			maxMemory = nodeData.memory.DeepCopy()
+		}
+		podFits := true
+		if nodeData.pods < 1 {
+			noPods++
+			podFits = false
+		}
+		if nodeData.cpu.Cmp(podData.RequestsCPU) < 0 {
+			noCPU++
+			podFits = false
+		}
+		if nodeData.memory.Cmp(podData.RequestsMemory) < 0 {
+			noMemory++
+			podFits = false
+		}
+		if podFits {
+			fit++
+		}
+	}
+
+	if len(nodeNames) == 0 {
+		return 0, []string{"cluster has no nodes"}
+	}
+	if notReady > 0 {
+		hints = append(hints, fmt.Sprintf("%d node(s) not ready or cordoned", notReady))
+	}
+	if selectorMismatch > 0 {
+		if zone, ok := pod.Spec.NodeSelector[zoneLabel]; ok && eligible > 0 && fit == 0 {
+			hints = append(hints, fmt.Sprintf("all nodes in zone %s are full", zone))
+		}
+		hints = append(hints, fmt.Sprintf("%d node(s) do not match nodeSelector", selectorMismatch))
+	}
+	if untolerated > 0 {
+		hints = append(hints, fmt.Sprintf("tolerations required for %d tainted node(s)", untolerated))
+	}
+	if eligible == 0 {
+		hints = append(hints, "no node passes readiness, nodeSelector and taint checks")
+		return 0, hints
+	}
+	if noCPU == eligible {
+		hints = append(hints, fmt.Sprintf("no node has %.1f cores free (max %.1f)", podData.RequestsCPUCores, capacity.ReadableCPU(maxCPU)))
+	} else if noCPU > 0 {
+		hints = append(hints, fmt.Sprintf("%d node(s) have insufficient cpu", noCPU))
+	}
+	if noMemory == eligible {
+		hints = append(hints, fmt.Sprintf("no node has %.1f GiB memory free (max %.1f)", podData.RequestsMemoryGiB, capacity.ReadableMem(maxMemory)))
+	} else if noMemory > 0 {
+		hints = append(hints, fmt.Sprintf("%d node(s) have insufficient memory", noMemory))
+	}
+	if noPods > 0 {
+		hints = append(hints, fmt.Sprintf("%d node(s) are at their pod limit", noPods))
+	}
+	if fit > 0 {
+		hints = append(hints, fmt.Sprintf("fits on %d node(s), check affinity, volumes and scheduler events", fit))
+	}
+	return fit, hints
+}
+
+func matchesNodeSelector(pod corev1.Pod, node corev1.Node) bool {
+	for key, value := range pod.Spec.NodeSelector {
+		if node.Labels[key] != value {
+			return false
+		}
+	}
+	return true
+}
+
+func toleratesTaints(pod corev1.Pod, node corev1.Node) bool {
+	for i := range node.Spec.Taints {
+		taint := &node.Spec.Taints[i]
+		if taint.Effect == corev1.TaintEffectPreferNoSchedule {
+			continue
+		}
+		tolerated := false
+		for j := range pod.Spec.Tolerations {
+			if pod.Spec.Tolerations[j].ToleratesTaint(taint) {
+				tolerated = true
+				break
+			}
+		}
+		if !tolerated {
+			return false
+		}
+	}
+	return true
+}
+
+func init() {
+	rootCmd.AddCommand(diagnoseCmd)
+}
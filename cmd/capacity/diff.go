@@ -0,0 +1,227 @@
+/*
+Copyright © 2021 Alex Krzos akrzos@redhat.com
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package capacity
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"sort"
+
+	"github.com/akrzos/kubeSize/internal/output"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// diffSnapshotType is the capacity data struct of a snapshot, rows is set when the snapshot is a map of the struct keyed
+// by row name rather than a single struct
+type diffSnapshotType struct {
+	dataType reflect.Type
+	rows     bool
+}
+
+var (
+	quantityType = reflect.TypeOf(resource.Quantity{})
+
+	// diffSnapshotTypes are the snapshot types by --type name. The node-role command outputs the same
+	// ClusterCapacityData struct as the cluster command, one row per role.
+	diffSnapshotTypes = map[string]diffSnapshotType{
+		"cluster":   {dataType: reflect.TypeOf(output.ClusterCapacityData{})},
+		"node-role": {dataType: reflect.TypeOf(output.ClusterCapacityData{}), rows: true},
+		"node":      {dataType: reflect.TypeOf(output.NodeCapacityData{}), rows: true},
+		"namespace": {dataType: reflect.TypeOf(output.NamespaceCapacityData{}), rows: true},
+	}
+)
+
+var diffCmd = &cobra.Command{
+	Use:   "diff OLD NEW",
+	Short: "Compare two json snapshots of capacity data",
+	Long:  `Compare two json snapshots saved from the cluster, node-role, node or namespace commands (-o json) and print the fields that changed. No cluster access is needed.`,
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		snapshotType, _ := cmd.Flags().GetString("type")
+		displayAll, _ := cmd.Flags().GetBool("all")
+
+		if _, ok := diffSnapshotTypes[snapshotType]; !ok && snapshotType != "auto" {
+			return errors.Errorf("Type \"%s\" is invalid. Valid values are [auto cluster node-role node namespace]", snapshotType)
+		}
+
+		oldData, err := readSnapshot(args[0])
+		if err != nil {
+			return err
+		}
+		newData, err := readSnapshot(args[1])
+		if err != nil {
+			return err
+		}
+
+		if snapshotType == "auto" {
+			snapshotType, err = detectSnapshotType(oldData)
+			if err != nil {
+				return err
+			}
+		}
+		snapshot := diffSnapshotTypes[snapshotType]
+
+		if !snapshot.rows {
+			oldValue, newValue := reflect.New(snapshot.dataType), reflect.New(snapshot.dataType)
+			if err := json.Unmarshal(oldData, oldValue.Interface()); err != nil {
+				return errors.Wrapf(err, "failed to parse snapshot %s", args[0])
+			}
+			if err := json.Unmarshal(newData, newValue.Interface()); err != nil {
+				return errors.Wrapf(err, "failed to parse snapshot %s", args[1])
+			}
+			return writeOutput(cmd, func(out io.Writer) {
+				for _, line := range fieldDiffs(oldValue.Elem(), newValue.Elem(), displayAll) {
+					fmt.Fprintln(out, line)
+				}
+			})
+		}
+
+		mapType := reflect.MapOf(reflect.TypeOf(""), reflect.PtrTo(snapshot.dataType))
+		oldRows, newRows := reflect.New(mapType), reflect.New(mapType)
+		if err := json.Unmarshal(oldData, oldRows.Interface()); err != nil {
+			return errors.Wrapf(err, "failed to parse snapshot %s", args[0])
+		}
+		if err := json.Unmarshal(newData, newRows.Interface()); err != nil {
+			return errors.Wrapf(err, "failed to parse snapshot %s", args[1])
+		}
+		return writeOutput(cmd, func(out io.Writer) {
+			printRowDiffs(out, oldRows.Elem(), newRows.Elem(), displayAll)
+		})
+	},
+}
+
+// readSnapshot reads a json snapshot, unwrapping the metadata envelope when present
+func readSnapshot(fileName string) ([]byte, error) {
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read snapshot %s", fileName)
+	}
+	data, err = output.UnwrapEnvelope(data)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse snapshot %s", fileName)
+	}
+	return data, nil
+}
+
+// detectSnapshotType guesses the snapshot type from the field names of the data, or of its first row
+func detectSnapshotType(data []byte) (string, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return "", errors.Wrap(err, "failed to parse snapshot")
+	}
+	if _, ok := fields["TotalNodeCount"]; ok {
+		return "cluster", nil
+	}
+	for _, row := range fields {
+		var rowFields map[string]json.RawMessage
+		if err := json.Unmarshal(row, &rowFields); err == nil {
+			if _, ok := rowFields["Roles"]; ok {
+				return "node", nil
+			}
+			if _, ok := rowFields["TotalNodeCount"]; ok {
+				return "node-role", nil
+			}
+			if _, ok := rowFields["TotalUnassignedNodePodCount"]; ok {
+				return "namespace", nil
+			}
+		}
+		break
+	}
+	return "", errors.New("unable to detect the snapshot type, set --type")
+}
+
+// printRowDiffs prints the changed fields of each row present in both snapshots and the rows added or removed
+func printRowDiffs(out io.Writer, oldRows, newRows reflect.Value, displayAll bool) {
+	names := make([]string, 0, newRows.Len())
+	for _, key := range newRows.MapKeys() {
+		names = append(names, key.String())
+	}
+	for _, key := range oldRows.MapKeys() {
+		if !newRows.MapIndex(key).IsValid() {
+			names = append(names, key.String())
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		oldRow, newRow := oldRows.MapIndex(reflect.ValueOf(name)), newRows.MapIndex(reflect.ValueOf(name))
+		switch {
+		case !oldRow.IsValid():
+			fmt.Fprintf(out, "%s: added\n", name)
+		case !newRow.IsValid():
+			fmt.Fprintf(out, "%s: removed\n", name)
+		default:
+			lines := fieldDiffs(oldRow.Elem(), newRow.Elem(), displayAll)
+			if len(lines) == 0 {
+				continue
+			}
+			fmt.Fprintf(out, "%s:\n", name)
+			for _, line := range lines {
+				fmt.Fprintf(out, "  %s\n", line)
+			}
+		}
+	}
+}
+
+// fieldDiffs returns a line per field of two capacity data structs that changed, or per field when displayAll is set
+func fieldDiffs(oldValue, newValue reflect.Value, displayAll bool) []string {
+	lines := make([]string, 0)
+	for i := 0; i < oldValue.NumField(); i++ {
+		name := oldValue.Type().Field(i).Name
+		oldField, newField := oldValue.Field(i), newValue.Field(i)
+		var changed bool
+		var line string
+		switch {
+		case oldField.Type() == quantityType:
+			oldQuantity, newQuantity := oldField.Interface().(resource.Quantity), newField.Interface().(resource.Quantity)
+			delta := newQuantity.DeepCopy()
+			delta.Sub(oldQuantity)
+			sign := ""
+			if delta.Sign() > 0 {
+				sign = "+"
+			}
+			changed = oldQuantity.Cmp(newQuantity) != 0
+			line = fmt.Sprintf("%s: %s -> %s (%s%s)", name, &oldQuantity, &newQuantity, sign, &delta)
+		case oldField.Kind() == reflect.Float64:
+			changed = oldField.Float() != newField.Float()
+			line = fmt.Sprintf("%s: %.1f -> %.1f (%+.1f)", name, oldField.Float(), newField.Float(), newField.Float()-oldField.Float())
+		case oldField.Kind() == reflect.Int:
+			changed = oldField.Int() != newField.Int()
+			line = fmt.Sprintf("%s: %d -> %d (%+d)", name, oldField.Int(), newField.Int(), newField.Int()-oldField.Int())
+		default:
+			// Maps (Ex Hugepages, Roles) and other fields are compared and shown in their json form
+			oldJSON, _ := json.Marshal(oldField.Interface())
+			newJSON, _ := json.Marshal(newField.Interface())
+			changed = string(oldJSON) != string(newJSON)
+			line = fmt.Sprintf("%s: %s -> %s", name, oldJSON, newJSON)
+		}
+		if changed || displayAll {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+	diffCmd.Flags().StringP("type", "", "auto", "Type of capacity data in the snapshots. One of: auto|cluster|node-role|node|namespace")
+	diffCmd.Flags().BoolP("all", "", false, "Print every field, not only the fields that changed")
+}
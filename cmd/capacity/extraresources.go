@@ -0,0 +1,76 @@
+/*
+Copyright © 2021 Alex Krzos akrzos@redhat.com
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package capacity
+
+import (
+	"strings"
+
+	"github.com/akrzos/kubeSize/internal/output"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// Selectors for the extended resource quantity that addExtraResources accumulates into
+var (
+	extraResourcesCapacity    = func(data *output.ResourceTriple) *resource.Quantity { return &data.Capacity }
+	extraResourcesAllocatable = func(data *output.ResourceTriple) *resource.Quantity { return &data.Allocatable }
+	extraResourcesRequests    = func(data *output.ResourceTriple) *resource.Quantity { return &data.Requests }
+)
+
+// getExtraResources returns the resource names of --extra-resources, empty names are ignored
+func getExtraResources(cmd *cobra.Command) []string {
+	extraResources, _ := cmd.Flags().GetStringSlice("extra-resources")
+	names := make([]string, 0, len(extraResources))
+	for _, name := range extraResources {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// addExtraResources adds each named resource in resources to the quantity chosen by field. Every name is added even
+// when absent from resources so each row has a value for every --extra-resources column. The map is allocated on first
+// use and returned so nil maps can be passed in.
+func addExtraResources(extraResources map[string]*output.ResourceTriple, names []string, resources corev1.ResourceList, field func(*output.ResourceTriple) *resource.Quantity) map[string]*output.ResourceTriple {
+	for _, name := range names {
+		if extraResources == nil {
+			extraResources = make(map[string]*output.ResourceTriple)
+		}
+		if _, ok := extraResources[name]; !ok {
+			extraResources[name] = &output.ResourceTriple{}
+		}
+		field(extraResources[name]).Add(resources[corev1.ResourceName(name)])
+	}
+	return extraResources
+}
+
+// sumExtraResources adds each resource in extraResources to total, returning total which is allocated on first use
+func sumExtraResources(total, extraResources map[string]*output.ResourceTriple) map[string]*output.ResourceTriple {
+	for name, data := range extraResources {
+		if total == nil {
+			total = make(map[string]*output.ResourceTriple)
+		}
+		if _, ok := total[name]; !ok {
+			total[name] = &output.ResourceTriple{}
+		}
+		total[name].Capacity.Add(data.Capacity)
+		total[name].Allocatable.Add(data.Allocatable)
+		total[name].Requests.Add(data.Requests)
+	}
+	return total
+}
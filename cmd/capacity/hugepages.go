@@ -0,0 +1,89 @@
+/*
+Copyright © 2021 Alex Krzos akrzos@redhat.com
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package capacity
+
+import (
+	"strings"
+
+	"github.com/akrzos/kubeSize/internal/capacity"
+	"github.com/akrzos/kubeSize/internal/output"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// Selectors for the hugepages quantity that addHugepages accumulates into
+var (
+	hugepagesCapacity    = func(data *output.HugepagesCapacityData) *resource.Quantity { return &data.Capacity }
+	hugepagesAllocatable = func(data *output.HugepagesCapacityData) *resource.Quantity { return &data.Allocatable }
+	hugepagesRequests    = func(data *output.HugepagesCapacityData) *resource.Quantity { return &data.Requests }
+)
+
+// addHugepages adds every hugepages-<size> resource in resources to the quantity chosen by field, keyed by page size.
+// The map is allocated on first use and returned so nil maps can be passed in.
+func addHugepages(hugepages map[string]*output.HugepagesCapacityData, resources corev1.ResourceList, field func(*output.HugepagesCapacityData) *resource.Quantity) map[string]*output.HugepagesCapacityData {
+	for name, quantity := range resources {
+		if !strings.HasPrefix(string(name), corev1.ResourceHugePagesPrefix) {
+			continue
+		}
+		if hugepages == nil {
+			hugepages = make(map[string]*output.HugepagesCapacityData)
+		}
+		size := strings.TrimPrefix(string(name), corev1.ResourceHugePagesPrefix)
+		if _, ok := hugepages[size]; !ok {
+			hugepages[size] = &output.HugepagesCapacityData{}
+		}
+		field(hugepages[size]).Add(quantity)
+	}
+	return hugepages
+}
+
+// setHugepagesAvailable sets available = allocatable - requests for each page size, clamped like the other available
+// values unless --allow-negative is set
+func setHugepagesAvailable(cmd *cobra.Command, hugepages map[string]*output.HugepagesCapacityData) {
+	for _, data := range hugepages {
+		data.Available = data.Allocatable.DeepCopy()
+		data.Available.Sub(data.Requests)
+		clampAvailable(cmd, nil, &data.Available)
+	}
+}
+
+// setHugepagesReadable populates the "Human" readable values for each page size
+func setHugepagesReadable(hugepages map[string]*output.HugepagesCapacityData) {
+	for _, data := range hugepages {
+		data.CapacityGiB = capacity.ReadableMem(data.Capacity)
+		data.AllocatableGiB = capacity.ReadableMem(data.Allocatable)
+		data.RequestsGiB = capacity.ReadableMem(data.Requests)
+		data.AvailableGiB = capacity.ReadableMem(data.Available)
+	}
+}
+
+// sumHugepages adds each page size in hugepages to total, returning total which is allocated on first use
+func sumHugepages(total, hugepages map[string]*output.HugepagesCapacityData) map[string]*output.HugepagesCapacityData {
+	for size, data := range hugepages {
+		if total == nil {
+			total = make(map[string]*output.HugepagesCapacityData)
+		}
+		if _, ok := total[size]; !ok {
+			total[size] = &output.HugepagesCapacityData{}
+		}
+		total[size].Capacity.Add(data.Capacity)
+		total[size].Allocatable.Add(data.Allocatable)
+		total[size].Requests.Add(data.Requests)
+		total[size].Available.Add(data.Available)
+	}
+	return total
+}
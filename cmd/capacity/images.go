@@ -0,0 +1,129 @@
+/*
+Copyright © 2021 Alex Krzos akrzos@redhat.com
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package capacity
+
+import (
+	"context"
+	"io"
+	"sort"
+
+	"github.com/akrzos/kubeSize/internal/kube"
+	"github.com/akrzos/kubeSize/internal/output"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+var imagesCmd = &cobra.Command{
+	Use:     "images",
+	Aliases: []string{"img"},
+	Short:   "Get unique container image counts per node",
+	Long:    `Get the number of containers and unique container images of non-terminated pods per node, to estimate image pull pressure and image disk usage`,
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		return output.ValidateOutput(*cmd)
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runWatch(cmd, runImages)
+	},
+}
+
+// runImages collects and displays container and unique image counts per node
+func runImages(ctx context.Context, cmd *cobra.Command) error {
+	clientset, err := kube.CreateClientSet(KubernetesConfigFlags)
+	if err != nil {
+		return errors.Wrap(err, "failed to create clientset")
+	}
+
+	chunkSize, _ := cmd.Flags().GetInt64("chunk-size")
+
+	selector := "status.phase!=" + string(corev1.PodSucceeded) + ",status.phase!=" + string(corev1.PodFailed)
+	if nsFlag, _ := cmd.Flags().GetString("namespace"); nsFlag != "" {
+		selector += ",metadata.namespace=" + nsFlag
+	}
+	fieldSelector, err := fields.ParseSelector(selector)
+	if err != nil {
+		return errors.Wrap(err, "failed to create fieldSelector")
+	}
+
+	podListOptions, err := withFieldSelector(cmd, metav1.ListOptions{FieldSelector: fieldSelector.String()})
+	if err != nil {
+		return err
+	}
+
+	nonTermPods, err := kube.ListPods(ctx, clientset, "", podListOptions, chunkSize)
+	if err != nil {
+		return errors.Wrap(err, "failed to list non-term pods")
+	}
+
+	imageCapacityData, nodeNames := collectImageData(nonTermPods.Items)
+
+	sort.Strings(nodeNames)
+	reverseNames(cmd, nodeNames)
+
+	if displayTotal, _ := cmd.Flags().GetBool("display-total"); displayTotal {
+		nodeNames = append(nodeNames, "*total*")
+	}
+
+	return writeOutput(cmd, func(out io.Writer) {
+		output.DisplayImageData(out, imageCapacityData, nodeNames, getDisplayOptions(cmd))
+	})
+}
+
+// collectImageData counts the app and init containers and unique images of pods per node. Pods not yet assigned to a
+// node have pulled no images and are skipped. The *total* "node" counts the images unique across every node, so it is
+// usually less than the sum of the nodes.
+func collectImageData(pods []corev1.Pod) (map[string]*output.ImageCapacityData, []string) {
+	imageCapacityData := make(map[string]*output.ImageCapacityData)
+	nodeNames := make([]string, 0)
+	nodeImages := make(map[string]sets.String)
+	totalImages := sets.NewString()
+
+	imageCapacityData["*total*"] = new(output.ImageCapacityData)
+
+	for _, pod := range pods {
+		if pod.Spec.NodeName == "" {
+			continue
+		}
+		if _, ok := imageCapacityData[pod.Spec.NodeName]; !ok {
+			nodeNames = append(nodeNames, pod.Spec.NodeName)
+			imageCapacityData[pod.Spec.NodeName] = new(output.ImageCapacityData)
+			nodeImages[pod.Spec.NodeName] = sets.NewString()
+		}
+		for _, containers := range [][]corev1.Container{pod.Spec.Containers, pod.Spec.InitContainers} {
+			for _, container := range containers {
+				imageCapacityData[pod.Spec.NodeName].TotalContainerCount++
+				imageCapacityData["*total*"].TotalContainerCount++
+				nodeImages[pod.Spec.NodeName].Insert(container.Image)
+				totalImages.Insert(container.Image)
+			}
+		}
+	}
+
+	for _, node := range nodeNames {
+		imageCapacityData[node].UniqueImageCount = nodeImages[node].Len()
+	}
+	imageCapacityData["*total*"].UniqueImageCount = totalImages.Len()
+
+	return imageCapacityData, nodeNames
+}
+
+func init() {
+	rootCmd.AddCommand(imagesCmd)
+	imagesCmd.Flags().BoolP("display-total", "t", false, "Display the container count and unique images across all nodes in table output")
+}
@@ -0,0 +1,56 @@
+/*
+Copyright © 2021 Alex Krzos akrzos@redhat.com
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package capacity
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestCollectImageData(t *testing.T) {
+	pods := []corev1.Pod{
+		testPod("app-0", "worker-0", nil),
+		testPod("app-1", "worker-0", nil),
+		testPod("app-2", "worker-1", nil),
+		testPod("pending-0", "", nil),
+	}
+	pods[1].Spec.InitContainers = []corev1.Container{{Name: "init", Image: "busybox"}}
+	pods[2].Spec.Containers[0].Image = "quay.io/prometheus/prometheus"
+
+	imageCapacityData, nodeNames := collectImageData(pods)
+	if len(nodeNames) != 2 {
+		t.Fatalf("nodeNames = %v, want [worker-0 worker-1]", nodeNames)
+	}
+	tests := []struct {
+		node       string
+		containers int
+		images     int
+	}{
+		{"worker-0", 3, 2},
+		{"worker-1", 1, 1},
+		{"*total*", 4, 3},
+	}
+	for _, tt := range tests {
+		data := imageCapacityData[tt.node]
+		if data.TotalContainerCount != tt.containers {
+			t.Errorf("%s TotalContainerCount = %d, want %d", tt.node, data.TotalContainerCount, tt.containers)
+		}
+		if data.UniqueImageCount != tt.images {
+			t.Errorf("%s UniqueImageCount = %d, want %d", tt.node, data.UniqueImageCount, tt.images)
+		}
+	}
+}
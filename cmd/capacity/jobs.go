@@ -0,0 +1,125 @@
+/*
+Copyright © 2021 Alex Krzos akrzos@redhat.com
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package capacity
+
+import (
+	"context"
+	"io"
+	"sort"
+
+	"github.com/akrzos/kubeSize/internal/kube"
+	"github.com/akrzos/kubeSize/internal/output"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var jobsCmd = &cobra.Command{
+	Use:     "jobs",
+	Aliases: []string{"job"},
+	Short:   "Get CronJob and Job status",
+	Long:    `Get the schedule, suspended status, active jobs and last schedule time of CronJobs and the completions and failures of Jobs not created by a CronJob`,
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		return output.ValidateOutput(*cmd)
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runWatch(cmd, runJobs)
+	},
+}
+
+// runJobs collects and displays the CronJobs and standalone Jobs
+func runJobs(ctx context.Context, cmd *cobra.Command) error {
+	// Like kubectl get jobs, the kubeconfig context namespace is used unless --namespace or --all-namespaces is set
+	namespace := ""
+	if allNamespaces, _ := cmd.Flags().GetBool("all-namespaces"); !allNamespaces {
+		contextNamespace, _, err := KubernetesConfigFlags.ToRawKubeConfigLoader().Namespace()
+		if err != nil {
+			return errors.Wrap(err, "failed to read namespace")
+		}
+		namespace = contextNamespace
+	}
+
+	clientset, err := kube.CreateClientSet(KubernetesConfigFlags)
+	if err != nil {
+		return errors.Wrap(err, "failed to create clientset")
+	}
+
+	cronJobs, err := clientset.BatchV1beta1().CronJobs(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return errors.Wrap(err, "failed to list cronjobs")
+	}
+
+	jobs, err := clientset.BatchV1().Jobs(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return errors.Wrap(err, "failed to list jobs")
+	}
+
+	jobsData := output.JobsData{
+		CronJobs: make(map[string]*output.CronJobData),
+		Jobs:     make(map[string]*output.JobData),
+	}
+	cronJobNames := make([]string, 0, len(cronJobs.Items))
+	jobNames := make([]string, 0, len(jobs.Items))
+
+	for _, cronJob := range cronJobs.Items {
+		cronJobName := cronJob.Namespace + "/" + cronJob.Name
+		cronJobNames = append(cronJobNames, cronJobName)
+		jobsData.CronJobs[cronJobName] = &output.CronJobData{
+			Name:           cronJob.Name,
+			Namespace:      cronJob.Namespace,
+			Schedule:       cronJob.Spec.Schedule,
+			Suspended:      cronJob.Spec.Suspend != nil && *cronJob.Spec.Suspend,
+			ActiveJobCount: len(cronJob.Status.Active),
+		}
+		if cronJob.Status.LastScheduleTime != nil {
+			lastScheduleTime := cronJob.Status.LastScheduleTime.Time
+			jobsData.CronJobs[cronJobName].LastScheduleTime = &lastScheduleTime
+		}
+	}
+
+	for _, job := range jobs.Items {
+		// Jobs created by a CronJob are summarized by the CronJob
+		if owner := metav1.GetControllerOf(&job); owner != nil && owner.Kind == "CronJob" {
+			continue
+		}
+		jobName := job.Namespace + "/" + job.Name
+		jobNames = append(jobNames, jobName)
+		jobsData.Jobs[jobName] = &output.JobData{
+			Name:      job.Name,
+			Namespace: job.Namespace,
+			Active:    int(job.Status.Active),
+			Succeeded: int(job.Status.Succeeded),
+			Failed:    int(job.Status.Failed),
+		}
+		if job.Spec.Completions != nil {
+			jobsData.Jobs[jobName].Completions = int(*job.Spec.Completions)
+		}
+	}
+
+	sort.Strings(cronJobNames)
+	sort.Strings(jobNames)
+	reverseNames(cmd, cronJobNames)
+	reverseNames(cmd, jobNames)
+
+	return writeOutput(cmd, func(out io.Writer) {
+		output.DisplayJobsData(out, jobsData, cronJobNames, jobNames, getDisplayOptions(cmd))
+	})
+}
+
+func init() {
+	rootCmd.AddCommand(jobsCmd)
+	jobsCmd.Flags().BoolP("all-namespaces", "A", false, "List cronjobs and jobs across all namespaces")
+}
@@ -17,9 +17,9 @@ package capacity
 
 import (
 	"context"
-	"fmt"
-	"os"
+	"io"
 	"sort"
+	"strings"
 
 	"github.com/akrzos/kubeSize/internal/capacity"
 	"github.com/akrzos/kubeSize/internal/kube"
@@ -27,139 +27,335 @@ import (
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
 )
 
+// namespaceSortKeys are the --sort-by keys other than name, each orders namespaces by its value with the largest first
+var namespaceSortKeys = map[string]func(*output.NamespaceCapacityData) int64{
+	"pods":            func(data *output.NamespaceCapacityData) int64 { return int64(data.TotalPodCount) },
+	"cpu-requests":    func(data *output.NamespaceCapacityData) int64 { return data.TotalRequestsCPU.MilliValue() },
+	"memory-requests": func(data *output.NamespaceCapacityData) int64 { return data.TotalRequestsMemory.Value() },
+	"cpu-limits":      func(data *output.NamespaceCapacityData) int64 { return data.TotalLimitsCPU.MilliValue() },
+	"memory-limits":   func(data *output.NamespaceCapacityData) int64 { return data.TotalLimitsMemory.Value() },
+}
+
 var namespaceCmd = &cobra.Command{
 	Use:     "namespace",
 	Aliases: []string{"ns"},
 	Short:   "Get namespace size",
 	Long:    `Get metrics related to the size of a namespace`,
-	PreRun: func(cmd *cobra.Command, args []string) {
+	PreRunE: func(cmd *cobra.Command, args []string) error {
 		if err := output.ValidateOutput(*cmd); err != nil {
-			fmt.Fprintf(os.Stderr, "error: %v\n", err)
-			os.Exit(1)
+			return err
+		}
+		if sortBy, _ := cmd.Flags().GetString("sort-by"); sortBy != "name" {
+			if _, ok := namespaceSortKeys[sortBy]; !ok {
+				return errors.Errorf("invalid --sort-by %q, must be one of name, pods, cpu-requests, memory-requests, cpu-limits or memory-limits", sortBy)
+			}
+		}
+		namespaceSelector, _ := cmd.Flags().GetString("namespace-selector")
+		if _, err := labels.Parse(namespaceSelector); err != nil {
+			return errors.Wrap(err, "invalid --namespace-selector")
 		}
+		return nil
 	},
 	RunE: func(cmd *cobra.Command, args []string) error {
+		return runWatch(cmd, runNamespace)
+	},
+}
+
+// runNamespace collects and displays namespace capacity data
+func runNamespace(ctx context.Context, cmd *cobra.Command) error {
+	clientset, err := kube.CreateClientSet(KubernetesConfigFlags)
+	if err != nil {
+		return errors.Wrap(err, "failed to create clientset")
+	}
+
+	chunkSize, _ := cmd.Flags().GetInt64("chunk-size")
 
-		clientset, err := kube.CreateClientSet(KubernetesConfigFlags)
+	nsFlag, _ := cmd.Flags().GetString("namespace")
+	nsListOptions := metav1.ListOptions{}
+	podListOptions := metav1.ListOptions{}
+
+	if nsFlag != "" {
+		nsFieldSelector, err := fields.ParseSelector("metadata.name=" + nsFlag)
+		if err != nil {
+			return errors.Wrap(err, "failed to create fieldSelector")
+		}
+		podNamespaceFieldSelector, err := fields.ParseSelector("metadata.namespace=" + nsFlag)
 		if err != nil {
-			return errors.Wrap(err, "failed to create clientset")
+			return errors.Wrap(err, "failed to create fieldSelector")
 		}
+		nsListOptions = metav1.ListOptions{FieldSelector: nsFieldSelector.String()}
+		podListOptions = metav1.ListOptions{FieldSelector: podNamespaceFieldSelector.String()}
+	}
+	namespaceSelector, _ := cmd.Flags().GetString("namespace-selector")
+	nsListOptions.LabelSelector = namespaceSelector
+
+	namespaces, err := clientset.CoreV1().Namespaces().List(ctx, nsListOptions)
+	if err != nil {
+		return errors.Wrap(err, "failed to list namespaces")
+	}
+
+	podListOptions, err = withFieldSelector(cmd, podListOptions)
+	if err != nil {
+		return err
+	}
 
-		nsFlag, _ := cmd.Flags().GetString("namespace")
-		nsListOptions := metav1.ListOptions{}
-		podListOptions := metav1.ListOptions{}
+	pods, err := kube.ListPods(ctx, clientset, "", podListOptions, chunkSize)
+	if err != nil {
+		return errors.Wrap(err, "failed to list pods")
+	}
+	pods.Items = recentPods(cmd, pods.Items)
 
-		if nsFlag != "" {
-			nsFieldSelector, err := fields.ParseSelector("metadata.name=" + nsFlag)
-			if err != nil {
-				return errors.Wrap(err, "failed to create fieldSelector")
+	namespaceCapacityData := make(map[string]*output.NamespaceCapacityData)
+	namespaceNames := make([]string, 0, len(namespaces.Items))
+
+	for _, namespace := range namespaces.Items {
+		namespaceNames = append(namespaceNames, namespace.Name)
+		namespaceCapacityData[namespace.Name] = new(output.NamespaceCapacityData)
+	}
+	selectedNamespaces := sets.NewString(namespaceNames...)
+	namespaceNodes := make(map[string]sets.String)
+
+	for _, pod := range pods.Items {
+		// Only pods of the namespaces matching --namespace-selector are aggregated
+		if namespaceSelector != "" && !selectedNamespaces.Has(pod.Namespace) {
+			continue
+		}
+		if !capacity.StringInSlice(pod.Namespace, namespaceNames) {
+			namespaceNames = append(namespaceNames, pod.Namespace)
+			namespaceCapacityData[pod.Namespace] = new(output.NamespaceCapacityData)
+		}
+		if pod.Spec.NodeName == "" {
+			namespaceCapacityData[pod.Namespace].TotalUnassignedNodePodCount++
+		} else {
+			if _, ok := namespaceNodes[pod.Namespace]; !ok {
+				namespaceNodes[pod.Namespace] = sets.NewString()
 			}
-			podNamespaceFieldSelector, err := fields.ParseSelector("metadata.namespace=" + nsFlag)
-			if err != nil {
-				return errors.Wrap(err, "failed to create fieldSelector")
+			namespaceNodes[pod.Namespace].Insert(pod.Spec.NodeName)
+		}
+		namespaceCapacityData[pod.Namespace].TotalPodCount++
+		countPodPhase(pod.Status.Phase, &namespaceCapacityData[pod.Namespace].RunningPodCount, &namespaceCapacityData[pod.Namespace].PendingPodCount, &namespaceCapacityData[pod.Namespace].SucceededPodCount, &namespaceCapacityData[pod.Namespace].FailedPodCount)
+		if (pod.Status.Phase != corev1.PodSucceeded) && (pod.Status.Phase != corev1.PodFailed) {
+			namespaceCapacityData[pod.Namespace].TotalNonTermPodCount++
+			namespaceCapacityData[pod.Namespace].TotalContainerCount += len(pod.Spec.Containers)
+			namespaceCapacityData[pod.Namespace].TotalInitContainerCount += len(pod.Spec.InitContainers)
+			if capacity.IsPodReady(pod) {
+				namespaceCapacityData[pod.Namespace].ReadyPodCount++
 			}
-			nsListOptions = metav1.ListOptions{FieldSelector: nsFieldSelector.String()}
-			podListOptions = metav1.ListOptions{FieldSelector: podNamespaceFieldSelector.String()}
+			podRequests, podLimits := capacity.PodRequestsAndLimits(pod)
+			namespaceCapacityData[pod.Namespace].TotalRequestsCPU.Add(*podRequests.Cpu())
+			namespaceCapacityData[pod.Namespace].TotalLimitsCPU.Add(*podLimits.Cpu())
+			namespaceCapacityData[pod.Namespace].TotalRequestsMemory.Add(*podRequests.Memory())
+			namespaceCapacityData[pod.Namespace].TotalLimitsMemory.Add(*podLimits.Memory())
+			namespaceCapacityData[pod.Namespace].TotalRequestsEphemeralStorage.Add(*podRequests.StorageEphemeral())
+			namespaceCapacityData[pod.Namespace].TotalLimitsEphemeralStorage.Add(*podLimits.StorageEphemeral())
 		}
+	}
 
-		namespaces, err := clientset.CoreV1().Namespaces().List(context.TODO(), nsListOptions)
-		if err != nil {
-			return errors.Wrap(err, "failed to list namespaces")
-		}
+	clusterPercent, _ := cmd.Flags().GetBool("cluster-percent")
+	var clusterAllocatableCPU, clusterAllocatableMemory resource.Quantity
 
-		pods, err := clientset.CoreV1().Pods("").List(context.TODO(), podListOptions)
+	if clusterPercent {
+		nodes, err := kube.ListNodes(ctx, clientset, metav1.ListOptions{}, chunkSize)
 		if err != nil {
-			return errors.Wrap(err, "failed to list pods")
+			return errors.Wrap(err, "failed to list nodes")
 		}
+		clusterAllocatableCPU, clusterAllocatableMemory = clusterAllocatable(nodes.Items)
+	}
 
-		namespaceCapacityData := make(map[string]*output.NamespaceCapacityData)
-		namespaceNames := make([]string, 0, len(namespaces.Items))
+	displayQuota, _ := cmd.Flags().GetBool("quota")
 
-		for _, namespace := range namespaces.Items {
-			namespaceNames = append(namespaceNames, namespace.Name)
-			namespaceCapacityData[namespace.Name] = new(output.NamespaceCapacityData)
+	if displayQuota {
+		resourceQuotas, err := clientset.CoreV1().ResourceQuotas(nsFlag).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return errors.Wrap(err, "failed to list resourcequotas")
 		}
-
-		for _, pod := range pods.Items {
-			if !capacity.StringInSlice(pod.Namespace, namespaceNames) {
-				namespaceNames = append(namespaceNames, pod.Namespace)
-				namespaceCapacityData[pod.Namespace] = new(output.NamespaceCapacityData)
+		// With multiple quotas in a namespace the quota with the lowest hard pod count is the effective ceiling
+		for _, resourceQuota := range resourceQuotas.Items {
+			hard, ok := resourceQuota.Status.Hard[corev1.ResourcePods]
+			if !ok {
+				continue
 			}
-			if pod.Spec.NodeName == "" {
-				namespaceCapacityData[pod.Namespace].TotalUnassignedNodePodCount++
+			if _, ok := namespaceCapacityData[resourceQuota.Namespace]; !ok {
+				continue
 			}
-			namespaceCapacityData[pod.Namespace].TotalPodCount++
-			if (pod.Status.Phase != corev1.PodSucceeded) && (pod.Status.Phase != corev1.PodFailed) {
-				namespaceCapacityData[pod.Namespace].TotalNonTermPodCount++
-				for _, container := range pod.Spec.Containers {
-					namespaceCapacityData[pod.Namespace].TotalRequestsCPU.Add(*container.Resources.Requests.Cpu())
-					namespaceCapacityData[pod.Namespace].TotalLimitsCPU.Add(*container.Resources.Limits.Cpu())
-					namespaceCapacityData[pod.Namespace].TotalRequestsMemory.Add(*container.Resources.Requests.Memory())
-					namespaceCapacityData[pod.Namespace].TotalLimitsMemory.Add(*container.Resources.Limits.Memory())
-					namespaceCapacityData[pod.Namespace].TotalRequestsEphemeralStorage.Add(*container.Resources.Requests.StorageEphemeral())
-					namespaceCapacityData[pod.Namespace].TotalLimitsEphemeralStorage.Add(*container.Resources.Limits.StorageEphemeral())
-				}
+			nsData := namespaceCapacityData[resourceQuota.Namespace]
+			if !nsData.HasPodQuota || int(hard.Value()) < nsData.PodQuotaHard {
+				used := resourceQuota.Status.Used[corev1.ResourcePods]
+				nsData.HasPodQuota = true
+				nsData.PodQuotaHard = int(hard.Value())
+				nsData.PodQuotaUsed = int(used.Value())
 			}
 		}
+	}
 
-		namespaceCapacityData["*total*"] = new(output.NamespaceCapacityData)
+	hideSystem, _ := cmd.Flags().GetBool("hide-system")
+	excludeSystemFromTotal, _ := cmd.Flags().GetBool("exclude-system-from-total")
+	systemPrefixes, _ := cmd.Flags().GetStringSlice("system-prefixes")
 
-		// Populate "Human" readable capacity data values and the *total* "namespace"
-		for _, namespace := range namespaceNames {
-			namespaceCapacityData[namespace].TotalRequestsCPUCores = capacity.ReadableCPU(namespaceCapacityData[namespace].TotalRequestsCPU)
-			namespaceCapacityData[namespace].TotalLimitsCPUCores = capacity.ReadableCPU(namespaceCapacityData[namespace].TotalLimitsCPU)
-			namespaceCapacityData[namespace].TotalRequestsMemoryGiB = capacity.ReadableMem(namespaceCapacityData[namespace].TotalRequestsMemory)
-			namespaceCapacityData[namespace].TotalLimitsMemoryGiB = capacity.ReadableMem(namespaceCapacityData[namespace].TotalLimitsMemory)
-			namespaceCapacityData[namespace].TotalRequestsEphemeralStorageGB = capacity.ReadableStorage(namespaceCapacityData[namespace].TotalRequestsEphemeralStorage)
-			namespaceCapacityData[namespace].TotalLimitsEphemeralStorageGB = capacity.ReadableStorage(namespaceCapacityData[namespace].TotalLimitsEphemeralStorage)
-			namespaceCapacityData["*total*"].TotalPodCount += namespaceCapacityData[namespace].TotalPodCount
-			namespaceCapacityData["*total*"].TotalNonTermPodCount += namespaceCapacityData[namespace].TotalNonTermPodCount
-			namespaceCapacityData["*total*"].TotalUnassignedNodePodCount += namespaceCapacityData[namespace].TotalUnassignedNodePodCount
-			namespaceCapacityData["*total*"].TotalRequestsCPU.Add(namespaceCapacityData[namespace].TotalRequestsCPU)
-			namespaceCapacityData["*total*"].TotalRequestsCPUCores += namespaceCapacityData[namespace].TotalRequestsCPUCores
-			namespaceCapacityData["*total*"].TotalLimitsCPU.Add(namespaceCapacityData[namespace].TotalLimitsCPU)
-			namespaceCapacityData["*total*"].TotalLimitsCPUCores += namespaceCapacityData[namespace].TotalLimitsCPUCores
-			namespaceCapacityData["*total*"].TotalRequestsMemory.Add(namespaceCapacityData[namespace].TotalRequestsMemory)
-			namespaceCapacityData["*total*"].TotalRequestsMemoryGiB += namespaceCapacityData[namespace].TotalRequestsMemoryGiB
-			namespaceCapacityData["*total*"].TotalLimitsMemory.Add(namespaceCapacityData[namespace].TotalLimitsMemory)
-			namespaceCapacityData["*total*"].TotalLimitsMemoryGiB += namespaceCapacityData[namespace].TotalLimitsMemoryGiB
-			namespaceCapacityData["*total*"].TotalRequestsEphemeralStorage.Add(namespaceCapacityData[namespace].TotalRequestsEphemeralStorage)
-			namespaceCapacityData["*total*"].TotalRequestsEphemeralStorageGB += namespaceCapacityData[namespace].TotalRequestsEphemeralStorageGB
-			namespaceCapacityData["*total*"].TotalLimitsEphemeralStorage.Add(namespaceCapacityData[namespace].TotalLimitsEphemeralStorage)
-			namespaceCapacityData["*total*"].TotalLimitsEphemeralStorageGB += namespaceCapacityData[namespace].TotalLimitsEphemeralStorageGB
+	// Hidden system namespaces still count towards the *total* "namespace" unless also excluded from it
+	if hideSystem && excludeSystemFromTotal {
+		namespaceNames = removeSystemNamespaces(namespaceNames, systemPrefixes, namespaceCapacityData)
+	}
+
+	namespaceCapacityData["*total*"] = new(output.NamespaceCapacityData)
+	totalNodes := sets.NewString()
+
+	// Populate "Human" readable capacity data values and the *total* "namespace"
+	for _, namespace := range namespaceNames {
+		namespaceCapacityData[namespace].TotalRequestsCPUCores = capacity.ReadableCPU(namespaceCapacityData[namespace].TotalRequestsCPU)
+		namespaceCapacityData[namespace].TotalLimitsCPUCores = capacity.ReadableCPU(namespaceCapacityData[namespace].TotalLimitsCPU)
+		namespaceCapacityData[namespace].TotalRequestsMemoryGiB = capacity.ReadableMem(namespaceCapacityData[namespace].TotalRequestsMemory)
+		namespaceCapacityData[namespace].TotalLimitsMemoryGiB = capacity.ReadableMem(namespaceCapacityData[namespace].TotalLimitsMemory)
+		namespaceCapacityData[namespace].TotalRequestsEphemeralStorageGB = capacity.ReadableStorage(namespaceCapacityData[namespace].TotalRequestsEphemeralStorage)
+		namespaceCapacityData[namespace].TotalLimitsEphemeralStorageGB = capacity.ReadableStorage(namespaceCapacityData[namespace].TotalLimitsEphemeralStorage)
+		namespaceCapacityData["*total*"].TotalPodCount += namespaceCapacityData[namespace].TotalPodCount
+		namespaceCapacityData["*total*"].TotalNonTermPodCount += namespaceCapacityData[namespace].TotalNonTermPodCount
+		namespaceCapacityData["*total*"].TotalUnassignedNodePodCount += namespaceCapacityData[namespace].TotalUnassignedNodePodCount
+		namespaceCapacityData["*total*"].TotalContainerCount += namespaceCapacityData[namespace].TotalContainerCount
+		namespaceCapacityData["*total*"].TotalInitContainerCount += namespaceCapacityData[namespace].TotalInitContainerCount
+		namespaceCapacityData["*total*"].RunningPodCount += namespaceCapacityData[namespace].RunningPodCount
+		namespaceCapacityData["*total*"].PendingPodCount += namespaceCapacityData[namespace].PendingPodCount
+		namespaceCapacityData["*total*"].SucceededPodCount += namespaceCapacityData[namespace].SucceededPodCount
+		namespaceCapacityData["*total*"].FailedPodCount += namespaceCapacityData[namespace].FailedPodCount
+		namespaceCapacityData["*total*"].ReadyPodCount += namespaceCapacityData[namespace].ReadyPodCount
+		namespaceCapacityData["*total*"].TotalRequestsCPU.Add(namespaceCapacityData[namespace].TotalRequestsCPU)
+		namespaceCapacityData["*total*"].TotalRequestsCPUCores += namespaceCapacityData[namespace].TotalRequestsCPUCores
+		namespaceCapacityData["*total*"].TotalLimitsCPU.Add(namespaceCapacityData[namespace].TotalLimitsCPU)
+		namespaceCapacityData["*total*"].TotalLimitsCPUCores += namespaceCapacityData[namespace].TotalLimitsCPUCores
+		namespaceCapacityData["*total*"].TotalRequestsMemory.Add(namespaceCapacityData[namespace].TotalRequestsMemory)
+		namespaceCapacityData["*total*"].TotalRequestsMemoryGiB += namespaceCapacityData[namespace].TotalRequestsMemoryGiB
+		namespaceCapacityData["*total*"].TotalLimitsMemory.Add(namespaceCapacityData[namespace].TotalLimitsMemory)
+		namespaceCapacityData["*total*"].TotalLimitsMemoryGiB += namespaceCapacityData[namespace].TotalLimitsMemoryGiB
+		namespaceCapacityData["*total*"].TotalRequestsEphemeralStorage.Add(namespaceCapacityData[namespace].TotalRequestsEphemeralStorage)
+		namespaceCapacityData["*total*"].TotalRequestsEphemeralStorageGB += namespaceCapacityData[namespace].TotalRequestsEphemeralStorageGB
+		namespaceCapacityData["*total*"].TotalLimitsEphemeralStorage.Add(namespaceCapacityData[namespace].TotalLimitsEphemeralStorage)
+		namespaceCapacityData["*total*"].TotalLimitsEphemeralStorageGB += namespaceCapacityData[namespace].TotalLimitsEphemeralStorageGB
+		// Namespaces share nodes, the *total* "namespace" counts the distinct nodes of every namespace once
+		namespaceCapacityData[namespace].DistinctNodeCount = namespaceNodes[namespace].Len()
+		totalNodes = totalNodes.Union(namespaceNodes[namespace])
+	}
+	namespaceCapacityData["*total*"].DistinctNodeCount = totalNodes.Len()
+
+	// Averages are per non-terminated pod since only those pods contribute requests
+	for _, namespace := range append([]string{"*total*"}, namespaceNames...) {
+		if namespaceCapacityData[namespace].TotalNonTermPodCount > 0 {
+			namespaceCapacityData[namespace].AvgContainersPerPod = float64(namespaceCapacityData[namespace].TotalContainerCount) / float64(namespaceCapacityData[namespace].TotalNonTermPodCount)
+			namespaceCapacityData[namespace].AvgCPURequestPerPod = namespaceCapacityData[namespace].TotalRequestsCPUCores / float64(namespaceCapacityData[namespace].TotalNonTermPodCount)
+			namespaceCapacityData[namespace].AvgMemoryRequestPerPod = namespaceCapacityData[namespace].TotalRequestsMemoryGiB / float64(namespaceCapacityData[namespace].TotalNonTermPodCount)
+		}
+		namespaceCapacityData[namespace].CPULimitRequestRatio = capacity.Ratio(namespaceCapacityData[namespace].TotalLimitsCPU, namespaceCapacityData[namespace].TotalRequestsCPU)
+		namespaceCapacityData[namespace].MemoryLimitRequestRatio = capacity.Ratio(namespaceCapacityData[namespace].TotalLimitsMemory, namespaceCapacityData[namespace].TotalRequestsMemory)
+		if clusterPercent {
+			setClusterPercent(namespaceCapacityData[namespace], clusterAllocatableCPU, clusterAllocatableMemory)
 		}
+	}
 
-		sort.Strings(namespaceNames)
+	if hideSystem {
+		namespaceNames = removeSystemNamespaces(namespaceNames, systemPrefixes, namespaceCapacityData)
+	}
 
-		displayDefault, _ := cmd.Flags().GetBool("default-format")
+	sort.Strings(namespaceNames)
+	if sortBy, _ := cmd.Flags().GetString("sort-by"); sortBy != "name" {
+		// Largest first, namespaces with equal values stay alphabetical
+		value := namespaceSortKeys[sortBy]
+		sort.SliceStable(namespaceNames, func(i, j int) bool {
+			return value(namespaceCapacityData[namespaceNames[i]]) > value(namespaceCapacityData[namespaceNames[j]])
+		})
+	}
+	reverseNames(cmd, namespaceNames)
 
-		displayEphemeralStorage, _ := cmd.Flags().GetBool("ephemeral-storage")
+	displayOptions := getDisplayOptions(cmd)
+	displayOptions.AllNamespaces, _ = cmd.Flags().GetBool("all-namespaces")
+	displayOptions.Stats, _ = cmd.Flags().GetBool("stats")
+	displayOptions.PodReadiness, _ = cmd.Flags().GetBool("pod-readiness")
+	displayOptions.Ratios, _ = cmd.Flags().GetBool("ratios")
+	displayOptions.MinPods, _ = cmd.Flags().GetInt("min-pods")
+	displayOptions.Quota = displayQuota
+	displayOptions.ClusterPercent = clusterPercent
+	displayOptions.NodeSpread, _ = cmd.Flags().GetBool("node-spread")
 
-		displayNoHeaders, _ := cmd.Flags().GetBool("no-headers")
+	// --top only limits the rows displayed, the *total* "namespace" above still sums every namespace
+	namespaceNames = topNames(cmd, namespaceNames)
 
-		displayFormat, _ := cmd.Flags().GetString("output")
+	displayTotal, _ := cmd.Flags().GetBool("display-total")
+	quiet, _ := cmd.Flags().GetBool("quiet")
 
-		displayAllNamespaces, _ := cmd.Flags().GetBool("all-namespaces")
+	// --quiet displays only the *total* row, json and yaml output only the *total* object
+	if quiet {
+		namespaceNames = []string{"*total*"}
+		namespaceCapacityData = map[string]*output.NamespaceCapacityData{"*total*": namespaceCapacityData["*total*"]}
+	} else if displayTotal {
+		namespaceNames = append(namespaceNames, "*total*")
+	}
 
-		displayTotal, _ := cmd.Flags().GetBool("display-total")
+	return writeOutput(cmd, func(out io.Writer) {
+		output.DisplayNamespaceData(out, namespaceCapacityData, namespaceNames, displayOptions)
+	})
+}
 
-		if displayTotal {
-			namespaceNames = append(namespaceNames, "*total*")
-		}
+// clusterAllocatable sums the allocatable cpu and memory of the nodes
+func clusterAllocatable(nodes []corev1.Node) (resource.Quantity, resource.Quantity) {
+	var allocatableCPU, allocatableMemory resource.Quantity
+	for _, node := range nodes {
+		allocatableCPU.Add(*node.Status.Allocatable.Cpu())
+		allocatableMemory.Add(*node.Status.Allocatable.Memory())
+	}
+	return allocatableCPU, allocatableMemory
+}
 
-		output.DisplayNamespaceData(namespaceCapacityData, namespaceNames, displayDefault, !displayNoHeaders, displayEphemeralStorage, displayFormat, displayAllNamespaces)
+// setClusterPercent sets the namespace requests as a percentage of cluster allocatable, the *total* "namespace"
+// sums the requests of every namespace so its percentages are the overall request utilization
+func setClusterPercent(nsData *output.NamespaceCapacityData, allocatableCPU, allocatableMemory resource.Quantity) {
+	nsData.RequestsCPUClusterPct = capacity.Utilization(nsData.TotalRequestsCPU, allocatableCPU)
+	nsData.RequestsMemoryClusterPct = capacity.Utilization(nsData.TotalRequestsMemory, allocatableMemory)
+}
 
-		return nil
-	},
+// removeSystemNamespaces removes namespaces with any of the system prefixes from the names and capacity data
+func removeSystemNamespaces(namespaceNames []string, systemPrefixes []string, namespaceCapacityData map[string]*output.NamespaceCapacityData) []string {
+	filtered := make([]string, 0, len(namespaceNames))
+	for _, namespace := range namespaceNames {
+		isSystem := false
+		for _, prefix := range systemPrefixes {
+			if strings.HasPrefix(namespace, prefix) {
+				isSystem = true
+				break
+			}
+		}
+		if isSystem {
+			delete(namespaceCapacityData, namespace)
+			continue
+		}
+		filtered = append(filtered, namespace)
+	}
+	return filtered
 }
 
 func init() {
 	rootCmd.AddCommand(namespaceCmd)
 	namespaceCmd.Flags().BoolP("all-namespaces", "A", false, "Include 0 pod namespaces in table output")
+	namespaceCmd.Flags().StringP("namespace-selector", "", "", "Only include namespaces matching this label selector and aggregate only their pods (Ex team=payments)")
+	namespaceCmd.Flags().DurationP("since", "", 0, "Only count pods created within this duration (Ex 1h). 0 counts all pods")
+	namespaceCmd.Flags().BoolP("ratios", "", false, "Include cpu and memory limits to requests ratios in table output")
+	namespaceCmd.Flags().StringP("sort-by", "", "name", "Sort namespaces by name, pods, cpu-requests, memory-requests, cpu-limits or memory-limits, all but name sort largest first")
+	namespaceCmd.Flags().IntP("top", "", 0, "Only include the first N namespaces after sorting in the output, 0 includes all")
+	namespaceCmd.Flags().IntP("min-pods", "", 0, "Only include namespaces with at least this many pods in table output")
 	namespaceCmd.Flags().BoolP("ephemeral-storage", "e", false, "Include ephemeral storage capacity data in table output")
 	namespaceCmd.Flags().BoolP("display-total", "t", false, "Display sum of all namespace capacity data in table output")
+	namespaceCmd.Flags().BoolP("quiet", "q", false, "Only display the *total* row, implies --display-total. Json and yaml output only the *total* object")
+	namespaceCmd.Flags().BoolP("pod-readiness", "", false, "Include the count of Ready non-terminated pods in table output")
+	namespaceCmd.Flags().BoolP("phases", "", false, "Include Running, Pending, Succeeded and Failed pod counts in table output")
+	namespaceCmd.Flags().BoolP("stats", "", false, "Include container count and average requests per non-terminated pod in table output")
+	namespaceCmd.Flags().BoolP("cluster-percent", "", false, "Include cpu and memory requests as a percentage of cluster allocatable in table output")
+	namespaceCmd.Flags().BoolP("node-spread", "", false, "Include the count of distinct nodes the pods of each namespace are scheduled on in table output")
+	namespaceCmd.Flags().BoolP("quota", "", false, "Include pod count ResourceQuota hard and used values")
+	namespaceCmd.Flags().BoolP("hide-system", "", false, "Hide namespaces matching --system-prefixes, their pods are still included in the total")
+	namespaceCmd.Flags().BoolP("exclude-system-from-total", "", false, "Exclude the pods of namespaces hidden by --hide-system from the total")
+	namespaceCmd.Flags().StringSliceP("system-prefixes", "", []string{"kube-", "openshift-"}, "Namespace name prefixes treated as system namespaces by --hide-system")
 }
@@ -0,0 +1,87 @@
+/*
+Copyright © 2021 Alex Krzos akrzos@redhat.com
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package capacity
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/akrzos/kubeSize/internal/output"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func testNamespaceData() (map[string]*output.NamespaceCapacityData, []string) {
+	namespaceCapacityData := map[string]*output.NamespaceCapacityData{
+		"default":     {TotalPodCount: 3, TotalNonTermPodCount: 3},
+		"kube-system": {TotalPodCount: 8, TotalNonTermPodCount: 8},
+	}
+	return namespaceCapacityData, []string{"default", "kube-system"}
+}
+
+func TestHideSystemNamespaces(t *testing.T) {
+	namespaceCapacityData, namespaceNames := testNamespaceData()
+	namespaceNames = removeSystemNamespaces(namespaceNames, []string{"kube-", "openshift-"}, namespaceCapacityData)
+
+	var out bytes.Buffer
+	output.DisplayNamespaceData(&out, namespaceCapacityData, namespaceNames, output.DisplayOptions{Format: "table", Headers: true})
+	if strings.Contains(out.String(), "kube-system") {
+		t.Errorf("--hide-system table output contains kube-system:\n%s", out.String())
+	}
+	if !strings.Contains(out.String(), "default") {
+		t.Errorf("--hide-system table output is missing default:\n%s", out.String())
+	}
+
+	namespaceCapacityData, namespaceNames = testNamespaceData()
+	out.Reset()
+	output.DisplayNamespaceData(&out, namespaceCapacityData, namespaceNames, output.DisplayOptions{Format: "json"})
+	if !strings.Contains(out.String(), `"kube-system"`) {
+		t.Errorf("json output without --hide-system is missing kube-system:\n%s", out.String())
+	}
+}
+
+func TestSetClusterPercent(t *testing.T) {
+	nodes := []corev1.Node{testNode("worker-0", "worker", "4", "16Gi", "100Gi"), testNode("worker-1", "worker", "4", "16Gi", "100Gi")}
+	allocatableCPU, allocatableMemory := clusterAllocatable(nodes)
+
+	namespaceCapacityData := map[string]*output.NamespaceCapacityData{
+		"default":     {TotalRequestsCPU: resource.MustParse("2"), TotalRequestsMemory: resource.MustParse("8Gi")},
+		"kube-system": {TotalRequestsCPU: resource.MustParse("1"), TotalRequestsMemory: resource.MustParse("4Gi")},
+		"*total*":     {TotalRequestsCPU: resource.MustParse("3"), TotalRequestsMemory: resource.MustParse("12Gi")},
+	}
+	for _, nsData := range namespaceCapacityData {
+		setClusterPercent(nsData, allocatableCPU, allocatableMemory)
+	}
+
+	tests := []struct {
+		namespace  string
+		wantCPU    float64
+		wantMemory float64
+	}{
+		{"default", 25, 25},
+		{"kube-system", 12.5, 12.5},
+		{"*total*", 37.5, 37.5},
+	}
+	for _, tt := range tests {
+		if got := namespaceCapacityData[tt.namespace].RequestsCPUClusterPct; got != tt.wantCPU {
+			t.Errorf("%s RequestsCPUClusterPct = %v, want %v", tt.namespace, got, tt.wantCPU)
+		}
+		if got := namespaceCapacityData[tt.namespace].RequestsMemoryClusterPct; got != tt.wantMemory {
+			t.Errorf("%s RequestsMemoryClusterPct = %v, want %v", tt.namespace, got, tt.wantMemory)
+		}
+	}
+}
@@ -17,8 +17,7 @@ package capacity
 
 import (
 	"context"
-	"fmt"
-	"os"
+	"io"
 	"sort"
 	"strings"
 
@@ -28,7 +27,10 @@ import (
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/util/sets"
 )
 
@@ -37,193 +39,527 @@ var nodeCmd = &cobra.Command{
 	Aliases: []string{"no"},
 	Short:   "Get individual node capacity",
 	Long:    `Get metrics and data related to node capacity`,
-	PreRun: func(cmd *cobra.Command, args []string) {
-		if err := output.ValidateOutput(*cmd); err != nil {
-			fmt.Fprintf(os.Stderr, "error: %v\n", err)
-			os.Exit(1)
-		}
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		return output.ValidateOutput(*cmd)
 	},
 	RunE: func(cmd *cobra.Command, args []string) error {
+		return runWatch(cmd, runNode)
+	},
+}
 
-		clientset, err := kube.CreateClientSet(KubernetesConfigFlags)
-		if err != nil {
-			return errors.Wrap(err, "failed to create clientset")
-		}
+// runNode collects and displays node capacity data
+func runNode(ctx context.Context, cmd *cobra.Command) error {
+	podReservation, _ := cmd.Flags().GetInt("pod-reservation")
 
-		nodes, err := clientset.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{})
-		if err != nil {
-			return errors.Wrap(err, "failed to list nodes")
-		}
+	evictionRisk, _ := cmd.Flags().GetBool("eviction-risk")
+	memoryFloorFlag, _ := cmd.Flags().GetString("memory-floor")
+	memoryFloor, err := resource.ParseQuantity(memoryFloorFlag)
+	if err != nil {
+		return errors.Wrap(err, "failed to parse memory-floor")
+	}
+
+	labelSelectorFlag, _ := cmd.Flags().GetString("label-selector")
+	labelSelector, err := labels.Parse(labelSelectorFlag)
+	if err != nil {
+		return errors.Wrap(err, "failed to parse label-selector")
+	}
+
+	gpuResourceName, _ := cmd.Flags().GetString("gpu-resource-name")
+	gpuResource := corev1.ResourceName(gpuResourceName)
+	extraResources := getExtraResources(cmd)
+	excludeDaemonSets, _ := cmd.Flags().GetBool("exclude-daemonsets")
+	excludeDaemonSetCounts, _ := cmd.Flags().GetBool("exclude-daemonsets-from-counts")
+
+	clientset, err := kube.CreateClientSet(KubernetesConfigFlags)
+	if err != nil {
+		return errors.Wrap(err, "failed to create clientset")
+	}
 
-		pods, err := clientset.CoreV1().Pods("").List(context.TODO(), metav1.ListOptions{})
-		if err != nil {
-			return errors.Wrap(err, "failed to list pods")
+	chunkSize, _ := cmd.Flags().GetInt64("chunk-size")
+
+	// --node scopes the nodes listed, and the pods attributed, to a single node
+	nodeFlag, _ := cmd.Flags().GetString("node")
+	nodeListOptions := metav1.ListOptions{LabelSelector: labelSelector.String()}
+	if nodeFlag != "" {
+		nodeListOptions.FieldSelector = fields.OneTermEqualSelector("metadata.name", nodeFlag).String()
+	}
+
+	nodes, err := kube.ListNodes(ctx, clientset, nodeListOptions, chunkSize)
+	if err != nil {
+		return errors.Wrap(err, "failed to list nodes")
+	}
+	if nodeFlag != "" && len(nodes.Items) == 0 {
+		if labelSelectorFlag != "" {
+			return errors.Errorf("node %s not found or does not match --label-selector %s", nodeFlag, labelSelectorFlag)
 		}
+		return errors.Errorf("node %s not found", nodeFlag)
+	}
 
-		nodesCapacityData := make(map[string]*output.NodeCapacityData)
-		nodeNames := make([]string, 0, len(nodes.Items))
-		nodesByRole := make(map[string][]string)
+	// Capacity and allocatable remain node wide, only pod counts and requests are scoped by --namespace
+	nsFlag, _ := cmd.Flags().GetString("namespace")
+	podSelectors := make([]fields.Selector, 0, 2)
+	if nsFlag != "" {
+		podSelectors = append(podSelectors, fields.OneTermEqualSelector("metadata.namespace", nsFlag))
+	}
+	if nodeFlag != "" {
+		podSelectors = append(podSelectors, fields.OneTermEqualSelector("spec.nodeName", nodeFlag))
+	}
+	podListOptions := metav1.ListOptions{}
+	if len(podSelectors) > 0 {
+		podListOptions.FieldSelector = fields.AndSelectors(podSelectors...).String()
+	}
 
-		for _, node := range nodes.Items {
-			nodeNames = append(nodeNames, node.Name)
-			nodesCapacityData[node.Name] = new(output.NodeCapacityData)
+	podListOptions, err = withFieldSelector(cmd, podListOptions)
+	if err != nil {
+		return err
+	}
 
-			roles := sets.NewString()
-			for labelKey, labelValue := range node.Labels {
-				switch {
-				case strings.HasPrefix(labelKey, "node-role.kubernetes.io/"):
-					if role := strings.TrimPrefix(labelKey, "node-role.kubernetes.io/"); len(role) > 0 {
-						roles.Insert(role)
-					}
-				case labelKey == "kubernetes.io/role" && labelValue != "":
-					roles.Insert(labelValue)
-				}
-			}
-			if len(roles) == 0 {
-				roles.Insert("<none>")
+	pods, err := kube.ListPods(ctx, clientset, "", podListOptions, chunkSize)
+	if err != nil {
+		return errors.Wrap(err, "failed to list pods")
+	}
+	pods.Items = recentPods(cmd, namespacePods(cmd, pods.Items))
+
+	// --group-by sums the nodes by the value of a node label instead of displaying each node
+	if groupBy, _ := cmd.Flags().GetString("group-by"); groupBy != "" {
+		groupCapacityData, groupNames := collectNodeLabelData(cmd, nodes.Items, pods.Items, groupBy)
+		displayOptions := getDisplayOptions(cmd)
+		return writeOutput(cmd, func(out io.Writer) {
+			output.DisplayNodeLabelData(out, groupCapacityData, groupNames, groupBy, displayOptions)
+			if displayOptions.Explain {
+				output.DisplayExplain(out, displayOptions)
 			}
+		})
+	}
+
+	nodesCapacityData := make(map[string]*output.NodeCapacityData)
+	nodeNames := make([]string, 0, len(nodes.Items))
+	nodesByRole := make(map[string][]string)
+
+	for _, node := range nodes.Items {
+		nodeNames = append(nodeNames, node.Name)
+		nodesCapacityData[node.Name] = new(output.NodeCapacityData)
 
-			nodesCapacityData[node.Name].Ready = false
-			for _, condition := range node.Status.Conditions {
-				if (condition.Type == "Ready") && condition.Status == corev1.ConditionTrue {
-					nodesCapacityData[node.Name].Ready = true
-					break
+		roles := sets.NewString()
+		for labelKey, labelValue := range node.Labels {
+			switch {
+			case strings.HasPrefix(labelKey, "node-role.kubernetes.io/"):
+				if role := strings.TrimPrefix(labelKey, "node-role.kubernetes.io/"); len(role) > 0 {
+					roles.Insert(role)
 				}
+			case labelKey == "kubernetes.io/role" && labelValue != "":
+				roles.Insert(labelValue)
 			}
+		}
+		if len(roles) == 0 {
+			roles.Insert("<none>")
+		}
 
-			nodesCapacityData[node.Name].Schedulable = !node.Spec.Unschedulable
-			nodesCapacityData[node.Name].Roles = roles
-			nodesCapacityData[node.Name].TotalCapacityPods.Add(*node.Status.Capacity.Pods())
-			nodesCapacityData[node.Name].TotalCapacityCPU.Add(*node.Status.Capacity.Cpu())
-			nodesCapacityData[node.Name].TotalCapacityMemory.Add(*node.Status.Capacity.Memory())
-			nodesCapacityData[node.Name].TotalCapacityEphemeralStorage.Add(*node.Status.Capacity.StorageEphemeral())
-			nodesCapacityData[node.Name].TotalAllocatablePods.Add(*node.Status.Allocatable.Pods())
-			nodesCapacityData[node.Name].TotalAllocatableCPU.Add(*node.Status.Allocatable.Cpu())
-			nodesCapacityData[node.Name].TotalAllocatableMemory.Add(*node.Status.Allocatable.Memory())
-			nodesCapacityData[node.Name].TotalAllocatableEphemeralStorage.Add(*node.Status.Allocatable.StorageEphemeral())
-			rolesIndex := strings.Join(roles.List(), ",")
-			nodesByRole[rolesIndex] = append(nodesByRole[rolesIndex], node.Name)
-		}
-		nodesCapacityData["*unassigned*"] = new(output.NodeCapacityData)
-		nodesCapacityData["*total*"] = new(output.NodeCapacityData)
+		nodesCapacityData[node.Name].Ready = false
+		for _, condition := range node.Status.Conditions {
+			if (condition.Type == "Ready") && condition.Status == corev1.ConditionTrue {
+				nodesCapacityData[node.Name].Ready = true
+			}
+			if (condition.Type == "MemoryPressure") && condition.Status == corev1.ConditionTrue {
+				nodesCapacityData[node.Name].MemoryPressure = true
+			}
+			if (condition.Type == "DiskPressure") && condition.Status == corev1.ConditionTrue {
+				nodesCapacityData[node.Name].DiskPressure = true
+			}
+			if (condition.Type == "PIDPressure") && condition.Status == corev1.ConditionTrue {
+				nodesCapacityData[node.Name].PIDPressure = true
+			}
+		}
 
+		nodesCapacityData[node.Name].Schedulable = !node.Spec.Unschedulable
+		nodesCapacityData[node.Name].Roles = roles
+		for _, taint := range node.Spec.Taints {
+			nodesCapacityData[node.Name].Taints = append(nodesCapacityData[node.Name].Taints, capacity.FormatTaint(taint))
+		}
+		creationTimestamp := node.CreationTimestamp.Time
+		nodesCapacityData[node.Name].CreationTimestamp = &creationTimestamp
+		nodesCapacityData[node.Name].KubeletVersion = node.Status.NodeInfo.KubeletVersion
+		nodesCapacityData[node.Name].KernelVersion = node.Status.NodeInfo.KernelVersion
+		nodesCapacityData[node.Name].OSImage = node.Status.NodeInfo.OSImage
+		nodesCapacityData[node.Name].TotalCapacityPods.Add(*node.Status.Capacity.Pods())
+		nodesCapacityData[node.Name].TotalCapacityCPU.Add(*node.Status.Capacity.Cpu())
+		nodesCapacityData[node.Name].TotalCapacityMemory.Add(*node.Status.Capacity.Memory())
+		nodesCapacityData[node.Name].TotalCapacityEphemeralStorage.Add(*node.Status.Capacity.StorageEphemeral())
+		nodesCapacityData[node.Name].TotalAllocatablePods.Add(*node.Status.Allocatable.Pods())
+		nodesCapacityData[node.Name].TotalAllocatableCPU.Add(*node.Status.Allocatable.Cpu())
+		nodesCapacityData[node.Name].TotalAllocatableMemory.Add(*node.Status.Allocatable.Memory())
+		nodesCapacityData[node.Name].TotalAllocatableEphemeralStorage.Add(*node.Status.Allocatable.StorageEphemeral())
+		nodesCapacityData[node.Name].TotalCapacityGPU.Add(node.Status.Capacity[gpuResource])
+		nodesCapacityData[node.Name].TotalAllocatableGPU.Add(node.Status.Allocatable[gpuResource])
+		nodesCapacityData[node.Name].Hugepages = addHugepages(nodesCapacityData[node.Name].Hugepages, node.Status.Capacity, hugepagesCapacity)
+		nodesCapacityData[node.Name].Hugepages = addHugepages(nodesCapacityData[node.Name].Hugepages, node.Status.Allocatable, hugepagesAllocatable)
+		nodesCapacityData[node.Name].ExtraResources = addExtraResources(nodesCapacityData[node.Name].ExtraResources, extraResources, node.Status.Capacity, extraResourcesCapacity)
+		nodesCapacityData[node.Name].ExtraResources = addExtraResources(nodesCapacityData[node.Name].ExtraResources, extraResources, node.Status.Allocatable, extraResourcesAllocatable)
+		nodesCapacityData[node.Name].TotalReservedCPU.Add(capacity.Reserved(node, corev1.ResourceCPU))
+		nodesCapacityData[node.Name].TotalReservedMemory.Add(capacity.Reserved(node, corev1.ResourceMemory))
+		nodesCapacityData[node.Name].TotalReservedEphemeralStorage.Add(capacity.Reserved(node, corev1.ResourceEphemeralStorage))
+		rolesIndex := strings.Join(roles.List(), ",")
+		nodesByRole[rolesIndex] = append(nodesByRole[rolesIndex], node.Name)
+	}
+	nodesCapacityData["*unassigned*"] = new(output.NodeCapacityData)
+
+	// Usage is node wide unless --namespace is set, then the usage of pods in the namespace is summed per node
+	displayUsage, _ := cmd.Flags().GetBool("usage")
+	if displayUsage && nsFlag != "" {
+		podUsage := getPodUsage(ctx, KubernetesConfigFlags, nsFlag)
+		if podUsage == nil {
+			displayUsage = false
+		}
 		for _, pod := range pods.Items {
-			podNode := pod.Spec.NodeName
-			if pod.Spec.NodeName == "" {
-				podNode = "*unassigned*"
+			usage, ok := podUsage[pod.Name]
+			if !ok {
+				continue
 			}
+			if nodeData, ok := nodesCapacityData[pod.Spec.NodeName]; ok {
+				nodeData.TotalUsageCPU.Add(*usage.Cpu())
+				nodeData.TotalUsageMemory.Add(*usage.Memory())
+			}
+		}
+	} else if displayUsage {
+		nodeUsage := getNodeUsage(ctx, KubernetesConfigFlags)
+		if nodeUsage == nil {
+			displayUsage = false
+		}
+		for nodeName, usage := range nodeUsage {
+			if nodeData, ok := nodesCapacityData[nodeName]; ok {
+				nodeData.TotalUsageCPU.Add(*usage.Cpu())
+				nodeData.TotalUsageMemory.Add(*usage.Memory())
+			}
+		}
+	}
+
+	for _, pod := range pods.Items {
+		podNode := pod.Spec.NodeName
+		if pod.Spec.NodeName == "" {
+			podNode = "*unassigned*"
+		}
+		// Pods on nodes excluded by the label selector are not attributed
+		if _, ok := nodesCapacityData[podNode]; !ok {
+			continue
+		}
+		isDaemonSetPod := capacity.IsDaemonSetPod(pod)
+		if !(excludeDaemonSetCounts && isDaemonSetPod) {
 			nodesCapacityData[podNode].TotalPodCount++
+			countPodPhase(pod.Status.Phase, &nodesCapacityData[podNode].RunningPodCount, &nodesCapacityData[podNode].PendingPodCount, &nodesCapacityData[podNode].SucceededPodCount, &nodesCapacityData[podNode].FailedPodCount)
+		}
 
-			if (pod.Status.Phase != corev1.PodSucceeded) && (pod.Status.Phase != corev1.PodFailed) {
+		if (pod.Status.Phase != corev1.PodSucceeded) && (pod.Status.Phase != corev1.PodFailed) {
+			if !isDaemonSetPod {
+				nodesCapacityData[podNode].NonTermWorkloadPodCount++
+			}
+			if !(excludeDaemonSetCounts && isDaemonSetPod) {
 				nodesCapacityData[podNode].TotalNonTermPodCount++
-				for _, container := range pod.Spec.Containers {
-					nodesCapacityData[podNode].TotalRequestsCPU.Add(*container.Resources.Requests.Cpu())
-					nodesCapacityData[podNode].TotalLimitsCPU.Add(*container.Resources.Limits.Cpu())
-					nodesCapacityData[podNode].TotalRequestsMemory.Add(*container.Resources.Requests.Memory())
-					nodesCapacityData[podNode].TotalLimitsMemory.Add(*container.Resources.Limits.Memory())
-					nodesCapacityData[podNode].TotalRequestsEphemeralStorage.Add(*container.Resources.Requests.StorageEphemeral())
-					nodesCapacityData[podNode].TotalLimitsEphemeralStorage.Add(*container.Resources.Limits.StorageEphemeral())
+				nodesCapacityData[podNode].TotalContainerCount += len(pod.Spec.Containers)
+				nodesCapacityData[podNode].TotalInitContainerCount += len(pod.Spec.InitContainers)
+				if capacity.IsPodReady(pod) {
+					nodesCapacityData[podNode].ReadyPodCount++
 				}
 			}
+			if excludeDaemonSets && isDaemonSetPod {
+				continue
+			}
+			podRequests, podLimits := capacity.PodRequestsAndLimits(pod)
+			nodesCapacityData[podNode].TotalRequestsCPU.Add(*podRequests.Cpu())
+			nodesCapacityData[podNode].TotalLimitsCPU.Add(*podLimits.Cpu())
+			nodesCapacityData[podNode].TotalRequestsMemory.Add(*podRequests.Memory())
+			nodesCapacityData[podNode].TotalLimitsMemory.Add(*podLimits.Memory())
+			nodesCapacityData[podNode].TotalRequestsEphemeralStorage.Add(*podRequests.StorageEphemeral())
+			nodesCapacityData[podNode].TotalLimitsEphemeralStorage.Add(*podLimits.StorageEphemeral())
+			nodesCapacityData[podNode].TotalRequestsGPU.Add(podRequests[gpuResource])
+			nodesCapacityData[podNode].Hugepages = addHugepages(nodesCapacityData[podNode].Hugepages, podRequests, hugepagesRequests)
+			nodesCapacityData[podNode].ExtraResources = addExtraResources(nodesCapacityData[podNode].ExtraResources, extraResources, podRequests, extraResourcesRequests)
 		}
+	}
 
-		for _, node := range nodeNames {
-			nodesCapacityData[node].TotalAvailablePods = int(nodesCapacityData[node].TotalAllocatablePods.Value()) - nodesCapacityData[node].TotalNonTermPodCount
-			nodesCapacityData[node].TotalAvailableCPU = nodesCapacityData[node].TotalAllocatableCPU
-			nodesCapacityData[node].TotalAvailableCPU.Sub(nodesCapacityData[node].TotalRequestsCPU)
-			nodesCapacityData[node].TotalAvailableMemory = nodesCapacityData[node].TotalAllocatableMemory
-			nodesCapacityData[node].TotalAvailableMemory.Sub(nodesCapacityData[node].TotalRequestsMemory)
-			nodesCapacityData[node].TotalAvailableEphemeralStorage = nodesCapacityData[node].TotalAllocatableEphemeralStorage
+	// --ephemeral-usage subtracts the ephemeral storage pods actually use rather than their requests, which are rarely set
+	var ephemeralUsage map[string]resource.Quantity
+	if useEphemeralUsage, _ := cmd.Flags().GetBool("ephemeral-usage"); useEphemeralUsage {
+		ephemeralUsage = getEphemeralUsage(ctx, clientset, nodeNames)
+	}
+
+	for _, node := range nodeNames {
+		nodesCapacityData[node].TotalAvailablePods = int(nodesCapacityData[node].TotalAllocatablePods.Value()) - nodesCapacityData[node].TotalNonTermPodCount
+		nodesCapacityData[node].TotalAvailableCPU = nodesCapacityData[node].TotalAllocatableCPU
+		nodesCapacityData[node].TotalAvailableCPU.Sub(nodesCapacityData[node].TotalRequestsCPU)
+		nodesCapacityData[node].TotalAvailableMemory = nodesCapacityData[node].TotalAllocatableMemory
+		nodesCapacityData[node].TotalAvailableMemory.Sub(nodesCapacityData[node].TotalRequestsMemory)
+		nodesCapacityData[node].TotalAvailableEphemeralStorage = nodesCapacityData[node].TotalAllocatableEphemeralStorage
+		if usage, ok := ephemeralUsage[node]; ok {
+			nodesCapacityData[node].TotalUsageEphemeralStorage = usage
+			nodesCapacityData[node].TotalAvailableEphemeralStorage.Sub(usage)
+		} else {
 			nodesCapacityData[node].TotalAvailableEphemeralStorage.Sub(nodesCapacityData[node].TotalRequestsEphemeralStorage)
 		}
+		nodesCapacityData[node].TotalAvailableGPU = nodesCapacityData[node].TotalAllocatableGPU
+		nodesCapacityData[node].TotalAvailableGPU.Sub(nodesCapacityData[node].TotalRequestsGPU)
+		nodesCapacityData[node].TotalLimitsAvailableCPU = nodesCapacityData[node].TotalAllocatableCPU.DeepCopy()
+		nodesCapacityData[node].TotalLimitsAvailableCPU.Sub(nodesCapacityData[node].TotalLimitsCPU)
+		nodesCapacityData[node].TotalLimitsAvailableMemory = nodesCapacityData[node].TotalAllocatableMemory.DeepCopy()
+		nodesCapacityData[node].TotalLimitsAvailableMemory.Sub(nodesCapacityData[node].TotalLimitsMemory)
+		clampAvailable(cmd, &nodesCapacityData[node].TotalAvailablePods, &nodesCapacityData[node].TotalAvailableCPU, &nodesCapacityData[node].TotalAvailableMemory,
+			&nodesCapacityData[node].TotalAvailableEphemeralStorage, &nodesCapacityData[node].TotalAvailableGPU, &nodesCapacityData[node].TotalLimitsAvailableCPU,
+			&nodesCapacityData[node].TotalLimitsAvailableMemory)
+		setHugepagesAvailable(cmd, nodesCapacityData[node].Hugepages)
+		// Allocatable pods below capacity pods is commonly a CNI imposed limit (Ex IP addresses per cloud instance)
+		nodesCapacityData[node].PodReservationGap = int(nodesCapacityData[node].TotalCapacityPods.Value() - nodesCapacityData[node].TotalAllocatablePods.Value())
+		if podReservation >= 0 {
+			nodesCapacityData[node].PodReservationExceeded = nodesCapacityData[node].PodReservationGap > podReservation
+		}
+		if evictionRisk {
+			nodesCapacityData[node].EvictionRisk = nodesCapacityData[node].MemoryPressure || nodesCapacityData[node].TotalAvailableMemory.Cmp(memoryFloor) < 0
+		}
+	}
 
-		displayDefault, _ := cmd.Flags().GetBool("default-format")
-
-		displayEphemeralStorage, _ := cmd.Flags().GetBool("ephemeral-storage")
-
-		displayNoHeaders, _ := cmd.Flags().GetBool("no-headers")
-
-		displayFormat, _ := cmd.Flags().GetString("output")
-
-		sort.Strings(nodeNames)
-		if displayUnassigned, _ := cmd.Flags().GetBool("unassigned"); displayUnassigned {
-			nodeNames = append(nodeNames, "*unassigned*")
-			nodesByRole["~"] = append(nodesByRole["~"], "*unassigned*")
-		}
-
-		// Populate "Human" readable capacity data values and the *total* "node"
-		for _, node := range nodeNames {
-			nodesCapacityData[node].TotalCapacityCPUCores = capacity.ReadableCPU(nodesCapacityData[node].TotalCapacityCPU)
-			nodesCapacityData[node].TotalCapacityMemoryGiB = capacity.ReadableMem(nodesCapacityData[node].TotalCapacityMemory)
-			nodesCapacityData[node].TotalCapacityEphemeralStorageGB = capacity.ReadableStorage(nodesCapacityData[node].TotalCapacityEphemeralStorage)
-			nodesCapacityData[node].TotalAllocatableCPUCores = capacity.ReadableCPU(nodesCapacityData[node].TotalAllocatableCPU)
-			nodesCapacityData[node].TotalAllocatableMemoryGiB = capacity.ReadableMem(nodesCapacityData[node].TotalAllocatableMemory)
-			nodesCapacityData[node].TotalAllocatableEphemeralStorageGB = capacity.ReadableStorage(nodesCapacityData[node].TotalAllocatableEphemeralStorage)
-			nodesCapacityData[node].TotalRequestsCPUCores = capacity.ReadableCPU(nodesCapacityData[node].TotalRequestsCPU)
-			nodesCapacityData[node].TotalLimitsCPUCores = capacity.ReadableCPU(nodesCapacityData[node].TotalLimitsCPU)
-			nodesCapacityData[node].TotalAvailableCPUCores = capacity.ReadableCPU(nodesCapacityData[node].TotalAvailableCPU)
-			nodesCapacityData[node].TotalRequestsMemoryGiB = capacity.ReadableMem(nodesCapacityData[node].TotalRequestsMemory)
-			nodesCapacityData[node].TotalLimitsMemoryGiB = capacity.ReadableMem(nodesCapacityData[node].TotalLimitsMemory)
-			nodesCapacityData[node].TotalAvailableMemoryGiB = capacity.ReadableMem(nodesCapacityData[node].TotalAvailableMemory)
-			nodesCapacityData[node].TotalRequestsEphemeralStorageGB = capacity.ReadableStorage(nodesCapacityData[node].TotalRequestsEphemeralStorage)
-			nodesCapacityData[node].TotalLimitsEphemeralStorageGB = capacity.ReadableStorage(nodesCapacityData[node].TotalLimitsEphemeralStorage)
-			nodesCapacityData[node].TotalAvailableEphemeralStorageGB = capacity.ReadableStorage(nodesCapacityData[node].TotalAvailableEphemeralStorage)
-			nodesCapacityData["*total*"].TotalPodCount += nodesCapacityData[node].TotalPodCount
-			nodesCapacityData["*total*"].TotalNonTermPodCount += nodesCapacityData[node].TotalNonTermPodCount
-			nodesCapacityData["*total*"].TotalCapacityPods.Add(nodesCapacityData[node].TotalCapacityPods)
-			nodesCapacityData["*total*"].TotalCapacityCPU.Add(nodesCapacityData[node].TotalCapacityCPU)
-			nodesCapacityData["*total*"].TotalCapacityCPUCores += nodesCapacityData[node].TotalCapacityCPUCores
-			nodesCapacityData["*total*"].TotalCapacityMemory.Add(nodesCapacityData[node].TotalCapacityMemory)
-			nodesCapacityData["*total*"].TotalCapacityMemoryGiB += nodesCapacityData[node].TotalCapacityMemoryGiB
-			nodesCapacityData["*total*"].TotalCapacityEphemeralStorage.Add(nodesCapacityData[node].TotalCapacityEphemeralStorage)
-			nodesCapacityData["*total*"].TotalCapacityEphemeralStorageGB += nodesCapacityData[node].TotalCapacityEphemeralStorageGB
-			nodesCapacityData["*total*"].TotalAllocatablePods.Add(nodesCapacityData[node].TotalAllocatablePods)
-			nodesCapacityData["*total*"].TotalAllocatableCPU.Add(nodesCapacityData[node].TotalAllocatableCPU)
-			nodesCapacityData["*total*"].TotalAllocatableCPUCores += nodesCapacityData[node].TotalAllocatableCPUCores
-			nodesCapacityData["*total*"].TotalAllocatableMemory.Add(nodesCapacityData[node].TotalAllocatableMemory)
-			nodesCapacityData["*total*"].TotalAllocatableMemoryGiB += nodesCapacityData[node].TotalAllocatableMemoryGiB
-			nodesCapacityData["*total*"].TotalAllocatableEphemeralStorage.Add(nodesCapacityData[node].TotalAllocatableEphemeralStorage)
-			nodesCapacityData["*total*"].TotalAllocatableEphemeralStorageGB += nodesCapacityData[node].TotalAllocatableEphemeralStorageGB
-			nodesCapacityData["*total*"].TotalAvailablePods += nodesCapacityData[node].TotalAvailablePods
-			nodesCapacityData["*total*"].TotalRequestsCPU.Add(nodesCapacityData[node].TotalRequestsCPU)
-			nodesCapacityData["*total*"].TotalRequestsCPUCores += nodesCapacityData[node].TotalRequestsCPUCores
-			nodesCapacityData["*total*"].TotalLimitsCPU.Add(nodesCapacityData[node].TotalLimitsCPU)
-			nodesCapacityData["*total*"].TotalLimitsCPUCores += nodesCapacityData[node].TotalLimitsCPUCores
-			nodesCapacityData["*total*"].TotalAvailableCPU.Add(nodesCapacityData[node].TotalAvailableCPU)
-			nodesCapacityData["*total*"].TotalAvailableCPUCores += nodesCapacityData[node].TotalAvailableCPUCores
-			nodesCapacityData["*total*"].TotalRequestsMemory.Add(nodesCapacityData[node].TotalRequestsMemory)
-			nodesCapacityData["*total*"].TotalRequestsMemoryGiB += nodesCapacityData[node].TotalRequestsMemoryGiB
-			nodesCapacityData["*total*"].TotalLimitsMemory.Add(nodesCapacityData[node].TotalLimitsMemory)
-			nodesCapacityData["*total*"].TotalLimitsMemoryGiB += nodesCapacityData[node].TotalLimitsMemoryGiB
-			nodesCapacityData["*total*"].TotalAvailableMemory.Add(nodesCapacityData[node].TotalAvailableMemory)
-			nodesCapacityData["*total*"].TotalAvailableMemoryGiB += nodesCapacityData[node].TotalAvailableMemoryGiB
-			nodesCapacityData["*total*"].TotalRequestsEphemeralStorage.Add(nodesCapacityData[node].TotalRequestsEphemeralStorage)
-			nodesCapacityData["*total*"].TotalRequestsEphemeralStorageGB += nodesCapacityData[node].TotalRequestsEphemeralStorageGB
-			nodesCapacityData["*total*"].TotalLimitsEphemeralStorage.Add(nodesCapacityData[node].TotalLimitsEphemeralStorage)
-			nodesCapacityData["*total*"].TotalLimitsEphemeralStorageGB += nodesCapacityData[node].TotalLimitsEphemeralStorageGB
-			nodesCapacityData["*total*"].TotalAvailableEphemeralStorage.Add(nodesCapacityData[node].TotalAvailableEphemeralStorage)
-			nodesCapacityData["*total*"].TotalAvailableEphemeralStorageGB += nodesCapacityData[node].TotalAvailableEphemeralStorageGB
-		}
-
-		sortByRole, _ := cmd.Flags().GetBool("sort-by-role")
-
-		displayTotal, _ := cmd.Flags().GetBool("display-total")
-
-		if displayTotal {
-			nodeNames = append(nodeNames, "*total*")
-			nodesByRole["~"] = append(nodesByRole["~"], "*total*")
-		}
-
-		output.DisplayNodeData(nodesCapacityData, nodeNames, displayDefault, !displayNoHeaders, displayEphemeralStorage, displayFormat, sortByRole, nodesByRole)
-
-		return nil
-	},
+	displayOptions := getDisplayOptions(cmd)
+	displayOptions.PodReservation = podReservation >= 0
+	displayOptions.Usage = displayUsage
+	displayOptions.PodReadiness, _ = cmd.Flags().GetBool("pod-readiness")
+	displayOptions.Taints, _ = cmd.Flags().GetBool("taints")
+	displayOptions.Age, _ = cmd.Flags().GetBool("age")
+	displayOptions.Versions, _ = cmd.Flags().GetBool("versions")
+
+	sort.Strings(nodeNames)
+	if evictionRisk {
+		// List nodes at risk of eviction first while preserving the existing order within each group
+		atRisk := func(names []string) {
+			sort.SliceStable(names, func(i, j int) bool {
+				return nodesCapacityData[names[i]].EvictionRisk && !nodesCapacityData[names[j]].EvictionRisk
+			})
+		}
+		atRisk(nodeNames)
+		for role := range nodesByRole {
+			atRisk(nodesByRole[role])
+		}
+	}
+	reverseNames(cmd, nodeNames)
+	for role := range nodesByRole {
+		reverseNames(cmd, nodesByRole[role])
+	}
+	hideEmptyUnassigned, _ := cmd.Flags().GetBool("hide-empty-unassigned")
+	if displayUnassigned, _ := cmd.Flags().GetBool("unassigned"); displayUnassigned && !(hideEmptyUnassigned && nodesCapacityData["*unassigned*"].TotalPodCount == 0) {
+		nodeNames = append(nodeNames, "*unassigned*")
+		nodesByRole["~"] = append(nodesByRole["~"], "*unassigned*")
+	}
+
+	// Populate "Human" readable capacity data values
+	for _, node := range nodeNames {
+		nodesCapacityData[node].TotalCapacityCPUCores = capacity.ReadableCPU(nodesCapacityData[node].TotalCapacityCPU)
+		nodesCapacityData[node].TotalCapacityMemoryGiB = capacity.ReadableMem(nodesCapacityData[node].TotalCapacityMemory)
+		nodesCapacityData[node].TotalCapacityEphemeralStorageGB = capacity.ReadableStorage(nodesCapacityData[node].TotalCapacityEphemeralStorage)
+		nodesCapacityData[node].TotalAllocatableCPUCores = capacity.ReadableCPU(nodesCapacityData[node].TotalAllocatableCPU)
+		nodesCapacityData[node].TotalAllocatableMemoryGiB = capacity.ReadableMem(nodesCapacityData[node].TotalAllocatableMemory)
+		nodesCapacityData[node].TotalAllocatableEphemeralStorageGB = capacity.ReadableStorage(nodesCapacityData[node].TotalAllocatableEphemeralStorage)
+		nodesCapacityData[node].TotalRequestsCPUCores = capacity.ReadableCPU(nodesCapacityData[node].TotalRequestsCPU)
+		nodesCapacityData[node].TotalLimitsCPUCores = capacity.ReadableCPU(nodesCapacityData[node].TotalLimitsCPU)
+		nodesCapacityData[node].TotalAvailableCPUCores = capacity.ReadableCPU(nodesCapacityData[node].TotalAvailableCPU)
+		nodesCapacityData[node].TotalRequestsMemoryGiB = capacity.ReadableMem(nodesCapacityData[node].TotalRequestsMemory)
+		nodesCapacityData[node].TotalLimitsMemoryGiB = capacity.ReadableMem(nodesCapacityData[node].TotalLimitsMemory)
+		nodesCapacityData[node].TotalAvailableMemoryGiB = capacity.ReadableMem(nodesCapacityData[node].TotalAvailableMemory)
+		nodesCapacityData[node].TotalRequestsEphemeralStorageGB = capacity.ReadableStorage(nodesCapacityData[node].TotalRequestsEphemeralStorage)
+		nodesCapacityData[node].TotalLimitsEphemeralStorageGB = capacity.ReadableStorage(nodesCapacityData[node].TotalLimitsEphemeralStorage)
+		nodesCapacityData[node].TotalAvailableEphemeralStorageGB = capacity.ReadableStorage(nodesCapacityData[node].TotalAvailableEphemeralStorage)
+		nodesCapacityData[node].TotalReservedCPUCores = capacity.ReadableCPU(nodesCapacityData[node].TotalReservedCPU)
+		nodesCapacityData[node].TotalReservedMemoryGiB = capacity.ReadableMem(nodesCapacityData[node].TotalReservedMemory)
+		nodesCapacityData[node].TotalReservedEphemeralStorageGB = capacity.ReadableStorage(nodesCapacityData[node].TotalReservedEphemeralStorage)
+		nodesCapacityData[node].TotalLimitsAvailableCPUCores = capacity.ReadableCPU(nodesCapacityData[node].TotalLimitsAvailableCPU)
+		nodesCapacityData[node].TotalLimitsAvailableMemoryGiB = capacity.ReadableMem(nodesCapacityData[node].TotalLimitsAvailableMemory)
+		nodesCapacityData[node].TotalUsageCPUCores = capacity.ReadableCPU(nodesCapacityData[node].TotalUsageCPU)
+		nodesCapacityData[node].TotalUsageMemoryGiB = capacity.ReadableMem(nodesCapacityData[node].TotalUsageMemory)
+		nodesCapacityData[node].TotalUsageEphemeralStorageGB = capacity.ReadableStorage(nodesCapacityData[node].TotalUsageEphemeralStorage)
+		nodesCapacityData[node].TotalCapacityGPUCount = int(nodesCapacityData[node].TotalCapacityGPU.Value())
+		nodesCapacityData[node].TotalAllocatableGPUCount = int(nodesCapacityData[node].TotalAllocatableGPU.Value())
+		nodesCapacityData[node].TotalRequestsGPUCount = int(nodesCapacityData[node].TotalRequestsGPU.Value())
+		nodesCapacityData[node].TotalAvailableGPUCount = int(nodesCapacityData[node].TotalAvailableGPU.Value())
+		setHugepagesReadable(nodesCapacityData[node].Hugepages)
+		nodesCapacityData[node].RequestsCPUUtilization = capacity.Utilization(nodesCapacityData[node].TotalRequestsCPU, nodesCapacityData[node].TotalAllocatableCPU)
+		nodesCapacityData[node].RequestsMemoryUtilization = capacity.Utilization(nodesCapacityData[node].TotalRequestsMemory, nodesCapacityData[node].TotalAllocatableMemory)
+		nodesCapacityData[node].PodUtilization = capacity.Utilization(*resource.NewQuantity(int64(nodesCapacityData[node].TotalNonTermPodCount), resource.DecimalSI), nodesCapacityData[node].TotalAllocatablePods)
+		nodesCapacityData[node].AllocatableCPUEfficiency = capacity.Utilization(nodesCapacityData[node].TotalAllocatableCPU, nodesCapacityData[node].TotalCapacityCPU)
+		nodesCapacityData[node].AllocatableMemoryEfficiency = capacity.Utilization(nodesCapacityData[node].TotalAllocatableMemory, nodesCapacityData[node].TotalCapacityMemory)
+		nodesCapacityData[node].CPULimitRequestRatio = capacity.Ratio(nodesCapacityData[node].TotalLimitsCPU, nodesCapacityData[node].TotalRequestsCPU)
+		nodesCapacityData[node].MemoryLimitRequestRatio = capacity.Ratio(nodesCapacityData[node].TotalLimitsMemory, nodesCapacityData[node].TotalRequestsMemory)
+	}
+	nodesCapacityData["*total*"] = sumNodeTotal(cmd, nodesCapacityData, nodeNames)
+
+	displayOptions.SortByRole, _ = cmd.Flags().GetBool("sort-by-role")
+	displayOptions.Ratios, _ = cmd.Flags().GetBool("ratios")
+	displayOptions.Efficiency, _ = cmd.Flags().GetBool("efficiency")
+	displayOptions.FullThreshold, _ = cmd.Flags().GetFloat64("full-threshold")
+
+	// --only-empty and --top only limit the rows displayed, the *total* "node" above still sums every node
+	nodeNames = emptyNodeNames(cmd, nodesCapacityData, nodeNames)
+	nodeNames = topNames(cmd, nodeNames)
+	topNodes := sets.NewString(nodeNames...)
+	for role := range nodesByRole {
+		roleNodes := make([]string, 0, len(nodesByRole[role]))
+		for _, node := range nodesByRole[role] {
+			if topNodes.Has(node) {
+				roleNodes = append(roleNodes, node)
+			}
+		}
+		nodesByRole[role] = roleNodes
+	}
+
+	displayTotal, _ := cmd.Flags().GetBool("display-total")
+	quiet, _ := cmd.Flags().GetBool("quiet")
+
+	// --quiet displays only the *total* row, json and yaml output only the *total* object
+	if quiet {
+		nodeNames = []string{"*total*"}
+		nodesByRole = map[string][]string{"~": {"*total*"}}
+		nodesCapacityData = map[string]*output.NodeCapacityData{"*total*": nodesCapacityData["*total*"]}
+	} else if displayTotal {
+		nodeNames = append(nodeNames, "*total*")
+		nodesByRole["~"] = append(nodesByRole["~"], "*total*")
+	}
+
+	return writeOutput(cmd, func(out io.Writer) {
+		output.DisplayNodeData(out, nodesCapacityData, nodeNames, nodesByRole, displayOptions)
+		if displayOptions.Explain {
+			output.DisplayExplain(out, displayOptions)
+		}
+	})
+}
+
+// sumNodeTotal returns the *total* "node", the sum of the capacity data of nodeNames. The *unassigned* row is skipped when
+// --no-unassigned-in-total is set so unscheduled pods do not count against the total scheduled load
+func sumNodeTotal(cmd *cobra.Command, nodesCapacityData map[string]*output.NodeCapacityData, nodeNames []string) *output.NodeCapacityData {
+	noUnassignedInTotal, _ := cmd.Flags().GetBool("no-unassigned-in-total")
+	total := new(output.NodeCapacityData)
+	for _, node := range nodeNames {
+		if node == "*unassigned*" && noUnassignedInTotal {
+			continue
+		}
+		total.TotalPodCount += nodesCapacityData[node].TotalPodCount
+		total.TotalNonTermPodCount += nodesCapacityData[node].TotalNonTermPodCount
+		total.TotalContainerCount += nodesCapacityData[node].TotalContainerCount
+		total.TotalInitContainerCount += nodesCapacityData[node].TotalInitContainerCount
+		total.RunningPodCount += nodesCapacityData[node].RunningPodCount
+		total.PendingPodCount += nodesCapacityData[node].PendingPodCount
+		total.SucceededPodCount += nodesCapacityData[node].SucceededPodCount
+		total.FailedPodCount += nodesCapacityData[node].FailedPodCount
+		total.ReadyPodCount += nodesCapacityData[node].ReadyPodCount
+		total.NonTermWorkloadPodCount += nodesCapacityData[node].NonTermWorkloadPodCount
+		total.TotalCapacityPods.Add(nodesCapacityData[node].TotalCapacityPods)
+		total.TotalCapacityCPU.Add(nodesCapacityData[node].TotalCapacityCPU)
+		total.TotalCapacityCPUCores += nodesCapacityData[node].TotalCapacityCPUCores
+		total.TotalCapacityMemory.Add(nodesCapacityData[node].TotalCapacityMemory)
+		total.TotalCapacityMemoryGiB += nodesCapacityData[node].TotalCapacityMemoryGiB
+		total.TotalCapacityEphemeralStorage.Add(nodesCapacityData[node].TotalCapacityEphemeralStorage)
+		total.TotalCapacityEphemeralStorageGB += nodesCapacityData[node].TotalCapacityEphemeralStorageGB
+		total.TotalAllocatablePods.Add(nodesCapacityData[node].TotalAllocatablePods)
+		total.TotalAllocatableCPU.Add(nodesCapacityData[node].TotalAllocatableCPU)
+		total.TotalAllocatableCPUCores += nodesCapacityData[node].TotalAllocatableCPUCores
+		total.TotalAllocatableMemory.Add(nodesCapacityData[node].TotalAllocatableMemory)
+		total.TotalAllocatableMemoryGiB += nodesCapacityData[node].TotalAllocatableMemoryGiB
+		total.TotalAllocatableEphemeralStorage.Add(nodesCapacityData[node].TotalAllocatableEphemeralStorage)
+		total.TotalAllocatableEphemeralStorageGB += nodesCapacityData[node].TotalAllocatableEphemeralStorageGB
+		total.TotalAvailablePods += nodesCapacityData[node].TotalAvailablePods
+		total.PodReservationGap += nodesCapacityData[node].PodReservationGap
+		total.TotalRequestsCPU.Add(nodesCapacityData[node].TotalRequestsCPU)
+		total.TotalRequestsCPUCores += nodesCapacityData[node].TotalRequestsCPUCores
+		total.TotalLimitsCPU.Add(nodesCapacityData[node].TotalLimitsCPU)
+		total.TotalLimitsCPUCores += nodesCapacityData[node].TotalLimitsCPUCores
+		total.TotalAvailableCPU.Add(nodesCapacityData[node].TotalAvailableCPU)
+		total.TotalAvailableCPUCores += nodesCapacityData[node].TotalAvailableCPUCores
+		total.TotalRequestsMemory.Add(nodesCapacityData[node].TotalRequestsMemory)
+		total.TotalRequestsMemoryGiB += nodesCapacityData[node].TotalRequestsMemoryGiB
+		total.TotalLimitsMemory.Add(nodesCapacityData[node].TotalLimitsMemory)
+		total.TotalLimitsMemoryGiB += nodesCapacityData[node].TotalLimitsMemoryGiB
+		total.TotalAvailableMemory.Add(nodesCapacityData[node].TotalAvailableMemory)
+		total.TotalAvailableMemoryGiB += nodesCapacityData[node].TotalAvailableMemoryGiB
+		total.TotalRequestsEphemeralStorage.Add(nodesCapacityData[node].TotalRequestsEphemeralStorage)
+		total.TotalRequestsEphemeralStorageGB += nodesCapacityData[node].TotalRequestsEphemeralStorageGB
+		total.TotalLimitsEphemeralStorage.Add(nodesCapacityData[node].TotalLimitsEphemeralStorage)
+		total.TotalLimitsEphemeralStorageGB += nodesCapacityData[node].TotalLimitsEphemeralStorageGB
+		total.TotalAvailableEphemeralStorage.Add(nodesCapacityData[node].TotalAvailableEphemeralStorage)
+		total.TotalAvailableEphemeralStorageGB += nodesCapacityData[node].TotalAvailableEphemeralStorageGB
+		total.TotalReservedCPU.Add(nodesCapacityData[node].TotalReservedCPU)
+		total.TotalReservedCPUCores += nodesCapacityData[node].TotalReservedCPUCores
+		total.TotalReservedMemory.Add(nodesCapacityData[node].TotalReservedMemory)
+		total.TotalReservedMemoryGiB += nodesCapacityData[node].TotalReservedMemoryGiB
+		total.TotalReservedEphemeralStorage.Add(nodesCapacityData[node].TotalReservedEphemeralStorage)
+		total.TotalReservedEphemeralStorageGB += nodesCapacityData[node].TotalReservedEphemeralStorageGB
+		total.TotalLimitsAvailableCPU.Add(nodesCapacityData[node].TotalLimitsAvailableCPU)
+		total.TotalLimitsAvailableCPUCores += nodesCapacityData[node].TotalLimitsAvailableCPUCores
+		total.TotalLimitsAvailableMemory.Add(nodesCapacityData[node].TotalLimitsAvailableMemory)
+		total.TotalLimitsAvailableMemoryGiB += nodesCapacityData[node].TotalLimitsAvailableMemoryGiB
+		total.TotalUsageCPU.Add(nodesCapacityData[node].TotalUsageCPU)
+		total.TotalUsageCPUCores += nodesCapacityData[node].TotalUsageCPUCores
+		total.TotalUsageMemory.Add(nodesCapacityData[node].TotalUsageMemory)
+		total.TotalUsageMemoryGiB += nodesCapacityData[node].TotalUsageMemoryGiB
+		total.TotalUsageEphemeralStorage.Add(nodesCapacityData[node].TotalUsageEphemeralStorage)
+		total.TotalUsageEphemeralStorageGB += nodesCapacityData[node].TotalUsageEphemeralStorageGB
+		total.TotalCapacityGPU.Add(nodesCapacityData[node].TotalCapacityGPU)
+		total.TotalCapacityGPUCount += nodesCapacityData[node].TotalCapacityGPUCount
+		total.TotalAllocatableGPU.Add(nodesCapacityData[node].TotalAllocatableGPU)
+		total.TotalAllocatableGPUCount += nodesCapacityData[node].TotalAllocatableGPUCount
+		total.TotalRequestsGPU.Add(nodesCapacityData[node].TotalRequestsGPU)
+		total.TotalRequestsGPUCount += nodesCapacityData[node].TotalRequestsGPUCount
+		total.TotalAvailableGPU.Add(nodesCapacityData[node].TotalAvailableGPU)
+		total.TotalAvailableGPUCount += nodesCapacityData[node].TotalAvailableGPUCount
+		total.Hugepages = sumHugepages(total.Hugepages, nodesCapacityData[node].Hugepages)
+		total.ExtraResources = sumExtraResources(total.ExtraResources, nodesCapacityData[node].ExtraResources)
+	}
+	setHugepagesReadable(total.Hugepages)
+
+	total.RequestsCPUUtilization = capacity.Utilization(total.TotalRequestsCPU, total.TotalAllocatableCPU)
+	total.RequestsMemoryUtilization = capacity.Utilization(total.TotalRequestsMemory, total.TotalAllocatableMemory)
+	total.PodUtilization = capacity.Utilization(*resource.NewQuantity(int64(total.TotalNonTermPodCount), resource.DecimalSI), total.TotalAllocatablePods)
+	total.AllocatableCPUEfficiency = capacity.Utilization(total.TotalAllocatableCPU, total.TotalCapacityCPU)
+	total.AllocatableMemoryEfficiency = capacity.Utilization(total.TotalAllocatableMemory, total.TotalCapacityMemory)
+	total.CPULimitRequestRatio = capacity.Ratio(total.TotalLimitsCPU, total.TotalRequestsCPU)
+	total.MemoryLimitRequestRatio = capacity.Ratio(total.TotalLimitsMemory, total.TotalRequestsMemory)
+	return total
+}
+
+// emptyNodeNames keeps the nodes running at most --empty-threshold non-terminated pods other than DaemonSet pods when
+// --only-empty is set, these nodes are candidates to drain. Pseudo-rows (Ex *unassigned*) are always kept
+func emptyNodeNames(cmd *cobra.Command, nodesCapacityData map[string]*output.NodeCapacityData, nodeNames []string) []string {
+	if onlyEmpty, _ := cmd.Flags().GetBool("only-empty"); !onlyEmpty {
+		return nodeNames
+	}
+	emptyThreshold, _ := cmd.Flags().GetInt("empty-threshold")
+	emptyNodes := make([]string, 0, len(nodeNames))
+	for _, node := range nodeNames {
+		if strings.HasPrefix(node, "*") || nodesCapacityData[node].NonTermWorkloadPodCount <= emptyThreshold {
+			emptyNodes = append(emptyNodes, node)
+		}
+	}
+	return emptyNodes
 }
 
 func init() {
 	rootCmd.AddCommand(nodeCmd)
-	nodeCmd.Flags().BoolP("ephemeral-storage", "e", false, "Include ephemeral storage capacity data in table output")
+	addCapacityFlags(nodeCmd)
+	nodeCmd.Flags().BoolP("ratios", "", false, "Include cpu and memory limits to requests ratios in table output")
+	nodeCmd.Flags().BoolP("efficiency", "", false, "Include allocatable as a percentage of capacity for cpu and memory in table output")
+	nodeCmd.Flags().BoolP("ephemeral-usage", "", false, "Compute available ephemeral storage from the ephemeral storage pods use, read from the kubelet stats summary, instead of their requests")
+	nodeCmd.Flags().BoolP("usage", "", false, "Include actual cpu and memory usage from the metrics API (metrics-server) in table output, the usage of pods in --namespace when set")
 	nodeCmd.Flags().BoolP("sort-by-role", "r", false, "Sort output by node-role")
+	nodeCmd.Flags().StringP("group-by", "", "", "Sum node capacity data by the value of this node label instead of displaying each node (Ex node.kubernetes.io/instance-type)")
+	nodeCmd.Flags().BoolP("taints", "", false, "Include the node taints (key=value:effect) in table output")
+	nodeCmd.Flags().BoolP("age", "", false, "Include the node age (Ex 12d) in table output")
+	nodeCmd.Flags().BoolP("versions", "", false, "Include the kubelet version, os image and kernel version of each node and a count of nodes per kubelet version in table output")
+	nodeCmd.Flags().BoolP("pod-readiness", "", false, "Include the count of Ready non-terminated pods in table output")
+	nodeCmd.Flags().BoolP("only-empty", "", false, "Only include nodes running no pods other than DaemonSet pods, up to --empty-threshold")
+	nodeCmd.Flags().IntP("empty-threshold", "", 0, "Maximum non-terminated pods other than DaemonSet pods for a node to count as empty with --only-empty")
+	nodeCmd.Flags().Float64P("full-threshold", "", 90, "Requests utilization percent of allocatable above which a node counts as nearly full in the --utilization footer")
+	nodeCmd.Flags().IntP("top", "", 0, "Only include the first N nodes after sorting in the output, 0 includes all")
 	nodeCmd.Flags().BoolP("display-total", "t", false, "Display sum of all node capacity data in table output")
+	nodeCmd.Flags().BoolP("quiet", "q", false, "Only display the *total* row, implies --display-total. Json and yaml output only the *total* object")
 	nodeCmd.Flags().BoolP("unassigned", "u", false, "Include unassigned pod row, pods which do not have a node")
+	nodeCmd.Flags().BoolP("no-unassigned-in-total", "", false, "Exclude the unassigned pod row from the *total* row, the unassigned row is still displayed")
+	nodeCmd.Flags().BoolP("hide-empty-unassigned", "", false, "Omit the unassigned pod row when there are no unassigned pods")
+	nodeCmd.Flags().BoolP("eviction-risk", "", false, "Mark and list first nodes with MemoryPressure or available memory below --memory-floor")
+	nodeCmd.Flags().StringP("memory-floor", "", "0", "Available memory below which a node is at risk of eviction (Ex 512Mi)")
+	nodeCmd.Flags().DurationP("since", "", 0, "Only count pods created within this duration (Ex 1h), capacity and allocatable are unaffected. 0 counts all pods")
+	nodeCmd.Flags().StringP("node", "", "", "Only include this node and the pods assigned to it")
+	nodeCmd.Flags().StringP("label-selector", "l", "", "Only include nodes matching this label selector (Ex topology.kubernetes.io/zone=us-east-1a)")
+	nodeCmd.Flags().IntP("pod-reservation", "", -1, "Display the capacity minus allocatable pods gap and mark nodes whose gap exceeds this value, disabled when negative")
 }
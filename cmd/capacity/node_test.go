@@ -0,0 +1,120 @@
+/*
+Copyright © 2021 Alex Krzos akrzos@redhat.com
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package capacity
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/akrzos/kubeSize/internal/output"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSumNodeTotalNoUnassignedInTotal(t *testing.T) {
+	nodesCapacityData := map[string]*output.NodeCapacityData{
+		"worker-0":     {TotalPodCount: 4, TotalNonTermPodCount: 4, TotalRequestsCPU: resource.MustParse("2")},
+		"*unassigned*": {TotalPodCount: 3, TotalNonTermPodCount: 3, TotalRequestsCPU: resource.MustParse("1500m")},
+	}
+	nodeNames := []string{"worker-0", "*unassigned*"}
+
+	total := sumNodeTotal(nodeCmd, nodesCapacityData, nodeNames)
+	if total.TotalPodCount != 7 {
+		t.Errorf("TotalPodCount = %d, want 7", total.TotalPodCount)
+	}
+	if want := resource.MustParse("3500m"); total.TotalRequestsCPU.Cmp(want) != 0 {
+		t.Errorf("TotalRequestsCPU = %s, want 3500m", total.TotalRequestsCPU.String())
+	}
+
+	setFlags(t, nodeCmd, map[string]string{"no-unassigned-in-total": "true"})
+	total = sumNodeTotal(nodeCmd, nodesCapacityData, nodeNames)
+	if total.TotalPodCount != 4 {
+		t.Errorf("--no-unassigned-in-total TotalPodCount = %d, want 4", total.TotalPodCount)
+	}
+	if want := resource.MustParse("2"); total.TotalRequestsCPU.Cmp(want) != 0 {
+		t.Errorf("--no-unassigned-in-total TotalRequestsCPU = %s, want 2", total.TotalRequestsCPU.String())
+	}
+}
+
+func TestEmptyNodeNames(t *testing.T) {
+	nodesCapacityData := map[string]*output.NodeCapacityData{
+		"worker-0":     {TotalNonTermPodCount: 3, NonTermWorkloadPodCount: 0},
+		"worker-1":     {TotalNonTermPodCount: 4, NonTermWorkloadPodCount: 1},
+		"worker-2":     {TotalNonTermPodCount: 9, NonTermWorkloadPodCount: 6},
+		"*unassigned*": {TotalNonTermPodCount: 2, NonTermWorkloadPodCount: 2},
+	}
+	nodeNames := []string{"worker-0", "worker-1", "worker-2", "*unassigned*"}
+
+	if names := emptyNodeNames(nodeCmd, nodesCapacityData, nodeNames); len(names) != 4 {
+		t.Errorf("names without --only-empty = %v, want every node", names)
+	}
+	setFlags(t, nodeCmd, map[string]string{"only-empty": "true"})
+	if names := strings.Join(emptyNodeNames(nodeCmd, nodesCapacityData, nodeNames), ","); names != "worker-0,*unassigned*" {
+		t.Errorf("--only-empty names = %s, want worker-0,*unassigned*", names)
+	}
+	setFlags(t, nodeCmd, map[string]string{"empty-threshold": "1"})
+	if names := strings.Join(emptyNodeNames(nodeCmd, nodesCapacityData, nodeNames), ","); names != "worker-0,worker-1,*unassigned*" {
+		t.Errorf("--only-empty --empty-threshold 1 names = %s, want worker-0,worker-1,*unassigned*", names)
+	}
+}
+
+func TestRecentPods(t *testing.T) {
+	recent := testPod("app-0", "worker-0", nil)
+	recent.CreationTimestamp = metav1.NewTime(time.Now().Add(-10 * time.Minute))
+	old := testPod("app-1", "worker-0", nil)
+	old.CreationTimestamp = metav1.NewTime(time.Now().Add(-2 * time.Hour))
+	pods := []corev1.Pod{recent, old}
+
+	if got := recentPods(nodeCmd, pods); len(got) != 2 {
+		t.Errorf("pods without --since = %d, want 2", len(got))
+	}
+	setFlags(t, nodeCmd, map[string]string{"since": "1h"})
+	if got := recentPods(nodeCmd, pods); len(got) != 1 || got[0].Name != "app-0" {
+		t.Errorf("--since 1h pods = %v, want only app-0", got)
+	}
+}
+
+func TestNamespacePods(t *testing.T) {
+	pods := []corev1.Pod{testPod("app-0", "worker-0", nil), testPod("prometheus-0", "worker-0", nil), testPod("fluentd-0", "worker-0", nil)}
+	pods[1].Namespace = "monitoring"
+	pods[2].Namespace = "logging"
+	podNames := func(pods []corev1.Pod) string {
+		names := make([]string, 0, len(pods))
+		for _, pod := range pods {
+			names = append(names, pod.Name)
+		}
+		return strings.Join(names, ",")
+	}
+
+	if got := podNames(namespacePods(nodeCmd, pods)); got != "app-0,prometheus-0,fluentd-0" {
+		t.Errorf("pods without namespace filters = %s, want every pod", got)
+	}
+	setFlags(t, nodeCmd, map[string]string{"exclude-namespaces": "monitoring,logging"})
+	if got := podNames(namespacePods(nodeCmd, pods)); got != "app-0" {
+		t.Errorf("--exclude-namespaces monitoring,logging pods = %s, want app-0", got)
+	}
+	// A namespace in both lists is excluded
+	setFlags(t, nodeCmd, map[string]string{"include-namespaces": "default,logging"})
+	if got := podNames(namespacePods(nodeCmd, pods)); got != "app-0" {
+		t.Errorf("--include-namespaces default,logging --exclude-namespaces monitoring,logging pods = %s, want app-0", got)
+	}
+	setFlags(t, nodeCmd, map[string]string{"exclude-namespaces": ""})
+	if got := podNames(namespacePods(nodeCmd, pods)); got != "app-0,fluentd-0" {
+		t.Errorf("--include-namespaces default,logging pods = %s, want app-0,fluentd-0", got)
+	}
+}
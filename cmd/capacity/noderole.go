@@ -18,6 +18,8 @@ package capacity
 import (
 	"context"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"os"
 	"sort"
 	"strings"
@@ -28,158 +30,381 @@ import (
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/yaml"
 )
 
+// nodeGroup is a user defined group of nodes selected by a label selector
+type nodeGroup struct {
+	Name     string `json:"name"`
+	Selector string `json:"selector"`
+	selector labels.Selector
+}
+
+// loadNodeGroups reads node group definitions from a yaml file of the form:
+//
+//	groups:
+//	- name: gpu
+//	  selector: node.kubernetes.io/instance-type in (p3.2xlarge,p3.8xlarge)
+func loadNodeGroups(path string) ([]nodeGroup, error) {
+	groupsData, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read groups file")
+	}
+	groupsFile := struct {
+		Groups []nodeGroup `json:"groups"`
+	}{}
+	if err := yaml.Unmarshal(groupsData, &groupsFile); err != nil {
+		return nil, errors.Wrap(err, "failed to parse groups file")
+	}
+	if len(groupsFile.Groups) == 0 {
+		return nil, errors.Errorf("no groups defined in groups file %s", path)
+	}
+	groupNames := sets.NewString()
+	for i := range groupsFile.Groups {
+		group := &groupsFile.Groups[i]
+		if group.Name == "" || groupNames.Has(group.Name) {
+			return nil, errors.Errorf("group names must be unique and non-empty, found \"%s\"", group.Name)
+		}
+		groupNames.Insert(group.Name)
+		group.selector, err = labels.Parse(group.Selector)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse selector for group %s", group.Name)
+		}
+	}
+	return groupsFile.Groups, nil
+}
+
 var nodeRoleCmd = &cobra.Command{
 	Use:     "node-role",
 	Aliases: []string{"nr"},
 	Short:   "Get cluster capacity data grouped by node role",
 	Long:    `Get metrics and data related to cluster capacity grouped by node role`,
-	PreRun: func(cmd *cobra.Command, args []string) {
+	PreRunE: func(cmd *cobra.Command, args []string) error {
 		if err := output.ValidateOutput(*cmd); err != nil {
-			fmt.Fprintf(os.Stderr, "error: %v\n", err)
-			os.Exit(1)
+			return err
 		}
+		dedupTotal, _ := cmd.Flags().GetBool("dedup-total")
+		displayTotal, _ := cmd.Flags().GetBool("display-total")
+		if dedupTotal && !displayTotal {
+			return errors.New("--dedup-total requires --display-total")
+		}
+		return nil
 	},
 	RunE: func(cmd *cobra.Command, args []string) error {
+		return runWatch(cmd, runNodeRole)
+	},
+}
 
-		clientset, err := kube.CreateClientSet(KubernetesConfigFlags)
+// runNodeRole collects and displays cluster capacity data grouped by node role
+func runNodeRole(ctx context.Context, cmd *cobra.Command) error {
+	groupsFile, _ := cmd.Flags().GetString("groups-file")
+	groups := make([]nodeGroup, 0)
+	if groupsFile != "" {
+		fileGroups, err := loadNodeGroups(groupsFile)
 		if err != nil {
-			return errors.Wrap(err, "failed to create clientset")
+			return err
 		}
+		groups = fileGroups
+	}
 
-		nodes, err := clientset.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{})
-		if err != nil {
-			return errors.Wrap(err, "failed to list nodes")
-		}
+	labelSelectorFlag, _ := cmd.Flags().GetString("label-selector")
+	labelSelector, err := labels.Parse(labelSelectorFlag)
+	if err != nil {
+		return errors.Wrap(err, "failed to parse label-selector")
+	}
 
-		pods, err := clientset.CoreV1().Pods("").List(context.TODO(), metav1.ListOptions{})
-		if err != nil {
-			return errors.Wrap(err, "failed to list pods")
+	clientset, err := kube.CreateClientSet(KubernetesConfigFlags)
+	if err != nil {
+		return errors.Wrap(err, "failed to create clientset")
+	}
+
+	chunkSize, _ := cmd.Flags().GetInt64("chunk-size")
+
+	nodes, err := kube.ListNodes(ctx, clientset, metav1.ListOptions{LabelSelector: labelSelector.String()}, chunkSize)
+	if err != nil {
+		return errors.Wrap(err, "failed to list nodes")
+	}
+
+	podListOptions, err := withFieldSelector(cmd, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+
+	pods, err := kube.ListPods(ctx, clientset, "", podListOptions, chunkSize)
+	if err != nil {
+		return errors.Wrap(err, "failed to list pods")
+	}
+	pods.Items = namespacePods(cmd, pods.Items)
+
+	nodeRoleCapacityData, roleNames := collectNodeRoleData(cmd, nodes.Items, pods.Items, groups)
+
+	displayOptions := getDisplayOptions(cmd)
+	displayOptions.Schedulable, _ = cmd.Flags().GetBool("schedulable")
+
+	return writeOutput(cmd, func(out io.Writer) {
+		output.DisplayNodeRoleData(out, nodeRoleCapacityData, roleNames, displayOptions)
+		if displayOptions.Explain {
+			output.DisplayExplain(out, displayOptions)
 		}
+	})
+}
 
-		nodeRoleCapacityData := make(map[string]*output.ClusterCapacityData)
-		nodeRoles := make(map[string][]string)
-		roleNames := make([]string, 0)
-
-		for _, node := range nodes.Items {
-			roles := sets.NewString()
-			for labelKey, labelValue := range node.Labels {
-				switch {
-				case strings.HasPrefix(labelKey, "node-role.kubernetes.io/"):
-					if role := strings.TrimPrefix(labelKey, "node-role.kubernetes.io/"); len(role) > 0 {
-						roles.Insert(role)
-					}
-				case labelKey == "kubernetes.io/role" && labelValue != "":
-					roles.Insert(labelValue)
+// collectNodeRoleData sums the capacity of nodes and the requests of pods by node role, or by the node groups when
+// groups are defined. The returned names are in display order including any *total* and *unassigned* rows.
+func collectNodeRoleData(cmd *cobra.Command, nodes []corev1.Node, pods []corev1.Pod, groups []nodeGroup) (map[string]*output.ClusterCapacityData, []string) {
+	groupNames := make([]string, 0, len(groups))
+	for _, group := range groups {
+		groupNames = append(groupNames, group.Name)
+	}
+	nodeRoles := func(node corev1.Node) sets.String {
+		roles := sets.NewString()
+		if len(groups) > 0 {
+			for _, group := range groups {
+				if group.selector.Matches(labels.Set(node.Labels)) {
+					roles.Insert(group.Name)
 				}
 			}
-			if len(roles) == 0 {
-				roles.Insert("<none>")
+			if len(roles) > 1 {
+				fmt.Fprintf(os.Stderr, "warning: node %s matches multiple groups (%s) and is counted in each\n", node.Name, strings.Join(roles.List(), ","))
 			}
-			for role := range roles {
-				if !capacity.StringInSlice(role, roleNames) {
-					roleNames = append(roleNames, role)
-					nodeRoleCapacityData[role] = new(output.ClusterCapacityData)
+			return roles
+		}
+		for labelKey, labelValue := range node.Labels {
+			switch {
+			case strings.HasPrefix(labelKey, "node-role.kubernetes.io/"):
+				if role := strings.TrimPrefix(labelKey, "node-role.kubernetes.io/"); len(role) > 0 {
+					roles.Insert(role)
 				}
-				nodeRoleCapacityData[role].TotalNodeCount++
-				for _, condition := range node.Status.Conditions {
-					if (condition.Type == "Ready") && condition.Status == corev1.ConditionTrue {
-						nodeRoleCapacityData[role].TotalReadyNodeCount++
-					}
+			case labelKey == "kubernetes.io/role" && labelValue != "":
+				roles.Insert(labelValue)
+			}
+		}
+		return roles
+	}
+	return collectNodeGroupData(cmd, nodes, pods, groupNames, nodeRoles)
+}
+
+// collectNodeGroupData sums the capacity of nodes and the requests of pods by the groups nodeGroups returns for each
+// node, nodes without a group are summed under <none>. groupNames are displayed first in the given order, otherwise
+// the groups are sorted by name. The returned names are in display order including any *total* and *unassigned* rows.
+func collectNodeGroupData(cmd *cobra.Command, nodes []corev1.Node, pods []corev1.Pod, groupNames []string, nodeGroups func(corev1.Node) sets.String) (map[string]*output.ClusterCapacityData, []string) {
+	gpuResourceName, _ := cmd.Flags().GetString("gpu-resource-name")
+	gpuResource := corev1.ResourceName(gpuResourceName)
+	extraResources := getExtraResources(cmd)
+	excludeDaemonSets, _ := cmd.Flags().GetBool("exclude-daemonsets")
+	excludeDaemonSetCounts, _ := cmd.Flags().GetBool("exclude-daemonsets-from-counts")
+	displayTotal, _ := cmd.Flags().GetBool("display-total")
+	dedupTotal, _ := cmd.Flags().GetBool("dedup-total")
+	excludeUnready, _ := cmd.Flags().GetBool("exclude-unready")
+
+	nodeRoleCapacityData := make(map[string]*output.ClusterCapacityData)
+	nodeRoles := make(map[string][]string)
+	roleNames := make([]string, 0)
+	// Unready nodes excluded by --exclude-unready and the non-term pod count on them by group
+	unreadyNodes := sets.NewString()
+	excludedNonTermPodCount := make(map[string]int)
+
+	for _, groupName := range groupNames {
+		roleNames = append(roleNames, groupName)
+		nodeRoleCapacityData[groupName] = new(output.ClusterCapacityData)
+	}
+	if displayTotal {
+		nodeRoleCapacityData["*total*"] = new(output.ClusterCapacityData)
+	}
+
+	for _, node := range nodes {
+		roles := nodeGroups(node)
+		if len(roles) == 0 {
+			roles.Insert("<none>")
+		}
+		nodeGroups := roles.List()
+		if displayTotal {
+			// *total* is the sum of the roles, a node with multiple roles is counted once per role unless --dedup-total
+			if dedupTotal {
+				nodeGroups = append(nodeGroups, "*total*")
+			} else {
+				for range roles {
+					nodeGroups = append(nodeGroups, "*total*")
 				}
-				if node.Spec.Unschedulable {
-					nodeRoleCapacityData[role].TotalUnschedulableNodeCount++
+			}
+		}
+		for _, role := range nodeGroups {
+			if _, ok := nodeRoleCapacityData[role]; !ok {
+				roleNames = append(roleNames, role)
+				nodeRoleCapacityData[role] = new(output.ClusterCapacityData)
+			}
+			nodeRoleCapacityData[role].TotalNodeCount++
+			ready := capacity.IsNodeReady(node)
+			if ready {
+				nodeRoleCapacityData[role].TotalReadyNodeCount++
+			}
+			if node.Spec.Unschedulable {
+				nodeRoleCapacityData[role].TotalUnschedulableNodeCount++
+			}
+			if excludeUnready && !ready {
+				unreadyNodes.Insert(node.Name)
+				if capacity.IsSchedulable(node) {
+					nodeRoleCapacityData[role].SchedulableNodeCount++
 				}
-				nodeRoleCapacityData[role].TotalCapacityPods.Add(*node.Status.Capacity.Pods())
-				nodeRoleCapacityData[role].TotalCapacityCPU.Add(*node.Status.Capacity.Cpu())
-				nodeRoleCapacityData[role].TotalCapacityMemory.Add(*node.Status.Capacity.Memory())
-				nodeRoleCapacityData[role].TotalCapacityEphemeralStorage.Add(*node.Status.Capacity.StorageEphemeral())
-				nodeRoleCapacityData[role].TotalAllocatablePods.Add(*node.Status.Allocatable.Pods())
-				nodeRoleCapacityData[role].TotalAllocatableCPU.Add(*node.Status.Allocatable.Cpu())
-				nodeRoleCapacityData[role].TotalAllocatableMemory.Add(*node.Status.Allocatable.Memory())
-				nodeRoleCapacityData[role].TotalAllocatableEphemeralStorage.Add(*node.Status.Allocatable.StorageEphemeral())
+				continue
+			}
+			nodeRoleCapacityData[role].TotalCapacityPods.Add(*node.Status.Capacity.Pods())
+			nodeRoleCapacityData[role].TotalCapacityCPU.Add(*node.Status.Capacity.Cpu())
+			nodeRoleCapacityData[role].TotalCapacityMemory.Add(*node.Status.Capacity.Memory())
+			nodeRoleCapacityData[role].TotalCapacityEphemeralStorage.Add(*node.Status.Capacity.StorageEphemeral())
+			nodeRoleCapacityData[role].TotalAllocatablePods.Add(*node.Status.Allocatable.Pods())
+			nodeRoleCapacityData[role].TotalAllocatableCPU.Add(*node.Status.Allocatable.Cpu())
+			nodeRoleCapacityData[role].TotalAllocatableMemory.Add(*node.Status.Allocatable.Memory())
+			nodeRoleCapacityData[role].TotalAllocatableEphemeralStorage.Add(*node.Status.Allocatable.StorageEphemeral())
+			nodeRoleCapacityData[role].TotalCapacityGPU.Add(node.Status.Capacity[gpuResource])
+			nodeRoleCapacityData[role].TotalAllocatableGPU.Add(node.Status.Allocatable[gpuResource])
+			nodeRoleCapacityData[role].Hugepages = addHugepages(nodeRoleCapacityData[role].Hugepages, node.Status.Capacity, hugepagesCapacity)
+			nodeRoleCapacityData[role].Hugepages = addHugepages(nodeRoleCapacityData[role].Hugepages, node.Status.Allocatable, hugepagesAllocatable)
+			nodeRoleCapacityData[role].ExtraResources = addExtraResources(nodeRoleCapacityData[role].ExtraResources, extraResources, node.Status.Capacity, extraResourcesCapacity)
+			nodeRoleCapacityData[role].ExtraResources = addExtraResources(nodeRoleCapacityData[role].ExtraResources, extraResources, node.Status.Allocatable, extraResourcesAllocatable)
+			nodeRoleCapacityData[role].TotalReservedCPU.Add(capacity.Reserved(node, corev1.ResourceCPU))
+			nodeRoleCapacityData[role].TotalReservedMemory.Add(capacity.Reserved(node, corev1.ResourceMemory))
+			nodeRoleCapacityData[role].TotalReservedEphemeralStorage.Add(capacity.Reserved(node, corev1.ResourceEphemeralStorage))
+			if capacity.IsSchedulable(node) {
+				nodeRoleCapacityData[role].SchedulableNodeCount++
+				nodeRoleCapacityData[role].SchedulableAllocatableCPU.Add(*node.Status.Allocatable.Cpu())
+				nodeRoleCapacityData[role].SchedulableAllocatableMemory.Add(*node.Status.Allocatable.Memory())
 			}
-			nodeRoles[node.Name] = roles.List()
 		}
+		nodeRoles[node.Name] = nodeGroups
+	}
 
-		nodeRoleCapacityData["*unassigned*"] = new(output.ClusterCapacityData)
-		nodeRoles["*unassigned*"] = []string{"*unassigned*"}
+	nodeRoleCapacityData["*unassigned*"] = new(output.ClusterCapacityData)
+	nodeRoles["*unassigned*"] = []string{"*unassigned*"}
 
-		for _, pod := range pods.Items {
-			podNode := pod.Spec.NodeName
-			if pod.Spec.NodeName == "" {
-				podNode = "*unassigned*"
-			}
-			for _, role := range nodeRoles[podNode] {
+	for _, pod := range pods {
+		podNode := pod.Spec.NodeName
+		if pod.Spec.NodeName == "" {
+			podNode = "*unassigned*"
+		}
+		isDaemonSetPod := capacity.IsDaemonSetPod(pod)
+		for _, role := range nodeRoles[podNode] {
+			if !(excludeDaemonSetCounts && isDaemonSetPod) {
 				nodeRoleCapacityData[role].TotalPodCount++
-				if (pod.Status.Phase != corev1.PodSucceeded) && (pod.Status.Phase != corev1.PodFailed) {
+				countPodPhase(pod.Status.Phase, &nodeRoleCapacityData[role].RunningPodCount, &nodeRoleCapacityData[role].PendingPodCount, &nodeRoleCapacityData[role].SucceededPodCount, &nodeRoleCapacityData[role].FailedPodCount)
+			}
+			if (pod.Status.Phase != corev1.PodSucceeded) && (pod.Status.Phase != corev1.PodFailed) {
+				qosClass := capacity.PodQOSClass(pod)
+				if !(excludeDaemonSetCounts && isDaemonSetPod) {
 					nodeRoleCapacityData[role].TotalNonTermPodCount++
-					for _, container := range pod.Spec.Containers {
-						nodeRoleCapacityData[role].TotalRequestsCPU.Add(*container.Resources.Requests.Cpu())
-						nodeRoleCapacityData[role].TotalLimitsCPU.Add(*container.Resources.Limits.Cpu())
-						nodeRoleCapacityData[role].TotalRequestsMemory.Add(*container.Resources.Requests.Memory())
-						nodeRoleCapacityData[role].TotalLimitsMemory.Add(*container.Resources.Limits.Memory())
-						nodeRoleCapacityData[role].TotalRequestsEphemeralStorage.Add(*container.Resources.Requests.StorageEphemeral())
-						nodeRoleCapacityData[role].TotalLimitsEphemeralStorage.Add(*container.Resources.Limits.StorageEphemeral())
+					// QoS counts cover the same pods as the QoS requests, pods on excluded nodes are left out of both
+					if unreadyNodes.Has(podNode) {
+						excludedNonTermPodCount[role]++
+					} else {
+						countPodQOS(nodeRoleCapacityData[role], qosClass)
 					}
 				}
+				if (excludeDaemonSets && isDaemonSetPod) || unreadyNodes.Has(podNode) {
+					continue
+				}
+				podRequests, podLimits := capacity.PodRequestsAndLimits(pod)
+				nodeRoleCapacityData[role].TotalRequestsCPU.Add(*podRequests.Cpu())
+				addQOSRequests(nodeRoleCapacityData[role], qosClass, podRequests)
+				nodeRoleCapacityData[role].TotalLimitsCPU.Add(*podLimits.Cpu())
+				nodeRoleCapacityData[role].TotalRequestsMemory.Add(*podRequests.Memory())
+				nodeRoleCapacityData[role].TotalLimitsMemory.Add(*podLimits.Memory())
+				nodeRoleCapacityData[role].TotalRequestsEphemeralStorage.Add(*podRequests.StorageEphemeral())
+				nodeRoleCapacityData[role].TotalLimitsEphemeralStorage.Add(*podLimits.StorageEphemeral())
+				nodeRoleCapacityData[role].TotalRequestsGPU.Add(podRequests[gpuResource])
+				nodeRoleCapacityData[role].Hugepages = addHugepages(nodeRoleCapacityData[role].Hugepages, podRequests, hugepagesRequests)
+				nodeRoleCapacityData[role].ExtraResources = addExtraResources(nodeRoleCapacityData[role].ExtraResources, extraResources, podRequests, extraResourcesRequests)
 			}
 		}
+	}
 
-		for _, role := range roleNames {
-			nodeRoleCapacityData[role].TotalUnreadyNodeCount = nodeRoleCapacityData[role].TotalNodeCount - nodeRoleCapacityData[role].TotalReadyNodeCount
-			nodeRoleCapacityData[role].TotalAvailablePods = int(nodeRoleCapacityData[role].TotalAllocatablePods.Value()) - nodeRoleCapacityData[role].TotalNonTermPodCount
-			nodeRoleCapacityData[role].TotalAvailableCPU = nodeRoleCapacityData[role].TotalAllocatableCPU
-			nodeRoleCapacityData[role].TotalAvailableCPU.Sub(nodeRoleCapacityData[role].TotalRequestsCPU)
-			nodeRoleCapacityData[role].TotalAvailableMemory = nodeRoleCapacityData[role].TotalAllocatableMemory
-			nodeRoleCapacityData[role].TotalAvailableMemory.Sub(nodeRoleCapacityData[role].TotalRequestsMemory)
-			nodeRoleCapacityData[role].TotalAvailableEphemeralStorage = nodeRoleCapacityData[role].TotalAllocatableEphemeralStorage
-			nodeRoleCapacityData[role].TotalAvailableEphemeralStorage.Sub(nodeRoleCapacityData[role].TotalRequestsEphemeralStorage)
-		}
-
-		displayDefault, _ := cmd.Flags().GetBool("default-format")
-
-		displayEphemeralStorage, _ := cmd.Flags().GetBool("ephemeral-storage")
-
-		displayNoHeaders, _ := cmd.Flags().GetBool("no-headers")
-
-		displayFormat, _ := cmd.Flags().GetString("output")
-
+	if len(groupNames) == 0 {
 		sort.Strings(roleNames)
-		if displayUnassigned, _ := cmd.Flags().GetBool("unassigned"); displayUnassigned {
-			roleNames = append(roleNames, "*unassigned*")
-		}
+	}
+	reverseNames(cmd, roleNames)
+	if displayTotal {
+		roleNames = append(roleNames, "*total*")
+	}
 
-		// Populate "Human" readable capacity data values
-		for _, role := range roleNames {
-			nodeRoleCapacityData[role].TotalCapacityCPUCores = capacity.ReadableCPU(nodeRoleCapacityData[role].TotalCapacityCPU)
-			nodeRoleCapacityData[role].TotalCapacityMemoryGiB = capacity.ReadableMem(nodeRoleCapacityData[role].TotalCapacityMemory)
-			nodeRoleCapacityData[role].TotalCapacityEphemeralStorageGB = capacity.ReadableStorage(nodeRoleCapacityData[role].TotalCapacityEphemeralStorage)
-			nodeRoleCapacityData[role].TotalAllocatableCPUCores = capacity.ReadableCPU(nodeRoleCapacityData[role].TotalAllocatableCPU)
-			nodeRoleCapacityData[role].TotalAllocatableMemoryGiB = capacity.ReadableMem(nodeRoleCapacityData[role].TotalAllocatableMemory)
-			nodeRoleCapacityData[role].TotalAllocatableEphemeralStorageGB = capacity.ReadableStorage(nodeRoleCapacityData[role].TotalAllocatableEphemeralStorage)
-			nodeRoleCapacityData[role].TotalRequestsCPUCores = capacity.ReadableCPU(nodeRoleCapacityData[role].TotalRequestsCPU)
-			nodeRoleCapacityData[role].TotalLimitsCPUCores = capacity.ReadableCPU(nodeRoleCapacityData[role].TotalLimitsCPU)
-			nodeRoleCapacityData[role].TotalAvailableCPUCores = capacity.ReadableCPU(nodeRoleCapacityData[role].TotalAvailableCPU)
-			nodeRoleCapacityData[role].TotalRequestsMemoryGiB = capacity.ReadableMem(nodeRoleCapacityData[role].TotalRequestsMemory)
-			nodeRoleCapacityData[role].TotalLimitsMemoryGiB = capacity.ReadableMem(nodeRoleCapacityData[role].TotalLimitsMemory)
-			nodeRoleCapacityData[role].TotalAvailableMemoryGiB = capacity.ReadableMem(nodeRoleCapacityData[role].TotalAvailableMemory)
-			nodeRoleCapacityData[role].TotalRequestsEphemeralStorageGB = capacity.ReadableStorage(nodeRoleCapacityData[role].TotalRequestsEphemeralStorage)
-			nodeRoleCapacityData[role].TotalLimitsEphemeralStorageGB = capacity.ReadableStorage(nodeRoleCapacityData[role].TotalLimitsEphemeralStorage)
-			nodeRoleCapacityData[role].TotalAvailableEphemeralStorageGB = capacity.ReadableStorage(nodeRoleCapacityData[role].TotalAvailableEphemeralStorage)
-		}
+	for _, role := range roleNames {
+		nodeRoleCapacityData[role].TotalUnreadyNodeCount = nodeRoleCapacityData[role].TotalNodeCount - nodeRoleCapacityData[role].TotalReadyNodeCount
+		nodeRoleCapacityData[role].TotalAvailablePods = int(nodeRoleCapacityData[role].TotalAllocatablePods.Value()) - nodeRoleCapacityData[role].TotalNonTermPodCount + excludedNonTermPodCount[role]
+		nodeRoleCapacityData[role].TotalAvailableCPU = nodeRoleCapacityData[role].TotalAllocatableCPU
+		nodeRoleCapacityData[role].TotalAvailableCPU.Sub(nodeRoleCapacityData[role].TotalRequestsCPU)
+		nodeRoleCapacityData[role].TotalAvailableMemory = nodeRoleCapacityData[role].TotalAllocatableMemory
+		nodeRoleCapacityData[role].TotalAvailableMemory.Sub(nodeRoleCapacityData[role].TotalRequestsMemory)
+		nodeRoleCapacityData[role].TotalAvailableEphemeralStorage = nodeRoleCapacityData[role].TotalAllocatableEphemeralStorage
+		nodeRoleCapacityData[role].TotalAvailableEphemeralStorage.Sub(nodeRoleCapacityData[role].TotalRequestsEphemeralStorage)
+		nodeRoleCapacityData[role].TotalAvailableGPU = nodeRoleCapacityData[role].TotalAllocatableGPU
+		nodeRoleCapacityData[role].TotalAvailableGPU.Sub(nodeRoleCapacityData[role].TotalRequestsGPU)
+		nodeRoleCapacityData[role].TotalLimitsAvailableCPU = nodeRoleCapacityData[role].TotalAllocatableCPU.DeepCopy()
+		nodeRoleCapacityData[role].TotalLimitsAvailableCPU.Sub(nodeRoleCapacityData[role].TotalLimitsCPU)
+		nodeRoleCapacityData[role].TotalLimitsAvailableMemory = nodeRoleCapacityData[role].TotalAllocatableMemory.DeepCopy()
+		nodeRoleCapacityData[role].TotalLimitsAvailableMemory.Sub(nodeRoleCapacityData[role].TotalLimitsMemory)
+		clampAvailable(cmd, &nodeRoleCapacityData[role].TotalAvailablePods, &nodeRoleCapacityData[role].TotalAvailableCPU, &nodeRoleCapacityData[role].TotalAvailableMemory,
+			&nodeRoleCapacityData[role].TotalAvailableEphemeralStorage, &nodeRoleCapacityData[role].TotalAvailableGPU, &nodeRoleCapacityData[role].TotalLimitsAvailableCPU,
+			&nodeRoleCapacityData[role].TotalLimitsAvailableMemory)
+		setHugepagesAvailable(cmd, nodeRoleCapacityData[role].Hugepages)
+	}
 
-		output.DisplayNodeRoleData(nodeRoleCapacityData, roleNames, displayDefault, !displayNoHeaders, displayEphemeralStorage, displayFormat)
+	hideEmptyUnassigned, _ := cmd.Flags().GetBool("hide-empty-unassigned")
+	if displayUnassigned, _ := cmd.Flags().GetBool("unassigned"); displayUnassigned && !(hideEmptyUnassigned && nodeRoleCapacityData["*unassigned*"].TotalPodCount == 0) {
+		roleNames = append(roleNames, "*unassigned*")
+	}
 
-		return nil
-	},
+	// Populate "Human" readable capacity data values
+	for _, role := range roleNames {
+		nodeRoleCapacityData[role].TotalCapacityCPUCores = capacity.ReadableCPU(nodeRoleCapacityData[role].TotalCapacityCPU)
+		nodeRoleCapacityData[role].TotalCapacityMemoryGiB = capacity.ReadableMem(nodeRoleCapacityData[role].TotalCapacityMemory)
+		nodeRoleCapacityData[role].TotalCapacityEphemeralStorageGB = capacity.ReadableStorage(nodeRoleCapacityData[role].TotalCapacityEphemeralStorage)
+		nodeRoleCapacityData[role].TotalAllocatableCPUCores = capacity.ReadableCPU(nodeRoleCapacityData[role].TotalAllocatableCPU)
+		nodeRoleCapacityData[role].TotalAllocatableMemoryGiB = capacity.ReadableMem(nodeRoleCapacityData[role].TotalAllocatableMemory)
+		nodeRoleCapacityData[role].TotalAllocatableEphemeralStorageGB = capacity.ReadableStorage(nodeRoleCapacityData[role].TotalAllocatableEphemeralStorage)
+		nodeRoleCapacityData[role].TotalRequestsCPUCores = capacity.ReadableCPU(nodeRoleCapacityData[role].TotalRequestsCPU)
+		nodeRoleCapacityData[role].TotalLimitsCPUCores = capacity.ReadableCPU(nodeRoleCapacityData[role].TotalLimitsCPU)
+		nodeRoleCapacityData[role].TotalAvailableCPUCores = capacity.ReadableCPU(nodeRoleCapacityData[role].TotalAvailableCPU)
+		nodeRoleCapacityData[role].TotalRequestsMemoryGiB = capacity.ReadableMem(nodeRoleCapacityData[role].TotalRequestsMemory)
+		nodeRoleCapacityData[role].TotalLimitsMemoryGiB = capacity.ReadableMem(nodeRoleCapacityData[role].TotalLimitsMemory)
+		nodeRoleCapacityData[role].TotalAvailableMemoryGiB = capacity.ReadableMem(nodeRoleCapacityData[role].TotalAvailableMemory)
+		nodeRoleCapacityData[role].TotalRequestsEphemeralStorageGB = capacity.ReadableStorage(nodeRoleCapacityData[role].TotalRequestsEphemeralStorage)
+		nodeRoleCapacityData[role].TotalLimitsEphemeralStorageGB = capacity.ReadableStorage(nodeRoleCapacityData[role].TotalLimitsEphemeralStorage)
+		nodeRoleCapacityData[role].TotalAvailableEphemeralStorageGB = capacity.ReadableStorage(nodeRoleCapacityData[role].TotalAvailableEphemeralStorage)
+		nodeRoleCapacityData[role].TotalReservedCPUCores = capacity.ReadableCPU(nodeRoleCapacityData[role].TotalReservedCPU)
+		nodeRoleCapacityData[role].TotalReservedMemoryGiB = capacity.ReadableMem(nodeRoleCapacityData[role].TotalReservedMemory)
+		nodeRoleCapacityData[role].TotalReservedEphemeralStorageGB = capacity.ReadableStorage(nodeRoleCapacityData[role].TotalReservedEphemeralStorage)
+		nodeRoleCapacityData[role].TotalLimitsAvailableCPUCores = capacity.ReadableCPU(nodeRoleCapacityData[role].TotalLimitsAvailableCPU)
+		nodeRoleCapacityData[role].TotalLimitsAvailableMemoryGiB = capacity.ReadableMem(nodeRoleCapacityData[role].TotalLimitsAvailableMemory)
+		nodeRoleCapacityData[role].TotalCapacityGPUCount = int(nodeRoleCapacityData[role].TotalCapacityGPU.Value())
+		nodeRoleCapacityData[role].TotalAllocatableGPUCount = int(nodeRoleCapacityData[role].TotalAllocatableGPU.Value())
+		nodeRoleCapacityData[role].TotalRequestsGPUCount = int(nodeRoleCapacityData[role].TotalRequestsGPU.Value())
+		nodeRoleCapacityData[role].TotalAvailableGPUCount = int(nodeRoleCapacityData[role].TotalAvailableGPU.Value())
+		setHugepagesReadable(nodeRoleCapacityData[role].Hugepages)
+		nodeRoleCapacityData[role].RequestsCPUUtilization = capacity.Utilization(nodeRoleCapacityData[role].TotalRequestsCPU, nodeRoleCapacityData[role].TotalAllocatableCPU)
+		nodeRoleCapacityData[role].RequestsMemoryUtilization = capacity.Utilization(nodeRoleCapacityData[role].TotalRequestsMemory, nodeRoleCapacityData[role].TotalAllocatableMemory)
+		nodeRoleCapacityData[role].PodUtilization = capacity.Utilization(*resource.NewQuantity(int64(nodeRoleCapacityData[role].TotalNonTermPodCount), resource.DecimalSI), nodeRoleCapacityData[role].TotalAllocatablePods)
+		setOvercommit(nodeRoleCapacityData[role])
+		setCapacityPct(nodeRoleCapacityData[role])
+		setQOSReadable(nodeRoleCapacityData[role])
+		nodeRoleCapacityData[role].SchedulableAllocatableCPUCores = capacity.ReadableCPU(nodeRoleCapacityData[role].SchedulableAllocatableCPU)
+		nodeRoleCapacityData[role].SchedulableAllocatableMemoryGiB = capacity.ReadableMem(nodeRoleCapacityData[role].SchedulableAllocatableMemory)
+	}
+
+	return nodeRoleCapacityData, roleNames
 }
 
 func init() {
 	rootCmd.AddCommand(nodeRoleCmd)
-	nodeRoleCmd.Flags().BoolP("ephemeral-storage", "e", false, "Include ephemeral storage capacity data in table output")
+	addCapacityFlags(nodeRoleCmd)
 	nodeRoleCmd.Flags().BoolP("unassigned", "u", false, "Include unassigned pod row, pods which do not have a node")
+	nodeRoleCmd.Flags().BoolP("display-total", "t", false, "Display sum of all node-role capacity data in table output, nodes with multiple roles are counted once per role")
+	nodeRoleCmd.Flags().BoolP("dedup-total", "", false, "Count each node once in the --display-total row regardless of how many roles it has")
+	nodeRoleCmd.Flags().BoolP("schedulable", "", false, "Include schedulable node count and allocatable capacity, excluding cordoned and control-plane tainted nodes, in table output")
+	nodeRoleCmd.Flags().BoolP("exclude-unready", "", false, "Exclude the capacity and allocatable of nodes which are not Ready, along with the requests of their pods, node counts are unaffected")
+	nodeRoleCmd.Flags().BoolP("qos", "", false, "Include Guaranteed, Burstable and BestEffort non-terminated pod counts and their cpu and memory requests in table output")
+	nodeRoleCmd.Flags().BoolP("hide-empty-unassigned", "", false, "Omit the unassigned pod row when there are no unassigned pods")
+	nodeRoleCmd.Flags().StringP("label-selector", "l", "", "Only include nodes matching this label selector (Ex topology.kubernetes.io/zone=us-east-1a)")
+	nodeRoleCmd.Flags().StringP("groups-file", "", "", "Group nodes by the label selectors defined in a yaml file instead of node-role")
 }
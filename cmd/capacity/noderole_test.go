@@ -0,0 +1,284 @@
+/*
+Copyright © 2021 Alex Krzos akrzos@redhat.com
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package capacity
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/akrzos/kubeSize/internal/output"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// setFlags sets command flags for a test and restores their defaults once the test completes
+func setFlags(t *testing.T, cmd *cobra.Command, flags map[string]string) {
+	t.Helper()
+	for name, value := range flags {
+		flag := cmd.Flags().Lookup(name)
+		if flag == nil {
+			t.Fatalf("unknown flag --%s", name)
+		}
+		// Setting a slice flag appends once it has changed, so slices are replaced and restored to their original values
+		var err error
+		if slice, ok := flag.Value.(interface {
+			GetSlice() []string
+			Replace([]string) error
+		}); ok {
+			original := slice.GetSlice()
+			t.Cleanup(func() {
+				slice.Replace(original)
+			})
+			err = slice.Replace(strings.Split(value, ","))
+		} else {
+			t.Cleanup(func() {
+				flag.Value.Set(flag.DefValue)
+			})
+			err = flag.Value.Set(value)
+		}
+		if err != nil {
+			t.Fatalf("failed to set --%s: %v", name, err)
+		}
+	}
+}
+
+func testNode(name, role, cpu, memory, ephemeralStorage string) corev1.Node {
+	resources := corev1.ResourceList{
+		corev1.ResourcePods:             resource.MustParse("110"),
+		corev1.ResourceCPU:              resource.MustParse(cpu),
+		corev1.ResourceMemory:           resource.MustParse(memory),
+		corev1.ResourceEphemeralStorage: resource.MustParse(ephemeralStorage),
+	}
+	return corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: map[string]string{"node-role.kubernetes.io/" + role: ""},
+		},
+		Status: corev1.NodeStatus{
+			Capacity:    resources,
+			Allocatable: resources.DeepCopy(),
+			Conditions:  []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}},
+		},
+	}
+}
+
+func testPod(name, nodeName string, requests corev1.ResourceList) corev1.Pod {
+	return corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec: corev1.PodSpec{
+			NodeName:   nodeName,
+			Containers: []corev1.Container{{Name: "app", Image: "nginx", Resources: corev1.ResourceRequirements{Requests: requests}}},
+		},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+}
+
+func TestCollectNodeRoleDataEphemeralStorage(t *testing.T) {
+	nodes := []corev1.Node{
+		testNode("worker-0", "worker", "4", "16Gi", "100G"),
+		testNode("infra-0", "infra", "4", "16Gi", "100G"),
+	}
+	pods := []corev1.Pod{
+		testPod("app-0", "worker-0", corev1.ResourceList{corev1.ResourceEphemeralStorage: resource.MustParse("10G")}),
+		testPod("app-1", "worker-0", corev1.ResourceList{corev1.ResourceEphemeralStorage: resource.MustParse("5G")}),
+		testPod("router-0", "infra-0", corev1.ResourceList{corev1.ResourceEphemeralStorage: resource.MustParse("40G")}),
+	}
+
+	nodeRoleCapacityData, roleNames := collectNodeRoleData(nodeRoleCmd, nodes, pods, nil)
+
+	if len(roleNames) != 2 || roleNames[0] != "infra" || roleNames[1] != "worker" {
+		t.Fatalf("roleNames = %v, want [infra worker]", roleNames)
+	}
+	tests := []struct {
+		role        string
+		requests    string
+		available   string
+		requestsGB  float64
+		availableGB float64
+		nonTermPods int
+	}{
+		{"worker", "15G", "85G", 15, 85, 2},
+		{"infra", "40G", "60G", 40, 60, 1},
+	}
+	for _, tt := range tests {
+		data := nodeRoleCapacityData[tt.role]
+		if want := resource.MustParse(tt.requests); data.TotalRequestsEphemeralStorage.Cmp(want) != 0 {
+			t.Errorf("%s TotalRequestsEphemeralStorage = %s, want %s", tt.role, data.TotalRequestsEphemeralStorage.String(), tt.requests)
+		}
+		if want := resource.MustParse(tt.available); data.TotalAvailableEphemeralStorage.Cmp(want) != 0 {
+			t.Errorf("%s TotalAvailableEphemeralStorage = %s, want %s", tt.role, data.TotalAvailableEphemeralStorage.String(), tt.available)
+		}
+		if data.TotalRequestsEphemeralStorageGB != tt.requestsGB {
+			t.Errorf("%s TotalRequestsEphemeralStorageGB = %v, want %v", tt.role, data.TotalRequestsEphemeralStorageGB, tt.requestsGB)
+		}
+		if data.TotalAvailableEphemeralStorageGB != tt.availableGB {
+			t.Errorf("%s TotalAvailableEphemeralStorageGB = %v, want %v", tt.role, data.TotalAvailableEphemeralStorageGB, tt.availableGB)
+		}
+		if data.TotalNonTermPodCount != tt.nonTermPods {
+			t.Errorf("%s TotalNonTermPodCount = %d, want %d", tt.role, data.TotalNonTermPodCount, tt.nonTermPods)
+		}
+	}
+}
+
+func TestCollectNodeRoleDataExcludeDaemonSets(t *testing.T) {
+	nodes := []corev1.Node{testNode("worker-0", "worker", "4", "16Gi", "100G")}
+	daemonSetPod := testPod("node-exporter-0", "worker-0", corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m")})
+	daemonSetPod.OwnerReferences = []metav1.OwnerReference{{Kind: "DaemonSet", Name: "node-exporter"}}
+	pods := []corev1.Pod{
+		testPod("app-0", "worker-0", corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")}),
+		daemonSetPod,
+	}
+
+	nodeRoleCapacityData, _ := collectNodeRoleData(nodeRoleCmd, nodes, pods, nil)
+	if want := resource.MustParse("1500m"); nodeRoleCapacityData["worker"].TotalRequestsCPU.Cmp(want) != 0 {
+		t.Errorf("TotalRequestsCPU = %s, want 1500m", nodeRoleCapacityData["worker"].TotalRequestsCPU.String())
+	}
+
+	setFlags(t, nodeRoleCmd, map[string]string{"exclude-daemonsets": "true"})
+	nodeRoleCapacityData, _ = collectNodeRoleData(nodeRoleCmd, nodes, pods, nil)
+	worker := nodeRoleCapacityData["worker"]
+	if want := resource.MustParse("1"); worker.TotalRequestsCPU.Cmp(want) != 0 {
+		t.Errorf("--exclude-daemonsets TotalRequestsCPU = %s, want 1", worker.TotalRequestsCPU.String())
+	}
+	if want := resource.MustParse("3"); worker.TotalAvailableCPU.Cmp(want) != 0 {
+		t.Errorf("--exclude-daemonsets TotalAvailableCPU = %s, want 3", worker.TotalAvailableCPU.String())
+	}
+	// Only --exclude-daemonsets-from-counts removes the pod from the counts
+	if worker.TotalNonTermPodCount != 2 {
+		t.Errorf("--exclude-daemonsets TotalNonTermPodCount = %d, want 2", worker.TotalNonTermPodCount)
+	}
+}
+
+func collectNodeRoleDataWorker(t *testing.T, nodes []corev1.Node, pods []corev1.Pod) *output.ClusterCapacityData {
+	t.Helper()
+	nodeRoleCapacityData, _ := collectNodeRoleData(nodeRoleCmd, nodes, pods, nil)
+	worker, ok := nodeRoleCapacityData["worker"]
+	if !ok || worker.Hugepages["2Mi"] == nil {
+		t.Fatalf("no worker role with 2Mi hugepages in %v", nodeRoleCapacityData)
+	}
+	return worker
+}
+
+func TestCollectNodeRoleDataOvercommitted(t *testing.T) {
+	node := testNode("worker-0", "worker", "4", "16Gi", "100G")
+	node.Status.Allocatable["hugepages-2Mi"] = resource.MustParse("1Gi")
+	nodes := []corev1.Node{node}
+	pods := []corev1.Pod{testPod("app-0", "worker-0", corev1.ResourceList{
+		corev1.ResourceCPU: resource.MustParse("6"),
+		"hugepages-2Mi":    resource.MustParse("2Gi"),
+	})}
+
+	worker := collectNodeRoleDataWorker(t, nodes, pods)
+	if !worker.TotalAvailableCPU.IsZero() {
+		t.Errorf("TotalAvailableCPU = %s, want 0", worker.TotalAvailableCPU.String())
+	}
+	if available := worker.Hugepages["2Mi"].Available; !available.IsZero() {
+		t.Errorf("hugepages 2Mi Available = %s, want 0", available.String())
+	}
+
+	setFlags(t, nodeRoleCmd, map[string]string{"allow-negative": "true"})
+	worker = collectNodeRoleDataWorker(t, nodes, pods)
+	if want := resource.MustParse("-2"); worker.TotalAvailableCPU.Cmp(want) != 0 {
+		t.Errorf("--allow-negative TotalAvailableCPU = %s, want -2", worker.TotalAvailableCPU.String())
+	}
+	if want, available := resource.MustParse("-1Gi"), worker.Hugepages["2Mi"].Available; available.Cmp(want) != 0 {
+		t.Errorf("--allow-negative hugepages 2Mi Available = %s, want -1Gi", available.String())
+	}
+}
+
+func TestCollectNodeRoleDataExtraResources(t *testing.T) {
+	node := testNode("worker-0", "worker", "4", "16Gi", "100G")
+	node.Status.Capacity["example.com/fpga"] = resource.MustParse("4")
+	node.Status.Allocatable["example.com/fpga"] = resource.MustParse("3")
+	nodes := []corev1.Node{node, testNode("infra-0", "infra", "4", "16Gi", "100G")}
+	pods := []corev1.Pod{testPod("app-0", "worker-0", corev1.ResourceList{"example.com/fpga": resource.MustParse("2")})}
+
+	setFlags(t, nodeRoleCmd, map[string]string{"extra-resources": "example.com/fpga"})
+	nodeRoleCapacityData, _ := collectNodeRoleData(nodeRoleCmd, nodes, pods, nil)
+	tests := []struct {
+		role        string
+		capacity    string
+		allocatable string
+		requests    string
+	}{
+		{"worker", "4", "3", "2"},
+		{"infra", "0", "0", "0"},
+	}
+	for _, tt := range tests {
+		fpga, ok := nodeRoleCapacityData[tt.role].ExtraResources["example.com/fpga"]
+		if !ok {
+			t.Fatalf("%s has no example.com/fpga extra resource", tt.role)
+		}
+		if want := resource.MustParse(tt.capacity); fpga.Capacity.Cmp(want) != 0 {
+			t.Errorf("%s example.com/fpga Capacity = %s, want %s", tt.role, fpga.Capacity.String(), tt.capacity)
+		}
+		if want := resource.MustParse(tt.allocatable); fpga.Allocatable.Cmp(want) != 0 {
+			t.Errorf("%s example.com/fpga Allocatable = %s, want %s", tt.role, fpga.Allocatable.String(), tt.allocatable)
+		}
+		if want := resource.MustParse(tt.requests); fpga.Requests.Cmp(want) != 0 {
+			t.Errorf("%s example.com/fpga Requests = %s, want %s", tt.role, fpga.Requests.String(), tt.requests)
+		}
+	}
+}
+
+func TestCollectNodeRoleDataExcludeUnready(t *testing.T) {
+	notReady := testNode("worker-1", "worker", "4", "16Gi", "100G")
+	notReady.Status.Conditions = []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionFalse}}
+	nodes := []corev1.Node{testNode("worker-0", "worker", "4", "16Gi", "100G"), notReady}
+	pods := []corev1.Pod{
+		testPod("app-0", "worker-0", corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")}),
+		testPod("app-1", "worker-1", corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2")}),
+	}
+
+	nodeRoleCapacityData, _ := collectNodeRoleData(nodeRoleCmd, nodes, pods, nil)
+	if want := resource.MustParse("8"); nodeRoleCapacityData["worker"].TotalAllocatableCPU.Cmp(want) != 0 {
+		t.Errorf("TotalAllocatableCPU = %s, want 8", nodeRoleCapacityData["worker"].TotalAllocatableCPU.String())
+	}
+
+	setFlags(t, nodeRoleCmd, map[string]string{"exclude-unready": "true"})
+	nodeRoleCapacityData, _ = collectNodeRoleData(nodeRoleCmd, nodes, pods, nil)
+	data := nodeRoleCapacityData["worker"]
+	if data.TotalNodeCount != 2 || data.TotalReadyNodeCount != 1 || data.TotalUnreadyNodeCount != 1 {
+		t.Errorf("--exclude-unready node counts = %d total, %d ready, %d unready, want 2, 1, 1", data.TotalNodeCount, data.TotalReadyNodeCount, data.TotalUnreadyNodeCount)
+	}
+	tests := []struct {
+		name     string
+		quantity resource.Quantity
+		want     string
+	}{
+		{"TotalCapacityCPU", data.TotalCapacityCPU, "4"},
+		{"TotalAllocatableCPU", data.TotalAllocatableCPU, "4"},
+		{"TotalAllocatablePods", data.TotalAllocatablePods, "110"},
+		{"TotalRequestsCPU", data.TotalRequestsCPU, "1"},
+		{"TotalAvailableCPU", data.TotalAvailableCPU, "3"},
+	}
+	for _, tt := range tests {
+		if want := resource.MustParse(tt.want); tt.quantity.Cmp(want) != 0 {
+			t.Errorf("--exclude-unready %s = %s, want %s", tt.name, tt.quantity.String(), tt.want)
+		}
+	}
+	if data.TotalNonTermPodCount != 2 {
+		t.Errorf("--exclude-unready TotalNonTermPodCount = %d, want 2", data.TotalNonTermPodCount)
+	}
+	if data.BurstablePodCount != 1 {
+		t.Errorf("--exclude-unready BurstablePodCount = %d, want 1 matching the QoS requests", data.BurstablePodCount)
+	}
+	if data.TotalAvailablePods != 109 {
+		t.Errorf("--exclude-unready TotalAvailablePods = %d, want 109", data.TotalAvailablePods)
+	}
+}
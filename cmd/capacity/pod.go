@@ -0,0 +1,127 @@
+/*
+Copyright © 2021 Alex Krzos akrzos@redhat.com
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package capacity
+
+import (
+	"context"
+	"io"
+	"sort"
+
+	"github.com/akrzos/kubeSize/internal/capacity"
+	"github.com/akrzos/kubeSize/internal/kube"
+	"github.com/akrzos/kubeSize/internal/output"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var podCmd = &cobra.Command{
+	Use:     "pod",
+	Aliases: []string{"po"},
+	Short:   "Get pod capacity data",
+	Long:    `Get requests and limits of each pod summed across its containers`,
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		return output.ValidateOutput(*cmd)
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runWatch(cmd, runPod)
+	},
+}
+
+// runPod collects and displays pod capacity data
+func runPod(ctx context.Context, cmd *cobra.Command) error {
+	// Like kubectl get pods, the kubeconfig context namespace is used unless --namespace or --all-namespaces is set
+	namespace := ""
+	if allNamespaces, _ := cmd.Flags().GetBool("all-namespaces"); !allNamespaces {
+		contextNamespace, _, err := KubernetesConfigFlags.ToRawKubeConfigLoader().Namespace()
+		if err != nil {
+			return errors.Wrap(err, "failed to read namespace")
+		}
+		namespace = contextNamespace
+	}
+
+	clientset, err := kube.CreateClientSet(KubernetesConfigFlags)
+	if err != nil {
+		return errors.Wrap(err, "failed to create clientset")
+	}
+
+	chunkSize, _ := cmd.Flags().GetInt64("chunk-size")
+
+	podListOptions, err := withFieldSelector(cmd, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+
+	pods, err := kube.ListPods(ctx, clientset, namespace, podListOptions, chunkSize)
+	if err != nil {
+		return errors.Wrap(err, "failed to list pods")
+	}
+
+	podCapacityData := make(map[string]*output.PodCapacityData)
+	podNames := make([]string, 0, len(pods.Items))
+	podCapacityData["*total*"] = &output.PodCapacityData{Name: "*total*"}
+
+	for _, pod := range pods.Items {
+		podName := pod.Namespace + "/" + pod.Name
+		podNames = append(podNames, podName)
+		podCapacityData[podName] = &output.PodCapacityData{
+			Name:      pod.Name,
+			Namespace: pod.Namespace,
+			Node:      pod.Spec.NodeName,
+			Phase:     string(pod.Status.Phase),
+		}
+		podRequests, podLimits := capacity.PodRequestsAndLimits(pod)
+		podCapacityData[podName].TotalRequestsCPU.Add(*podRequests.Cpu())
+		podCapacityData[podName].TotalLimitsCPU.Add(*podLimits.Cpu())
+		podCapacityData[podName].TotalRequestsMemory.Add(*podRequests.Memory())
+		podCapacityData[podName].TotalLimitsMemory.Add(*podLimits.Memory())
+		// Terminated pods no longer hold their requests so are excluded from the *total* "pod"
+		if (pod.Status.Phase != corev1.PodSucceeded) && (pod.Status.Phase != corev1.PodFailed) {
+			podCapacityData["*total*"].TotalRequestsCPU.Add(*podRequests.Cpu())
+			podCapacityData["*total*"].TotalLimitsCPU.Add(*podLimits.Cpu())
+			podCapacityData["*total*"].TotalRequestsMemory.Add(*podRequests.Memory())
+			podCapacityData["*total*"].TotalLimitsMemory.Add(*podLimits.Memory())
+		}
+	}
+
+	sort.Strings(podNames)
+	reverseNames(cmd, podNames)
+
+	// Populate "Human" readable capacity data values
+	for _, pod := range append([]string{"*total*"}, podNames...) {
+		podCapacityData[pod].TotalRequestsCPUCores = capacity.ReadableCPU(podCapacityData[pod].TotalRequestsCPU)
+		podCapacityData[pod].TotalLimitsCPUCores = capacity.ReadableCPU(podCapacityData[pod].TotalLimitsCPU)
+		podCapacityData[pod].TotalRequestsMemoryGiB = capacity.ReadableMem(podCapacityData[pod].TotalRequestsMemory)
+		podCapacityData[pod].TotalLimitsMemoryGiB = capacity.ReadableMem(podCapacityData[pod].TotalLimitsMemory)
+	}
+
+	displayTotal, _ := cmd.Flags().GetBool("display-total")
+
+	if displayTotal {
+		podNames = append(podNames, "*total*")
+	}
+
+	return writeOutput(cmd, func(out io.Writer) {
+		output.DisplayPodData(out, podCapacityData, podNames, getDisplayOptions(cmd))
+	})
+}
+
+func init() {
+	rootCmd.AddCommand(podCmd)
+	podCmd.Flags().BoolP("all-namespaces", "A", false, "List pods across all namespaces")
+	podCmd.Flags().BoolP("display-total", "t", false, "Display sum of all non-terminated pod capacity data in table output")
+}
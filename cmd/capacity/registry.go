@@ -0,0 +1,200 @@
+/*
+Copyright © 2021 Alex Krzos akrzos@redhat.com
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package capacity
+
+import (
+	"context"
+	"io"
+	"sort"
+
+	"github.com/akrzos/kubeSize/internal/capacity"
+	"github.com/akrzos/kubeSize/internal/kube"
+	"github.com/akrzos/kubeSize/internal/output"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+)
+
+var registryCmd = &cobra.Command{
+	Use:     "registry",
+	Aliases: []string{"reg"},
+	Short:   "Get container requests grouped by image registry",
+	Long:    `Get non-terminated pod container requests and limits grouped by the registry host of the container image`,
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		return output.ValidateOutput(*cmd)
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runWatch(cmd, runRegistry)
+	},
+}
+
+// runRegistry collects and displays container capacity data grouped by image registry
+func runRegistry(ctx context.Context, cmd *cobra.Command) error {
+	clientset, err := kube.CreateClientSet(KubernetesConfigFlags)
+	if err != nil {
+		return errors.Wrap(err, "failed to create clientset")
+	}
+
+	chunkSize, _ := cmd.Flags().GetInt64("chunk-size")
+
+	selector := "status.phase!=" + string(corev1.PodSucceeded) + ",status.phase!=" + string(corev1.PodFailed)
+	if nsFlag, _ := cmd.Flags().GetString("namespace"); nsFlag != "" {
+		selector += ",metadata.namespace=" + nsFlag
+	}
+	fieldSelector, err := fields.ParseSelector(selector)
+	if err != nil {
+		return errors.Wrap(err, "failed to create fieldSelector")
+	}
+
+	podListOptions, err := withFieldSelector(cmd, metav1.ListOptions{FieldSelector: fieldSelector.String()})
+	if err != nil {
+		return err
+	}
+
+	nonTermPods, err := kube.ListPods(ctx, clientset, "", podListOptions, chunkSize)
+	if err != nil {
+		return errors.Wrap(err, "failed to list non-term pods")
+	}
+
+	registryCapacityData := make(map[string]*output.RegistryCapacityData)
+	registryNames := make([]string, 0)
+
+	for _, pod := range nonTermPods.Items {
+		for registry, podData := range podRegistryData(pod) {
+			if _, ok := registryCapacityData[registry]; !ok {
+				registryNames = append(registryNames, registry)
+				registryCapacityData[registry] = new(output.RegistryCapacityData)
+			}
+			registryCapacityData[registry].TotalContainerCount += podData.TotalContainerCount
+			registryCapacityData[registry].TotalRequestsCPU.Add(podData.TotalRequestsCPU)
+			registryCapacityData[registry].TotalLimitsCPU.Add(podData.TotalLimitsCPU)
+			registryCapacityData[registry].TotalRequestsMemory.Add(podData.TotalRequestsMemory)
+			registryCapacityData[registry].TotalLimitsMemory.Add(podData.TotalLimitsMemory)
+		}
+	}
+
+	registryCapacityData["*total*"] = new(output.RegistryCapacityData)
+
+	// Populate "Human" readable capacity data values and the *total* "registry"
+	for _, registry := range registryNames {
+		registryCapacityData[registry].TotalRequestsCPUCores = capacity.ReadableCPU(registryCapacityData[registry].TotalRequestsCPU)
+		registryCapacityData[registry].TotalLimitsCPUCores = capacity.ReadableCPU(registryCapacityData[registry].TotalLimitsCPU)
+		registryCapacityData[registry].TotalRequestsMemoryGiB = capacity.ReadableMem(registryCapacityData[registry].TotalRequestsMemory)
+		registryCapacityData[registry].TotalLimitsMemoryGiB = capacity.ReadableMem(registryCapacityData[registry].TotalLimitsMemory)
+		registryCapacityData["*total*"].TotalContainerCount += registryCapacityData[registry].TotalContainerCount
+		registryCapacityData["*total*"].TotalRequestsCPU.Add(registryCapacityData[registry].TotalRequestsCPU)
+		registryCapacityData["*total*"].TotalRequestsCPUCores += registryCapacityData[registry].TotalRequestsCPUCores
+		registryCapacityData["*total*"].TotalLimitsCPU.Add(registryCapacityData[registry].TotalLimitsCPU)
+		registryCapacityData["*total*"].TotalLimitsCPUCores += registryCapacityData[registry].TotalLimitsCPUCores
+		registryCapacityData["*total*"].TotalRequestsMemory.Add(registryCapacityData[registry].TotalRequestsMemory)
+		registryCapacityData["*total*"].TotalRequestsMemoryGiB += registryCapacityData[registry].TotalRequestsMemoryGiB
+		registryCapacityData["*total*"].TotalLimitsMemory.Add(registryCapacityData[registry].TotalLimitsMemory)
+		registryCapacityData["*total*"].TotalLimitsMemoryGiB += registryCapacityData[registry].TotalLimitsMemoryGiB
+	}
+
+	sort.Strings(registryNames)
+	reverseNames(cmd, registryNames)
+
+	displayTotal, _ := cmd.Flags().GetBool("display-total")
+
+	if displayTotal {
+		registryNames = append(registryNames, "*total*")
+	}
+
+	return writeOutput(cmd, func(out io.Writer) {
+		output.DisplayRegistryData(out, registryCapacityData, registryNames, getDisplayOptions(cmd))
+	})
+}
+
+// podRegistryData splits the effective requests and limits of a pod (capacity.PodRequestsAndLimits) across the registries
+// of its container images. App containers are charged their own resources. Any excess of the effective pod resources
+// over the app containers, from a larger init container or the pod overhead, is charged to the registry of the largest
+// init container when it exceeds the app containers, otherwise to the registry of the first app container.
+func podRegistryData(pod corev1.Pod) map[string]*output.RegistryCapacityData {
+	podData := make(map[string]*output.RegistryCapacityData)
+	registryData := func(image string) *output.RegistryCapacityData {
+		registry := capacity.ImageRegistry(image)
+		if _, ok := podData[registry]; !ok {
+			podData[registry] = new(output.RegistryCapacityData)
+		}
+		return podData[registry]
+	}
+
+	for _, container := range pod.Spec.Containers {
+		data := registryData(container.Image)
+		data.TotalContainerCount++
+		data.TotalRequestsCPU.Add(*container.Resources.Requests.Cpu())
+		data.TotalLimitsCPU.Add(*container.Resources.Limits.Cpu())
+		data.TotalRequestsMemory.Add(*container.Resources.Requests.Memory())
+		data.TotalLimitsMemory.Add(*container.Resources.Limits.Memory())
+	}
+	for _, container := range pod.Spec.InitContainers {
+		registryData(container.Image).TotalContainerCount++
+	}
+	if len(pod.Spec.Containers) == 0 {
+		return podData
+	}
+
+	requests, limits := capacity.PodRequestsAndLimits(pod)
+	containerRequests := func(container corev1.Container) corev1.ResourceList { return container.Resources.Requests }
+	containerLimits := func(container corev1.Container) corev1.ResourceList { return container.Resources.Limits }
+
+	if image, excess := excessResource(pod, corev1.ResourceCPU, requests, containerRequests); excess.Sign() > 0 {
+		registryData(image).TotalRequestsCPU.Add(excess)
+	}
+	if image, excess := excessResource(pod, corev1.ResourceCPU, limits, containerLimits); excess.Sign() > 0 {
+		registryData(image).TotalLimitsCPU.Add(excess)
+	}
+	if image, excess := excessResource(pod, corev1.ResourceMemory, requests, containerRequests); excess.Sign() > 0 {
+		registryData(image).TotalRequestsMemory.Add(excess)
+	}
+	if image, excess := excessResource(pod, corev1.ResourceMemory, limits, containerLimits); excess.Sign() > 0 {
+		registryData(image).TotalLimitsMemory.Add(excess)
+	}
+	return podData
+}
+
+// excessResource returns the amount the effective pod resource exceeds the sum of the app containers and the image it
+// is charged to
+func excessResource(pod corev1.Pod, name corev1.ResourceName, effective corev1.ResourceList, resources func(corev1.Container) corev1.ResourceList) (string, resource.Quantity) {
+	var appTotal resource.Quantity
+	for _, container := range pod.Spec.Containers {
+		appTotal.Add(resources(container)[name])
+	}
+	excess := effective[name]
+	excess = excess.DeepCopy()
+	excess.Sub(appTotal)
+
+	image := pod.Spec.Containers[0].Image
+	var largestInit resource.Quantity
+	for _, container := range pod.Spec.InitContainers {
+		if value := resources(container)[name]; value.Cmp(largestInit) > 0 {
+			largestInit = value.DeepCopy()
+			if value.Cmp(appTotal) > 0 {
+				image = container.Image
+			}
+		}
+	}
+	return image, excess
+}
+
+func init() {
+	rootCmd.AddCommand(registryCmd)
+	registryCmd.Flags().BoolP("display-total", "t", false, "Display sum of all registry capacity data in table output")
+}
@@ -16,11 +16,29 @@ limitations under the License.
 package capacity
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
 
+	"github.com/akrzos/kubeSize/internal/capacity"
+	"github.com/akrzos/kubeSize/internal/kube"
+	"github.com/akrzos/kubeSize/internal/output"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/kubernetes"
 )
 
 var (
@@ -33,19 +51,543 @@ var rootCmd = &cobra.Command{
 	Long:          `Exposes size and capacity data for Kubernetes clusters`,
 	SilenceErrors: true,
 	SilenceUsage:  true,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		// Command line flags override config file values, which override the built-in defaults
+		if err := loadConfig(cmd); err != nil {
+			return err
+		}
+		if color, _ := cmd.Flags().GetString("color"); color != "auto" && color != "always" && color != "never" {
+			return fmt.Errorf("Color \"%s\" is invalid. Valid values are [auto always never]", color)
+		}
+		if precision, _ := cmd.Flags().GetInt("precision"); precision < 0 {
+			return errors.New("--precision must be 0 or greater")
+		}
+		if _, err := getRequestTimeout(cmd); err != nil {
+			return err
+		}
+		if fieldSelector, _ := cmd.Flags().GetString("field-selector"); fieldSelector != "" {
+			if _, err := fields.ParseSelector(fieldSelector); err != nil {
+				return errors.Wrap(err, "invalid --field-selector")
+			}
+		}
+		inCluster, _ := cmd.Flags().GetBool("in-cluster")
+		kube.SetInCluster(inCluster)
+		retries, _ := cmd.Flags().GetInt("retries")
+		retryInterval, _ := cmd.Flags().GetDuration("retry-interval")
+		if retries < 0 || retryInterval < 0 {
+			return errors.New("--retries and --retry-interval must be 0 or greater")
+		}
+		kube.SetRetries(retries, retryInterval)
+		units, _ := cmd.Flags().GetString("units")
+		return capacity.SetUnits(units)
+	},
+}
+
+// exitError exits with code after the output is written (Ex --fail-over), the message is printed to stderr
+type exitError struct {
+	code    int
+	message string
+}
+
+func (e *exitError) Error() string {
+	return e.message
 }
 
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
-		fmt.Println(err)
+	cmd, err := rootCmd.ExecuteC()
+	var exit *exitError
+	if errors.As(err, &exit) {
+		fmt.Fprintln(os.Stderr, exit.message)
+		os.Exit(exit.code)
+	}
+	if err != nil {
+		// json and yaml consumers get a parseable error object on stdout instead of a human readable message
+		displayFormat, _ := cmd.Flags().GetString("output")
+		if displayFormat == "json" || displayFormat == "yaml" {
+			output.DisplayError(os.Stdout, err, displayFormat)
+		} else {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		}
 		os.Exit(1)
 	}
 }
 
+// getDisplayOptions reads the display flags shared by the sub-commands, command specific options are set by the caller
+func getDisplayOptions(cmd *cobra.Command) output.DisplayOptions {
+	displayDefault, _ := cmd.Flags().GetBool("default-format")
+	displayPrecision, _ := cmd.Flags().GetInt("precision")
+	displayNoHeaders, _ := cmd.Flags().GetBool("no-headers")
+	displayOutput, _ := cmd.Flags().GetString("output")
+	displayFormat, customColumns, _ := output.ParseFormat(displayOutput)
+	displayCompact, _ := cmd.Flags().GetBool("compact")
+	displayWide, _ := cmd.Flags().GetBool("wide")
+	displayEphemeralStorage, _ := cmd.Flags().GetBool("ephemeral-storage")
+	displayGPU, _ := cmd.Flags().GetBool("gpu")
+	displayReserved, _ := cmd.Flags().GetBool("reserved")
+	displayLimitsAvailable, _ := cmd.Flags().GetBool("limits-availability")
+	displayHugepages, _ := cmd.Flags().GetBool("hugepages")
+	displayUtilization, _ := cmd.Flags().GetBool("utilization")
+	displayExplain, _ := cmd.Flags().GetBool("explain")
+	displayPhases, _ := cmd.Flags().GetBool("phases")
+	displayQOS, _ := cmd.Flags().GetBool("qos")
+	warnThreshold, _ := cmd.Flags().GetFloat64("warn-threshold")
+	critThreshold, _ := cmd.Flags().GetFloat64("crit-threshold")
+	displayMetadata, _ := cmd.Flags().GetBool("metadata")
+	var metadata *output.Metadata
+	if displayMetadata && (displayFormat == "json" || displayFormat == "yaml") {
+		metadata = getMetadata(KubernetesConfigFlags)
+	}
+	return output.DisplayOptions{
+		Format:           displayFormat,
+		Default:          displayDefault,
+		Precision:        displayPrecision,
+		Headers:          !displayNoHeaders,
+		Compact:          displayCompact,
+		Wide:             displayWide,
+		EphemeralStorage: displayEphemeralStorage,
+		GPU:              displayGPU,
+		Reserved:         displayReserved,
+		LimitsAvailable:  displayLimitsAvailable,
+		Hugepages:        displayHugepages,
+		Utilization:      displayUtilization,
+		Explain:          displayExplain,
+		Phases:           displayPhases,
+		QOS:              displayQOS,
+		ExtraResources:   getExtraResources(cmd),
+		Color:            useColor(cmd, displayFormat),
+		WarnThreshold:    warnThreshold,
+		CritThreshold:    critThreshold,
+		Metadata:         metadata,
+		CustomColumns:    customColumns,
+	}
+}
+
+// useColor resolves --color, auto colors table output written to a terminal unless NO_COLOR is set
+func useColor(cmd *cobra.Command, displayFormat string) bool {
+	if displayFormat != "table" {
+		return false
+	}
+	switch color, _ := cmd.Flags().GetString("color"); color {
+	case "always":
+		return true
+	case "never":
+		return false
+	}
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	if outputFile, _ := cmd.Flags().GetString("output-file"); outputFile != "" {
+		return false
+	}
+	return term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// getMetadata returns the kubeconfig context and api server the data is collected from, values which can not be read
+// from the kubeconfig are left empty
+func getMetadata(configFlags *genericclioptions.ConfigFlags) *output.Metadata {
+	metadata := &output.Metadata{Timestamp: time.Now().UTC()}
+	clientConfig := configFlags.ToRawKubeConfigLoader()
+	if configFlags.Context != nil && *configFlags.Context != "" {
+		metadata.Context = *configFlags.Context
+	} else if rawConfig, err := clientConfig.RawConfig(); err == nil {
+		metadata.Context = rawConfig.CurrentContext
+	}
+	if restConfig, err := clientConfig.ClientConfig(); err == nil {
+		metadata.Server = restConfig.Host
+	}
+	return metadata
+}
+
+// contextConfigFlags returns a copy of KubernetesConfigFlags selecting a kubeconfig context, leaving the flags shared by
+// the other commands untouched
+func contextConfigFlags(name string) *genericclioptions.ConfigFlags {
+	configFlags := genericclioptions.NewConfigFlags(false)
+	configFlags.CacheDir = KubernetesConfigFlags.CacheDir
+	configFlags.KubeConfig = KubernetesConfigFlags.KubeConfig
+	configFlags.ClusterName = KubernetesConfigFlags.ClusterName
+	configFlags.AuthInfoName = KubernetesConfigFlags.AuthInfoName
+	configFlags.Namespace = KubernetesConfigFlags.Namespace
+	configFlags.APIServer = KubernetesConfigFlags.APIServer
+	configFlags.TLSServerName = KubernetesConfigFlags.TLSServerName
+	configFlags.Insecure = KubernetesConfigFlags.Insecure
+	configFlags.CertFile = KubernetesConfigFlags.CertFile
+	configFlags.KeyFile = KubernetesConfigFlags.KeyFile
+	configFlags.CAFile = KubernetesConfigFlags.CAFile
+	configFlags.BearerToken = KubernetesConfigFlags.BearerToken
+	configFlags.Impersonate = KubernetesConfigFlags.Impersonate
+	configFlags.ImpersonateGroup = KubernetesConfigFlags.ImpersonateGroup
+	configFlags.Username = KubernetesConfigFlags.Username
+	configFlags.Password = KubernetesConfigFlags.Password
+	configFlags.Timeout = KubernetesConfigFlags.Timeout
+	configFlags.Context = &name
+	return configFlags
+}
+
+// reverseNames reverses the sorted names in place when --reverse is set, callers apply it before appending pseudo-rows
+// (Ex *total*) so those stay at the end
+func reverseNames(cmd *cobra.Command, names []string) {
+	if reverse, _ := cmd.Flags().GetBool("reverse"); !reverse {
+		return
+	}
+	for i, j := 0, len(names)-1; i < j; i, j = i+1, j-1 {
+		names[i], names[j] = names[j], names[i]
+	}
+}
+
+// topNames keeps the first --top names, pseudo-rows (Ex *unassigned*) are always kept and do not count towards --top
+func topNames(cmd *cobra.Command, names []string) []string {
+	top, _ := cmd.Flags().GetInt("top")
+	if top <= 0 {
+		return names
+	}
+	limited := make([]string, 0, len(names))
+	count := 0
+	for _, name := range names {
+		if strings.HasPrefix(name, "*") {
+			limited = append(limited, name)
+		} else if count < top {
+			limited = append(limited, name)
+			count++
+		}
+	}
+	return limited
+}
+
+// addCapacityFlags adds the capacity flags shared by the cluster, node-role, zone and node commands
+func addCapacityFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolP("ephemeral-storage", "e", false, "Include ephemeral storage capacity data in table output")
+	cmd.Flags().BoolP("gpu", "g", false, "Include gpu capacity data in table output")
+	cmd.Flags().StringP("gpu-resource-name", "", "nvidia.com/gpu", "Extended resource name of the gpu (Ex amd.com/gpu)")
+	cmd.Flags().StringSliceP("extra-resources", "", []string{}, "Comma separated extended resource names to include in table output, one column group each (Ex example.com/fpga)")
+	cmd.Flags().BoolP("hugepages", "", false, "Include hugepages capacity data in table output, one column group per page size")
+	cmd.Flags().BoolP("limits-availability", "", false, "Include cpu and memory available against limits (allocatable - limits) in table output")
+	cmd.Flags().BoolP("reserved", "", false, "Include cpu, memory and ephemeral storage reserved by the node (capacity - allocatable) in table output")
+	cmd.Flags().BoolP("utilization", "U", false, "Include cpu, memory and pod requests as a percentage of allocatable in table output, cluster output adds a footer of requests as a percentage of capacity which includes system reserved resources")
+	cmd.Flags().BoolP("exclude-daemonsets", "", false, "Exclude DaemonSet pod requests and limits from the totals")
+	cmd.Flags().BoolP("exclude-daemonsets-from-counts", "", false, "Exclude DaemonSet pods from the pod counts")
+	cmd.Flags().BoolP("allow-negative", "", false, "Show negative available values when requests exceed allocatable instead of clamping them to zero")
+	cmd.Flags().BoolP("phases", "", false, "Include Running, Pending, Succeeded and Failed pod counts in table output")
+	cmd.Flags().BoolP("explain", "", false, "Print a footnote after table output explaining how available values are computed")
+	cmd.Flags().StringSliceP("exclude-namespaces", "", []string{}, "Comma separated namespaces whose pods are excluded from the pod counts, requests and limits, node capacity is unchanged")
+	cmd.Flags().StringSliceP("include-namespaces", "", []string{}, "Comma separated namespaces whose pods are the only ones counted, --exclude-namespaces is applied after it")
+}
+
+// setOvercommit sets the cpu and memory overcommit ratios (requests / allocatable) and whether requests exceed allocatable
+func setOvercommit(clusterCapacityData *output.ClusterCapacityData) {
+	clusterCapacityData.CPUOvercommitRatio = capacity.Ratio(clusterCapacityData.TotalRequestsCPU, clusterCapacityData.TotalAllocatableCPU)
+	clusterCapacityData.CPUOvercommitted = clusterCapacityData.TotalRequestsCPU.Cmp(clusterCapacityData.TotalAllocatableCPU) > 0
+	clusterCapacityData.MemoryOvercommitRatio = capacity.Ratio(clusterCapacityData.TotalRequestsMemory, clusterCapacityData.TotalAllocatableMemory)
+	clusterCapacityData.MemoryOvercommitted = clusterCapacityData.TotalRequestsMemory.Cmp(clusterCapacityData.TotalAllocatableMemory) > 0
+}
+
+// setCapacityPct sets cpu and memory requests as a percentage of node capacity, rather than of allocatable
+func setCapacityPct(clusterCapacityData *output.ClusterCapacityData) {
+	clusterCapacityData.RequestsCPUCapacityPct = capacity.Utilization(clusterCapacityData.TotalRequestsCPU, clusterCapacityData.TotalCapacityCPU)
+	clusterCapacityData.RequestsMemoryCapacityPct = capacity.Utilization(clusterCapacityData.TotalRequestsMemory, clusterCapacityData.TotalCapacityMemory)
+}
+
+// recentPods keeps the pods created within --since, every pod is kept when --since is 0
+func recentPods(cmd *cobra.Command, pods []corev1.Pod) []corev1.Pod {
+	since, _ := cmd.Flags().GetDuration("since")
+	if since <= 0 {
+		return pods
+	}
+	cutoff := time.Now().Add(-since)
+	recent := make([]corev1.Pod, 0, len(pods))
+	for _, pod := range pods {
+		if pod.CreationTimestamp.Time.After(cutoff) {
+			recent = append(recent, pod)
+		}
+	}
+	return recent
+}
+
+// namespacePods keeps the pods of the --include-namespaces when set, then drops the pods of the --exclude-namespaces, so a
+// namespace in both lists is excluded
+func namespacePods(cmd *cobra.Command, pods []corev1.Pod) []corev1.Pod {
+	includeNamespaces, _ := cmd.Flags().GetStringSlice("include-namespaces")
+	excludeNamespaces, _ := cmd.Flags().GetStringSlice("exclude-namespaces")
+	if len(includeNamespaces) == 0 && len(excludeNamespaces) == 0 {
+		return pods
+	}
+	included := sets.NewString(includeNamespaces...)
+	excluded := sets.NewString(excludeNamespaces...)
+	filtered := make([]corev1.Pod, 0, len(pods))
+	for _, pod := range pods {
+		if (included.Len() > 0 && !included.Has(pod.Namespace)) || excluded.Has(pod.Namespace) {
+			continue
+		}
+		filtered = append(filtered, pod)
+	}
+	return filtered
+}
+
+// countPodQOS increments the pod count of the QoS class
+func countPodQOS(clusterCapacityData *output.ClusterCapacityData, qosClass corev1.PodQOSClass) {
+	switch qosClass {
+	case corev1.PodQOSGuaranteed:
+		clusterCapacityData.GuaranteedPodCount++
+	case corev1.PodQOSBurstable:
+		clusterCapacityData.BurstablePodCount++
+	case corev1.PodQOSBestEffort:
+		clusterCapacityData.BestEffortPodCount++
+	}
+}
+
+// addQOSRequests adds the cpu and memory requests of a pod to its QoS class, BestEffort pods have no requests
+func addQOSRequests(clusterCapacityData *output.ClusterCapacityData, qosClass corev1.PodQOSClass, podRequests corev1.ResourceList) {
+	switch qosClass {
+	case corev1.PodQOSGuaranteed:
+		clusterCapacityData.GuaranteedRequestsCPU.Add(*podRequests.Cpu())
+		clusterCapacityData.GuaranteedRequestsMemory.Add(*podRequests.Memory())
+	case corev1.PodQOSBurstable:
+		clusterCapacityData.BurstableRequestsCPU.Add(*podRequests.Cpu())
+		clusterCapacityData.BurstableRequestsMemory.Add(*podRequests.Memory())
+	}
+}
+
+// setQOSReadable populates the "Human" readable QoS class requests
+func setQOSReadable(clusterCapacityData *output.ClusterCapacityData) {
+	clusterCapacityData.GuaranteedRequestsCPUCores = capacity.ReadableCPU(clusterCapacityData.GuaranteedRequestsCPU)
+	clusterCapacityData.GuaranteedRequestsMemoryGiB = capacity.ReadableMem(clusterCapacityData.GuaranteedRequestsMemory)
+	clusterCapacityData.BurstableRequestsCPUCores = capacity.ReadableCPU(clusterCapacityData.BurstableRequestsCPU)
+	clusterCapacityData.BurstableRequestsMemoryGiB = capacity.ReadableMem(clusterCapacityData.BurstableRequestsMemory)
+}
+
+// countPodPhase increments the count of the pod phase, pods in the Unknown phase are only counted in the pod totals
+func countPodPhase(phase corev1.PodPhase, running, pending, succeeded, failed *int) {
+	switch phase {
+	case corev1.PodRunning:
+		*running++
+	case corev1.PodPending:
+		*pending++
+	case corev1.PodSucceeded:
+		*succeeded++
+	case corev1.PodFailed:
+		*failed++
+	}
+}
+
+// clampAvailable zeroes available values driven negative by requests or limits exceeding allocatable (overcommitted nodes)
+// unless --allow-negative is set, pods may be nil when only quantities are clamped
+func clampAvailable(cmd *cobra.Command, pods *int, quantities ...*resource.Quantity) {
+	if allowNegative, _ := cmd.Flags().GetBool("allow-negative"); allowNegative {
+		return
+	}
+	if pods != nil && *pods < 0 {
+		*pods = 0
+	}
+	for _, quantity := range quantities {
+		if quantity.Sign() < 0 {
+			quantity.Set(0)
+		}
+	}
+}
+
+// withFieldSelector combines --field-selector with the field selector of pod list options, the built-in selectors (Ex
+// the non-terminated phase selector) are kept rather than replaced
+func withFieldSelector(cmd *cobra.Command, listOptions metav1.ListOptions) (metav1.ListOptions, error) {
+	userFieldSelector, _ := cmd.Flags().GetString("field-selector")
+	if userFieldSelector == "" {
+		return listOptions, nil
+	}
+	fieldSelector, err := fields.ParseSelector(userFieldSelector)
+	if err != nil {
+		return listOptions, errors.Wrap(err, "invalid --field-selector")
+	}
+	if listOptions.FieldSelector != "" {
+		builtInFieldSelector, err := fields.ParseSelector(listOptions.FieldSelector)
+		if err != nil {
+			return listOptions, errors.Wrap(err, "failed to create fieldSelector")
+		}
+		fieldSelector = fields.AndSelectors(builtInFieldSelector, fieldSelector)
+	}
+	listOptions.FieldSelector = fieldSelector.String()
+	return listOptions, nil
+}
+
+// writeOutput calls write with the writer for rendered output, the file named by --output-file is created or truncated
+// and closed once write returns, otherwise stdout is used
+func writeOutput(cmd *cobra.Command, write func(out io.Writer)) error {
+	outputFile, _ := cmd.Flags().GetString("output-file")
+	if outputFile == "" {
+		write(os.Stdout)
+		return nil
+	}
+	f, err := os.Create(outputFile)
+	if err != nil {
+		return errors.Wrap(err, "failed to create output file")
+	}
+	write(f)
+	if err := f.Close(); err != nil {
+		return errors.Wrap(err, "failed to close output file")
+	}
+	return nil
+}
+
+// getNodeUsage returns the cpu and memory usage of each node from the metrics API (metrics-server), when the API is
+// unavailable a warning is printed and nil is returned so commands can continue with requests only data
+func getNodeUsage(ctx context.Context, configFlags *genericclioptions.ConfigFlags) map[string]corev1.ResourceList {
+	metricsClientset, err := kube.CreateMetricsClientSet(configFlags)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: usage data unavailable: %v\n", err)
+		return nil
+	}
+	nodeMetrics, err := metricsClientset.MetricsV1beta1().NodeMetricses().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: usage data unavailable, is metrics-server installed? %v\n", err)
+		return nil
+	}
+	nodeUsage := make(map[string]corev1.ResourceList)
+	for _, nodeMetric := range nodeMetrics.Items {
+		nodeUsage[nodeMetric.Name] = nodeMetric.Usage
+	}
+	return nodeUsage
+}
+
+// getEphemeralUsage returns the ephemeral storage used by the pods on each node keyed by node name, from the kubelet
+// stats summary. When a node summary is unavailable a warning is printed and nil is returned, available ephemeral
+// storage then reflects requests only.
+func getEphemeralUsage(ctx context.Context, clientset kubernetes.Interface, nodeNames []string) map[string]resource.Quantity {
+	ephemeralUsage := make(map[string]resource.Quantity)
+	for _, nodeName := range nodeNames {
+		usage, err := kube.NodeEphemeralUsage(ctx, clientset, nodeName)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: ephemeral storage usage unavailable, available ephemeral storage reflects requests only: %v\n", err)
+			return nil
+		}
+		ephemeralUsage[nodeName] = usage
+	}
+	return ephemeralUsage
+}
+
+// getPodUsage returns the cpu and memory usage of each pod in a namespace keyed by pod name, summed across its containers,
+// from the metrics API (metrics-server). When the API is unavailable a warning is printed and nil is returned.
+func getPodUsage(ctx context.Context, configFlags *genericclioptions.ConfigFlags, namespace string) map[string]corev1.ResourceList {
+	metricsClientset, err := kube.CreateMetricsClientSet(configFlags)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: usage data unavailable: %v\n", err)
+		return nil
+	}
+	podMetrics, err := metricsClientset.MetricsV1beta1().PodMetricses(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: usage data unavailable, is metrics-server installed? %v\n", err)
+		return nil
+	}
+	podUsage := make(map[string]corev1.ResourceList)
+	for _, podMetric := range podMetrics.Items {
+		var cpu, memory resource.Quantity
+		for _, container := range podMetric.Containers {
+			cpu.Add(*container.Usage.Cpu())
+			memory.Add(*container.Usage.Memory())
+		}
+		podUsage[podMetric.Name] = corev1.ResourceList{corev1.ResourceCPU: cpu, corev1.ResourceMemory: memory}
+	}
+	return podUsage
+}
+
+// getRequestTimeout parses --request-timeout, a bare integer is a number of seconds like kubectl and 0 disables the timeout
+func getRequestTimeout(cmd *cobra.Command) (time.Duration, error) {
+	value, _ := cmd.Flags().GetString("request-timeout")
+	if seconds, err := strconv.Atoi(value); err == nil {
+		value = fmt.Sprintf("%ds", seconds)
+	}
+	timeout, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, errors.Wrap(err, "invalid --request-timeout")
+	}
+	if timeout < 0 {
+		return 0, errors.Errorf("invalid --request-timeout %q, must be 0 or greater", value)
+	}
+	return timeout, nil
+}
+
+// runWithTimeout runs a command with every request bounded by --request-timeout, a timeout of 0 leaves ctx unbounded
+func runWithTimeout(ctx context.Context, cmd *cobra.Command, run func(ctx context.Context, cmd *cobra.Command) error) error {
+	timeout, err := getRequestTimeout(cmd)
+	if err != nil {
+		return err
+	}
+	if timeout == 0 {
+		return run(ctx, cmd)
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	if err := run(ctx, cmd); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return errors.Wrapf(err, "timed out after --request-timeout %s", timeout)
+		}
+		return err
+	}
+	return nil
+}
+
+// runWatch runs a command once, or with --watch re-runs it every --interval clearing the screen between refreshes until
+// interrupted. The context passed to run is cancelled on interrupt so in flight requests return and the watch exits.
+// Each run is bounded by --request-timeout.
+func runWatch(cmd *cobra.Command, run func(ctx context.Context, cmd *cobra.Command) error) error {
+	watch, _ := cmd.Flags().GetBool("watch")
+	if !watch {
+		return runWithTimeout(context.Background(), cmd, run)
+	}
+	displayFormat, _ := cmd.Flags().GetString("output")
+	if displayFormat != "table" {
+		return errors.New("--watch is only supported with table output")
+	}
+	interval, _ := cmd.Flags().GetDuration("interval")
+	if interval <= 0 {
+		return errors.New("--interval must be greater than 0")
+	}
+	outputFile, _ := cmd.Flags().GetString("output-file")
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if outputFile == "" {
+			// Move the cursor home and clear the screen
+			fmt.Print("\033[H\033[2J")
+		}
+		if err := runWithTimeout(ctx, cmd, run); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
 func init() {
 	KubernetesConfigFlags = genericclioptions.NewConfigFlags(false)
+	// --request-timeout defaults to 30s rather than kubectl's 0 so List calls on a flaky network cannot hang indefinitely
+	requestTimeout := "30s"
+	KubernetesConfigFlags.Timeout = &requestTimeout
 	KubernetesConfigFlags.AddFlags(rootCmd.PersistentFlags())
+	rootCmd.PersistentFlags().StringP("config", "", "", "Config file of flag defaults (default $XDG_CONFIG_HOME/kubesize/config.yaml or ~/.kubesize.yaml)")
 	rootCmd.PersistentFlags().BoolP("default-format", "d", false, "Use default format of displaying resource quantities")
 	rootCmd.PersistentFlags().BoolP("no-headers", "", false, "No headers in table output format")
-	rootCmd.PersistentFlags().StringP("output", "o", "table", "Output format. One of: table|json|yaml")
+	rootCmd.PersistentFlags().IntP("precision", "", 1, "Number of decimals of cpu cores, memory and storage values in table output")
+	rootCmd.PersistentFlags().StringP("output", "o", "table", "Output format. One of: table|json|yaml|jsonl|openmetrics|markdown|custom-columns=<header>:<field>,...")
+	rootCmd.PersistentFlags().BoolP("metadata", "", false, "Wrap json and yaml output in an envelope with the context, server and collection timestamp")
+	rootCmd.PersistentFlags().BoolP("wide", "w", false, "Display cpu, memory and storage as both quantities (--default-format) and readable values side by side in cluster, node-role, zone and node table output")
+	rootCmd.PersistentFlags().BoolP("compact", "", false, "Output json without indentation")
+	rootCmd.PersistentFlags().StringP("output-file", "", "", "Write output to a file instead of stdout")
+	rootCmd.PersistentFlags().BoolP("watch", "", false, "Re-query and display data every --interval until interrupted, table output only")
+	rootCmd.PersistentFlags().DurationP("interval", "", 5*time.Second, "Refresh interval for --watch")
+	rootCmd.PersistentFlags().StringP("units", "", "", "Units of memory and storage values in table output. One of: binary|decimal (default GiB memory and GB storage)")
+	rootCmd.PersistentFlags().Int64P("chunk-size", "", 500, "Number of pods and nodes returned per api List request, 0 lists all at once")
+	rootCmd.PersistentFlags().IntP("retries", "", 0, "Number of times to retry pod and node List requests failing with a transient error (Ex server timeout, too many requests or connection refused)")
+	rootCmd.PersistentFlags().DurationP("retry-interval", "", time.Second, "Wait before the first --retries retry, doubled after each retry")
+	rootCmd.PersistentFlags().BoolP("in-cluster", "", false, "Use the in-cluster ServiceAccount config instead of a kubeconfig, used automatically when no kubeconfig is found")
+	rootCmd.PersistentFlags().StringP("color", "", "auto", "Color request utilization cells in table output by threshold. One of: auto|always|never")
+	rootCmd.PersistentFlags().Float64P("warn-threshold", "", 80, "Requests utilization percent of allocatable at or above which cells are colored yellow")
+	rootCmd.PersistentFlags().Float64P("crit-threshold", "", 90, "Requests utilization percent of allocatable at or above which cells are colored red")
+	rootCmd.PersistentFlags().StringP("field-selector", "", "", "Field selector to filter pod queries (Ex status.phase=Running), combined with the built-in non-terminated filter rather than replacing it")
+	rootCmd.PersistentFlags().BoolP("reverse", "R", false, "Reverse the sort order of table rows, *total* and *unassigned* rows stay last")
 }
@@ -0,0 +1,58 @@
+/*
+Copyright © 2021 Alex Krzos akrzos@redhat.com
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package capacity
+
+import (
+	"io"
+	"reflect"
+
+	"github.com/akrzos/kubeSize/internal/output"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// schemaTypes are the json output structures by schema type name, snapshots of the diff command share the same types
+var schemaTypes = map[string]diffSnapshotType{
+	"cluster":   diffSnapshotTypes["cluster"],
+	"node-role": diffSnapshotTypes["node-role"],
+	"zone":      diffSnapshotTypes["node-role"],
+	"node":      diffSnapshotTypes["node"],
+	"namespace": diffSnapshotTypes["namespace"],
+	"size":      {dataType: reflect.TypeOf(output.ClusterSizeData{})},
+	"jobs":      {dataType: reflect.TypeOf(output.JobsData{})},
+}
+
+var schemaCmd = &cobra.Command{
+	Use:       "schema TYPE",
+	Short:     "Print the JSON Schema of json output",
+	Long:      `Print a JSON Schema document describing the json output of the cluster, node-role, zone, node, namespace, size or jobs commands so collected snapshots can be validated. No cluster access is needed.`,
+	Args:      cobra.ExactArgs(1),
+	ValidArgs: []string{"cluster", "node-role", "zone", "node", "namespace", "size", "jobs"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		schema, ok := schemaTypes[args[0]]
+		if !ok {
+			return errors.Errorf("Type \"%s\" is invalid. Valid values are [cluster node-role zone node namespace size jobs]", args[0])
+		}
+		displayOptions := getDisplayOptions(cmd)
+		return writeOutput(cmd, func(out io.Writer) {
+			output.DisplaySchema(out, schema.dataType, schema.rows, displayOptions)
+		})
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(schemaCmd)
+}
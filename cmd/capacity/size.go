@@ -18,13 +18,23 @@ package capacity
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
+	"sync"
 
 	"github.com/akrzos/kubeSize/internal/kube"
 	"github.com/akrzos/kubeSize/internal/output"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/term"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
 )
 
 var sizeCmd = &cobra.Command{
@@ -32,206 +42,540 @@ var sizeCmd = &cobra.Command{
 	Aliases: []string{"s"},
 	Short:   "Get cluster size data",
 	Long:    `Get counts of many Kubernetes objects in a cluster`,
-	PreRun: func(cmd *cobra.Command, args []string) {
-		if err := output.ValidateOutput(*cmd); err != nil {
-			fmt.Fprintf(os.Stderr, "error: %v\n", err)
-			os.Exit(1)
-		}
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		return output.ValidateOutput(*cmd)
 	},
 	RunE: func(cmd *cobra.Command, args []string) error {
+		return runWatch(cmd, runSize)
+	},
+}
 
-		clientset, err := kube.CreateClientSet(KubernetesConfigFlags)
-		if err != nil {
-			return errors.Wrap(err, "failed to create clientset")
-		}
+// runSize collects and displays cluster size data
+func runSize(ctx context.Context, cmd *cobra.Command) error {
+	clientset, err := kube.CreateClientSet(KubernetesConfigFlags)
+	if err != nil {
+		return errors.Wrap(err, "failed to create clientset")
+	}
+
+	dynamicClient, err := kube.CreateDynamicClient(KubernetesConfigFlags)
+	if err != nil {
+		return err
+	}
+
+	chunkSize, _ := cmd.Flags().GetInt64("chunk-size")
+	strict, _ := cmd.Flags().GetBool("strict")
+
+	clusterSizeData := new(output.ClusterSizeData)
+
+	maxConcurrency, _ := cmd.Flags().GetInt("max-concurrency")
+	group, ctx := errgroup.WithContext(ctx)
+	if maxConcurrency > 0 {
+		group.SetLimit(maxConcurrency)
+	}
+	g := &progressGroup{group: group, progress: newProgress(cmd)}
 
-		clusterSizeData := new(output.ClusterSizeData)
+	// Each List runs concurrently and only sets its own ClusterSizeData field, the first error cancels the rest
 
-		// Cluster APIs
-		namespaces, err := clientset.CoreV1().Namespaces().List(context.TODO(), metav1.ListOptions{})
+	// Cluster APIs
+	g.Go("namespaces", func() error {
+		namespaces, err := clientset.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
 		if err != nil {
 			return errors.Wrap(err, "failed to list namespaces")
 		}
-		nodes, err := clientset.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{})
+		clusterSizeData.Namespace = len(namespaces.Items)
+		return nil
+	})
+	g.Go("nodes", func() error {
+		nodes, err := kube.ListNodes(ctx, clientset, metav1.ListOptions{}, chunkSize)
 		if err != nil {
 			return errors.Wrap(err, "failed to list nodes")
 		}
-		persistentVolumes, err := clientset.CoreV1().PersistentVolumes().List(context.TODO(), metav1.ListOptions{})
+		clusterSizeData.Node = len(nodes.Items)
+		return nil
+	})
+	g.Go("persistentvolumes", func() error {
+		persistentVolumes, err := clientset.CoreV1().PersistentVolumes().List(ctx, metav1.ListOptions{})
 		if err != nil {
 			return errors.Wrap(err, "failed to list persistent volumes")
 		}
-		serviceAccounts, err := clientset.CoreV1().ServiceAccounts("").List(context.TODO(), metav1.ListOptions{})
+		clusterSizeData.PersistentVolume = len(persistentVolumes.Items)
+		return nil
+	})
+	g.Go("serviceaccounts", func() error {
+		serviceAccounts, err := clientset.CoreV1().ServiceAccounts("").List(ctx, metav1.ListOptions{})
 		if err != nil {
 			return errors.Wrap(err, "failed to list service accounts")
 		}
-		clusterRoles, err := clientset.RbacV1().ClusterRoles().List(context.TODO(), metav1.ListOptions{})
+		clusterSizeData.ServiceAccount = len(serviceAccounts.Items)
+		return nil
+	})
+	g.Go("clusterroles", func() error {
+		clusterRoles, err := clientset.RbacV1().ClusterRoles().List(ctx, metav1.ListOptions{})
 		if err != nil {
 			return errors.Wrap(err, "failed to list cluster roles")
 		}
-		clusterRoleBindings, err := clientset.RbacV1().ClusterRoleBindings().List(context.TODO(), metav1.ListOptions{})
+		clusterSizeData.ClusterRole = len(clusterRoles.Items)
+		return nil
+	})
+	g.Go("clusterrolebindings", func() error {
+		clusterRoleBindings, err := clientset.RbacV1().ClusterRoleBindings().List(ctx, metav1.ListOptions{})
 		if err != nil {
 			return errors.Wrap(err, "failed to list cluster role bindings")
 		}
-		roles, err := clientset.RbacV1().Roles("").List(context.TODO(), metav1.ListOptions{})
+		clusterSizeData.ClusterRoleBinding = len(clusterRoleBindings.Items)
+		return nil
+	})
+	g.Go("roles", func() error {
+		roles, err := clientset.RbacV1().Roles("").List(ctx, metav1.ListOptions{})
 		if err != nil {
 			return errors.Wrap(err, "failed to list roles")
 		}
-		roleBindings, err := clientset.RbacV1().RoleBindings("").List(context.TODO(), metav1.ListOptions{})
+		clusterSizeData.Role = len(roles.Items)
+		return nil
+	})
+	g.Go("rolebindings", func() error {
+		roleBindings, err := clientset.RbacV1().RoleBindings("").List(ctx, metav1.ListOptions{})
 		if err != nil {
 			return errors.Wrap(err, "failed to list role bindings")
 		}
-		resourceQuotas, err := clientset.CoreV1().ResourceQuotas("").List(context.TODO(), metav1.ListOptions{})
+		clusterSizeData.RoleBinding = len(roleBindings.Items)
+		return nil
+	})
+	g.Go("resourcequotas", func() error {
+		resourceQuotas, err := clientset.CoreV1().ResourceQuotas("").List(ctx, metav1.ListOptions{})
 		if err != nil {
 			return errors.Wrap(err, "failed to list resourcequotas")
 		}
-		networkPolicy, err := clientset.NetworkingV1().NetworkPolicies("").List(context.TODO(), metav1.ListOptions{})
+		clusterSizeData.ResourceQuota = len(resourceQuotas.Items)
+		return nil
+	})
+	g.Go("networkpolicies", func() error {
+		networkPolicy, err := clientset.NetworkingV1().NetworkPolicies("").List(ctx, metav1.ListOptions{})
 		if err != nil {
 			return errors.Wrap(err, "failed to list networkpolicy")
 		}
+		clusterSizeData.NetworkPolicy = len(networkPolicy.Items)
+		return nil
+	})
 
-		// Workloads APIs
-		pods, err := clientset.CoreV1().Pods("").List(context.TODO(), metav1.ListOptions{})
+	// Workloads APIs
+	g.Go("pods", func() error {
+		pods, err := kube.ListPods(ctx, clientset, "", metav1.ListOptions{}, chunkSize)
 		if err != nil {
 			return errors.Wrap(err, "failed to list pods")
 		}
-		replicaSets, err := clientset.AppsV1().ReplicaSets("").List(context.TODO(), metav1.ListOptions{})
+		for _, pod := range pods.Items {
+			clusterSizeData.Container += len(pod.Spec.Containers)
+		}
+		clusterSizeData.Pod = len(pods.Items)
+		return nil
+	})
+	g.Go("replicasets", func() error {
+		replicaSets, err := clientset.AppsV1().ReplicaSets("").List(ctx, metav1.ListOptions{})
 		if err != nil {
 			return errors.Wrap(err, "failed to list replicasets")
 		}
-		replicationControllers, err := clientset.CoreV1().ReplicationControllers("").List(context.TODO(), metav1.ListOptions{})
+		clusterSizeData.ReplicaSet = len(replicaSets.Items)
+		return nil
+	})
+	g.Go("replicationcontrollers", func() error {
+		replicationControllers, err := clientset.CoreV1().ReplicationControllers("").List(ctx, metav1.ListOptions{})
 		if err != nil {
 			return errors.Wrap(err, "failed to list replication controllers")
 		}
-		deployments, err := clientset.AppsV1().Deployments("").List(context.TODO(), metav1.ListOptions{})
+		clusterSizeData.ReplicaController = len(replicationControllers.Items)
+		return nil
+	})
+	g.Go("deployments", func() error {
+		deployments, err := clientset.AppsV1().Deployments("").List(ctx, metav1.ListOptions{})
 		if err != nil {
 			return errors.Wrap(err, "failed to list deployments")
 		}
-		daemonsets, err := clientset.AppsV1().DaemonSets("").List(context.TODO(), metav1.ListOptions{})
+		clusterSizeData.Deployment = len(deployments.Items)
+		return nil
+	})
+	g.Go("daemonsets", func() error {
+		daemonsets, err := clientset.AppsV1().DaemonSets("").List(ctx, metav1.ListOptions{})
 		if err != nil {
 			return errors.Wrap(err, "failed to list daemonsets")
 		}
-		statefulSets, err := clientset.AppsV1().StatefulSets("").List(context.TODO(), metav1.ListOptions{})
+		clusterSizeData.Daemonset = len(daemonsets.Items)
+		return nil
+	})
+	g.Go("statefulsets", func() error {
+		statefulSets, err := clientset.AppsV1().StatefulSets("").List(ctx, metav1.ListOptions{})
 		if err != nil {
 			return errors.Wrap(err, "failed to list statefulsets")
 		}
-		cronJobs, err := clientset.BatchV1beta1().CronJobs("").List(context.TODO(), metav1.ListOptions{})
+		clusterSizeData.StatefulSet = len(statefulSets.Items)
+		return nil
+	})
+	g.Go("cronjobs", func() error {
+		version, err := servedVersion(clientset.Discovery(), "cronjobs", "batch/v1", "batch/v1beta1")
 		if err != nil {
-			return errors.Wrap(err, "failed to list jobs")
+			return errors.Wrap(err, "failed to discover the cronjobs api version")
+		}
+		switch version {
+		case "batch/v1":
+			cronJobs, err := clientset.BatchV1().CronJobs("").List(ctx, metav1.ListOptions{})
+			if err != nil {
+				return errors.Wrap(err, "failed to list cronjobs")
+			}
+			clusterSizeData.CronJob = len(cronJobs.Items)
+		case "batch/v1beta1":
+			cronJobs, err := clientset.BatchV1beta1().CronJobs("").List(ctx, metav1.ListOptions{})
+			if err != nil {
+				return errors.Wrap(err, "failed to list cronjobs")
+			}
+			clusterSizeData.CronJob = len(cronJobs.Items)
+		default:
+			return notServed("cronjobs", strict)
 		}
-		jobs, err := clientset.BatchV1().Jobs("").List(context.TODO(), metav1.ListOptions{})
+		return nil
+	})
+	g.Go("jobs", func() error {
+		jobs, err := clientset.BatchV1().Jobs("").List(ctx, metav1.ListOptions{})
 		if err != nil {
 			return errors.Wrap(err, "failed to list jobs")
 		}
+		clusterSizeData.Job = len(jobs.Items)
+		return nil
+	})
+	g.Go("horizontalpodautoscalers", func() error {
+		horizontalPodAutoscalers, err := countHorizontalPodAutoscalers(ctx, clientset)
+		if err != nil {
+			return errors.Wrap(err, "failed to list horizontalpodautoscalers")
+		}
+		clusterSizeData.HorizontalPodAutoscaler = horizontalPodAutoscalers
+		return nil
+	})
 
-		// Service APIs
-		endPoints, err := clientset.CoreV1().Endpoints("").List(context.TODO(), metav1.ListOptions{})
+	// Service APIs
+	g.Go("endpoints", func() error {
+		endPoints, err := clientset.CoreV1().Endpoints("").List(ctx, metav1.ListOptions{})
 		if err != nil {
 			return errors.Wrap(err, "failed to list end points")
 		}
-		services, err := clientset.CoreV1().Services("").List(context.TODO(), metav1.ListOptions{})
+		clusterSizeData.EndPoints = len(endPoints.Items)
+		return nil
+	})
+	g.Go("services", func() error {
+		services, err := clientset.CoreV1().Services("").List(ctx, metav1.ListOptions{})
 		if err != nil {
 			return errors.Wrap(err, "failed to list services")
 		}
-		ingresses, err := clientset.NetworkingV1().Ingresses("").List(context.TODO(), metav1.ListOptions{})
+		clusterSizeData.Service = len(services.Items)
+		return nil
+	})
+	g.Go("ingresses", func() error {
+		version, err := servedVersion(clientset.Discovery(), "ingresses", "networking.k8s.io/v1", "networking.k8s.io/v1beta1")
 		if err != nil {
-			return errors.Wrap(err, "failed to list ingresses")
+			return errors.Wrap(err, "failed to discover the ingresses api version")
 		}
+		switch version {
+		case "networking.k8s.io/v1":
+			ingresses, err := clientset.NetworkingV1().Ingresses("").List(ctx, metav1.ListOptions{})
+			if err != nil {
+				return errors.Wrap(err, "failed to list ingresses")
+			}
+			clusterSizeData.Ingress = len(ingresses.Items)
+		case "networking.k8s.io/v1beta1":
+			ingresses, err := clientset.NetworkingV1beta1().Ingresses("").List(ctx, metav1.ListOptions{})
+			if err != nil {
+				return errors.Wrap(err, "failed to list ingresses")
+			}
+			clusterSizeData.Ingress = len(ingresses.Items)
+		default:
+			return notServed("ingresses", strict)
+		}
+		return nil
+	})
 
-		// Config And Storage APIs
-		configmaps, err := clientset.CoreV1().ConfigMaps("").List(context.TODO(), metav1.ListOptions{})
+	// Config And Storage APIs
+	g.Go("configmaps", func() error {
+		configmaps, err := clientset.CoreV1().ConfigMaps("").List(ctx, metav1.ListOptions{})
 		if err != nil {
 			return errors.Wrap(err, "failed to list configmaps")
 		}
-		secrets, err := clientset.CoreV1().Secrets("").List(context.TODO(), metav1.ListOptions{})
+		clusterSizeData.Configmap = len(configmaps.Items)
+		return nil
+	})
+	g.Go("secrets", func() error {
+		secrets, err := clientset.CoreV1().Secrets("").List(ctx, metav1.ListOptions{})
 		if err != nil {
 			return errors.Wrap(err, "failed to list secrets")
 		}
-		persistentVolumeClaims, err := clientset.CoreV1().PersistentVolumeClaims("").List(context.TODO(), metav1.ListOptions{})
+		clusterSizeData.Secret = len(secrets.Items)
+		return nil
+	})
+	g.Go("persistentvolumeclaims", func() error {
+		persistentVolumeClaims, err := clientset.CoreV1().PersistentVolumeClaims("").List(ctx, metav1.ListOptions{})
 		if err != nil {
 			return errors.Wrap(err, "failed to list persistentvolumesclaims")
 		}
-		storageClasses, err := clientset.StorageV1().StorageClasses().List(context.TODO(), metav1.ListOptions{})
+		clusterSizeData.PersistentVolumeClaim = len(persistentVolumeClaims.Items)
+		return nil
+	})
+	g.Go("storageclasses", func() error {
+		storageClasses, err := clientset.StorageV1().StorageClasses().List(ctx, metav1.ListOptions{})
 		if err != nil {
 			return errors.Wrap(err, "failed to list storageclasses")
 		}
-		volumeAttachments, err := clientset.StorageV1().VolumeAttachments().List(context.TODO(), metav1.ListOptions{})
+		clusterSizeData.StorageClass = len(storageClasses.Items)
+		return nil
+	})
+	g.Go("volumeattachments", func() error {
+		volumeAttachments, err := clientset.StorageV1().VolumeAttachments().List(ctx, metav1.ListOptions{})
 		if err != nil {
 			return errors.Wrap(err, "failed to list storageclasses")
 		}
+		clusterSizeData.VolumeAttachment = len(volumeAttachments.Items)
+		return nil
+	})
 
-		// Metadata APIs
-		events, err := clientset.CoreV1().Events("").List(context.TODO(), metav1.ListOptions{})
+	// Metadata APIs
+	g.Go("events", func() error {
+		events, err := clientset.CoreV1().Events("").List(ctx, metav1.ListOptions{})
 		if err != nil {
 			return errors.Wrap(err, "failed to list events")
 		}
-		limitRanges, err := clientset.CoreV1().LimitRanges("").List(context.TODO(), metav1.ListOptions{})
+		clusterSizeData.Event = len(events.Items)
+		return nil
+	})
+	g.Go("limitranges", func() error {
+		limitRanges, err := clientset.CoreV1().LimitRanges("").List(ctx, metav1.ListOptions{})
 		if err != nil {
 			return errors.Wrap(err, "failed to list limitrange")
 		}
-		podDisruptionBudget, err := clientset.PolicyV1beta1().PodDisruptionBudgets("").List(context.TODO(), metav1.ListOptions{})
+		clusterSizeData.LimitRange = len(limitRanges.Items)
+		return nil
+	})
+	g.Go("poddisruptionbudgets", func() error {
+		version, err := servedVersion(clientset.Discovery(), "poddisruptionbudgets", "policy/v1", "policy/v1beta1")
 		if err != nil {
-			return errors.Wrap(err, "failed to list poddisruptionbudget")
+			return errors.Wrap(err, "failed to discover the poddisruptionbudgets api version")
 		}
-		podSecurityPolicy, err := clientset.PolicyV1beta1().PodSecurityPolicies().List(context.TODO(), metav1.ListOptions{})
+		switch version {
+		case "policy/v1":
+			podDisruptionBudget, err := clientset.PolicyV1().PodDisruptionBudgets("").List(ctx, metav1.ListOptions{})
+			if err != nil {
+				return errors.Wrap(err, "failed to list poddisruptionbudget")
+			}
+			clusterSizeData.PodDisruptionBudget = len(podDisruptionBudget.Items)
+		case "policy/v1beta1":
+			podDisruptionBudget, err := clientset.PolicyV1beta1().PodDisruptionBudgets("").List(ctx, metav1.ListOptions{})
+			if err != nil {
+				return errors.Wrap(err, "failed to list poddisruptionbudget")
+			}
+			clusterSizeData.PodDisruptionBudget = len(podDisruptionBudget.Items)
+		default:
+			return notServed("poddisruptionbudgets", strict)
+		}
+		return nil
+	})
+	g.Go("podsecuritypolicies", func() error {
+		podSecurityPolicies, err := countPodSecurityPolicies(ctx, clientset, strict)
 		if err != nil {
 			return errors.Wrap(err, "failed to list podsecuritypolicy")
 		}
+		clusterSizeData.PodSecurityPolicy = podSecurityPolicies
+		return nil
+	})
 
-		// Cluster APIs
-		clusterSizeData.Namespace = len(namespaces.Items)
-		clusterSizeData.Node = len(nodes.Items)
-		clusterSizeData.PersistentVolume = len(persistentVolumes.Items)
-		clusterSizeData.ServiceAccount = len(serviceAccounts.Items)
-		clusterSizeData.ClusterRole = len(clusterRoles.Items)
-		clusterSizeData.ClusterRoleBinding = len(clusterRoleBindings.Items)
-		clusterSizeData.Role = len(roles.Items)
-		clusterSizeData.RoleBinding = len(roleBindings.Items)
-		clusterSizeData.ResourceQuota = len(resourceQuotas.Items)
-		clusterSizeData.NetworkPolicy = len(networkPolicy.Items)
+	// Add-on APIs
+	g.Go("verticalpodautoscalers", func() error {
+		verticalPodAutoscalers, err := countVerticalPodAutoscalers(ctx, dynamicClient)
+		if err != nil {
+			return errors.Wrap(err, "failed to list verticalpodautoscalers")
+		}
+		clusterSizeData.VerticalPodAutoscaler = verticalPodAutoscalers
+		return nil
+	})
+	g.Go("metrics api", func() error {
+		metricsAPI, err := metricsAPIAvailable(clientset)
+		if err != nil {
+			return errors.Wrap(err, "failed to discover the metrics api")
+		}
+		clusterSizeData.MetricsAPI = metricsAPI
+		return nil
+	})
+	if err := g.Wait(); err != nil {
+		return err
+	}
 
-		// Workloads APIs
-		for _, pod := range pods.Items {
-			for range pod.Spec.Containers {
-				clusterSizeData.Container++
+	return writeOutput(cmd, func(out io.Writer) {
+		output.DisplayClusterSizeData(out, *clusterSizeData, getDisplayOptions(cmd))
+	})
+}
+
+// progress writes a single status line to stderr counting the completed steps of a slow collection (Ex the List
+// requests of size) so it does not appear to hang. A progress without out writes nothing.
+type progress struct {
+	mu    sync.Mutex
+	out   io.Writer
+	done  int
+	total int
+}
+
+// newProgress returns a progress writing to stderr when stdout is a terminal and the output is read by humans, machine
+// readable formats and redirected output are left without progress
+func newProgress(cmd *cobra.Command) *progress {
+	switch displayFormat, _ := cmd.Flags().GetString("output"); displayFormat {
+	case "json", "yaml", "jsonl", "openmetrics":
+		return &progress{}
+	}
+	if !term.IsTerminal(int(os.Stdout.Fd())) {
+		return &progress{}
+	}
+	return &progress{out: os.Stderr}
+}
+
+// step counts a completed step and rewrites the status line with its name
+func (p *progress) step(name string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.done++
+	if p.out != nil {
+		fmt.Fprintf(p.out, "\r\033[KListed %s (%d/%d)", name, p.done, p.total)
+	}
+}
+
+// clear erases the status line before the output is written
+func (p *progress) clear() {
+	if p.out != nil {
+		fmt.Fprint(p.out, "\r\033[K")
+	}
+}
+
+// progressGroup runs named functions concurrently on an errgroup and reports each completion on a progress line. The
+// functions only start on Wait so the total is known before the first one completes.
+type progressGroup struct {
+	group    *errgroup.Group
+	progress *progress
+	names    []string
+	funcs    []func() error
+}
+
+// Go adds a function to run on Wait, name is shown on the progress line once it completes (Ex deployments)
+func (g *progressGroup) Go(name string, f func() error) {
+	g.names = append(g.names, name)
+	g.funcs = append(g.funcs, f)
+}
+
+// Wait runs the added functions and returns the first error, the progress line is cleared before returning
+func (g *progressGroup) Wait() error {
+	g.progress.total = len(g.funcs)
+	for i := range g.funcs {
+		name, f := g.names[i], g.funcs[i]
+		g.group.Go(func() error {
+			err := f()
+			g.progress.step(name)
+			return err
+		})
+	}
+	err := g.group.Wait()
+	g.progress.clear()
+	return err
+}
+
+// servedVersion returns the first of groupVersions (newest first) the apiserver serves resource from, "" when none do.
+// Beta APIs are removed in newer Kubernetes (Ex batch/v1beta1 CronJobs in 1.25) while older clusters lack the GA API.
+func servedVersion(discoveryClient discovery.DiscoveryInterface, resource string, groupVersions ...string) (string, error) {
+	for _, groupVersion := range groupVersions {
+		resources, err := discoveryClient.ServerResourcesForGroupVersion(groupVersion)
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		if resources == nil {
+			continue
+		}
+		for _, apiResource := range resources.APIResources {
+			if apiResource.Name == resource {
+				return groupVersion, nil
 			}
 		}
-		clusterSizeData.Pod = len(pods.Items)
-		clusterSizeData.ReplicaSet = len(replicaSets.Items)
-		clusterSizeData.ReplicaController = len(replicationControllers.Items)
-		clusterSizeData.Deployment = len(deployments.Items)
-		clusterSizeData.Daemonset = len(daemonsets.Items)
-		clusterSizeData.StatefulSet = len(statefulSets.Items)
-		clusterSizeData.CronJob = len(cronJobs.Items)
-		clusterSizeData.Job = len(jobs.Items)
-
-		// Service APIs
-		clusterSizeData.EndPoints = len(endPoints.Items)
-		clusterSizeData.Service = len(services.Items)
-		clusterSizeData.Ingress = len(ingresses.Items)
+	}
+	return "", nil
+}
 
-		// Config And Storage APIs
-		clusterSizeData.Configmap = len(configmaps.Items)
-		clusterSizeData.Secret = len(secrets.Items)
-		clusterSizeData.PersistentVolumeClaim = len(persistentVolumeClaims.Items)
-		clusterSizeData.StorageClass = len(storageClasses.Items)
-		clusterSizeData.VolumeAttachment = len(volumeAttachments.Items)
+// notServed counts a resource the cluster no longer serves (Ex PodSecurityPolicies removed in 1.25) as 0 with a
+// warning, or fails the size collection with --strict
+func notServed(resource string, strict bool) error {
+	if strict {
+		return errors.Errorf("%s are not served by the cluster", resource)
+	}
+	fmt.Fprintf(os.Stderr, "warning: %s are not served by the cluster, counted as 0\n", resource)
+	return nil
+}
 
-		// Metadata APIs
-		clusterSizeData.Event = len(events.Items)
-		clusterSizeData.LimitRange = len(limitRanges.Items)
-		clusterSizeData.PodDisruptionBudget = len(podDisruptionBudget.Items)
-		clusterSizeData.PodSecurityPolicy = len(podSecurityPolicy.Items)
+// countPodSecurityPolicies counts the policy/v1beta1 PodSecurityPolicies, 0 on clusters where PSP is removed unless strict
+func countPodSecurityPolicies(ctx context.Context, clientset kubernetes.Interface, strict bool) (int, error) {
+	version, err := servedVersion(clientset.Discovery(), "podsecuritypolicies", "policy/v1beta1")
+	if err != nil {
+		return 0, err
+	}
+	if version == "" {
+		return 0, notServed("podsecuritypolicies", strict)
+	}
+	podSecurityPolicies, err := clientset.PolicyV1beta1().PodSecurityPolicies().List(ctx, metav1.ListOptions{})
+	if apierrors.IsNotFound(err) || runtime.IsNotRegisteredError(err) {
+		return 0, notServed("podsecuritypolicies", strict)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return len(podSecurityPolicies.Items), nil
+}
 
-		displayNoHeaders, _ := cmd.Flags().GetBool("no-headers")
+// countHorizontalPodAutoscalers counts the autoscaling/v2beta2 HorizontalPodAutoscalers, falling back to autoscaling/v1
+// on clusters which do not serve v2beta2 (Ex 1.26 and newer)
+func countHorizontalPodAutoscalers(ctx context.Context, clientset kubernetes.Interface) (int, error) {
+	horizontalPodAutoscalers, err := clientset.AutoscalingV2beta2().HorizontalPodAutoscalers("").List(ctx, metav1.ListOptions{})
+	if err == nil {
+		return len(horizontalPodAutoscalers.Items), nil
+	}
+	if !apierrors.IsNotFound(err) {
+		return 0, err
+	}
+	v1HorizontalPodAutoscalers, err := clientset.AutoscalingV1().HorizontalPodAutoscalers("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return 0, err
+	}
+	return len(v1HorizontalPodAutoscalers.Items), nil
+}
 
-		displayFormat, _ := cmd.Flags().GetString("output")
+// verticalPodAutoscalers is the VerticalPodAutoscaler CRD installed with the VPA add-on
+var verticalPodAutoscalers = schema.GroupVersionResource{Group: "autoscaling.k8s.io", Version: "v1", Resource: "verticalpodautoscalers"}
 
-		output.DisplayClusterSizeData(*clusterSizeData, !displayNoHeaders, displayFormat)
+// countVerticalPodAutoscalers counts the VerticalPodAutoscalers, 0 when the VPA CRD is not installed
+func countVerticalPodAutoscalers(ctx context.Context, dynamicClient dynamic.Interface) (int, error) {
+	vpas, err := dynamicClient.Resource(verticalPodAutoscalers).Namespace("").List(ctx, metav1.ListOptions{})
+	if apierrors.IsNotFound(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return len(vpas.Items), nil
+}
 
-		return nil
-	},
+// metricsAPIAvailable reports whether the metrics.k8s.io API (Ex metrics-server) is registered with the apiserver
+func metricsAPIAvailable(clientset kubernetes.Interface) (bool, error) {
+	resources, err := clientset.Discovery().ServerResourcesForGroupVersion("metrics.k8s.io/v1beta1")
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return resources != nil && len(resources.APIResources) > 0, nil
 }
 
 func init() {
 	rootCmd.AddCommand(sizeCmd)
+	sizeCmd.Flags().BoolP("strict", "", false, "Fail when a resource is not served by the cluster instead of counting it as 0 (Ex PodSecurityPolicies on 1.25+)")
+	sizeCmd.Flags().IntP("max-concurrency", "", 5, "Maximum number of concurrent api List requests, unbounded when 0 or less")
 }
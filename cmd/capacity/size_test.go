@@ -0,0 +1,159 @@
+/*
+Copyright © 2021 Alex Krzos akrzos@redhat.com
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package capacity
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sync/errgroup"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestCountHorizontalPodAutoscalersFallback(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		&autoscalingv1.HorizontalPodAutoscaler{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"}},
+		&autoscalingv1.HorizontalPodAutoscaler{ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "payments"}},
+	)
+	// A cluster newer than 1.25 answers autoscaling/v2beta2 requests with 404
+	clientset.PrependReactor("list", "horizontalpodautoscalers", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetResource().Version == "v2beta2" {
+			return true, nil, apierrors.NewNotFound(schema.GroupResource{Group: "autoscaling", Resource: "horizontalpodautoscalers"}, "")
+		}
+		return false, nil, nil
+	})
+
+	count, err := countHorizontalPodAutoscalers(context.Background(), clientset)
+	if err != nil {
+		t.Fatalf("countHorizontalPodAutoscalers: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("countHorizontalPodAutoscalers = %d, want 2 from autoscaling/v1", count)
+	}
+}
+
+func TestCountVerticalPodAutoscalers(t *testing.T) {
+	listKinds := map[schema.GroupVersionResource]string{verticalPodAutoscalers: "VerticalPodAutoscalerList"}
+	vpa := &unstructured.Unstructured{}
+	vpa.SetAPIVersion("autoscaling.k8s.io/v1")
+	vpa.SetKind("VerticalPodAutoscaler")
+	vpa.SetNamespace("default")
+	vpa.SetName("web")
+
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds, vpa)
+	if count, err := countVerticalPodAutoscalers(context.Background(), dynamicClient); err != nil || count != 1 {
+		t.Errorf("countVerticalPodAutoscalers = %d, %v, want 1", count, err)
+	}
+
+	// Without the VPA CRD the apiserver answers with 404
+	dynamicClient = dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds)
+	dynamicClient.PrependReactor("list", "verticalpodautoscalers", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewNotFound(verticalPodAutoscalers.GroupResource(), "")
+	})
+	if count, err := countVerticalPodAutoscalers(context.Background(), dynamicClient); err != nil || count != 0 {
+		t.Errorf("countVerticalPodAutoscalers without the CRD = %d, %v, want 0 and no error", count, err)
+	}
+}
+
+func TestMetricsAPIAvailable(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	if available, err := metricsAPIAvailable(clientset); err != nil || available {
+		t.Errorf("metricsAPIAvailable without metrics-server = %v, %v, want false", available, err)
+	}
+
+	clientset.Resources = []*metav1.APIResourceList{{
+		GroupVersion: "metrics.k8s.io/v1beta1",
+		APIResources: []metav1.APIResource{{Name: "pods", Kind: "PodMetrics"}, {Name: "nodes", Kind: "NodeMetrics"}},
+	}}
+	if available, err := metricsAPIAvailable(clientset); err != nil || !available {
+		t.Errorf("metricsAPIAvailable with metrics-server = %v, %v, want true", available, err)
+	}
+}
+
+func TestServedVersion(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	clientset.Resources = []*metav1.APIResourceList{
+		{GroupVersion: "batch/v1", APIResources: []metav1.APIResource{{Name: "jobs"}}},
+		{GroupVersion: "batch/v1beta1", APIResources: []metav1.APIResource{{Name: "cronjobs"}}},
+		{GroupVersion: "policy/v1", APIResources: []metav1.APIResource{{Name: "poddisruptionbudgets"}}},
+		{GroupVersion: "policy/v1beta1", APIResources: []metav1.APIResource{{Name: "poddisruptionbudgets"}}},
+	}
+	tests := []struct {
+		resource      string
+		groupVersions []string
+		want          string
+	}{
+		{"cronjobs", []string{"batch/v1", "batch/v1beta1"}, "batch/v1beta1"},
+		{"poddisruptionbudgets", []string{"policy/v1", "policy/v1beta1"}, "policy/v1"},
+		{"ingresses", []string{"networking.k8s.io/v1", "networking.k8s.io/v1beta1"}, ""},
+	}
+	for _, tt := range tests {
+		got, err := servedVersion(clientset.Discovery(), tt.resource, tt.groupVersions...)
+		if err != nil {
+			t.Errorf("servedVersion(%s): %v", tt.resource, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("servedVersion(%s) = %q, want %q", tt.resource, got, tt.want)
+		}
+	}
+}
+
+func TestCountPodSecurityPoliciesRemoved(t *testing.T) {
+	// Kubernetes 1.25+ still serves policy/v1beta1 for a while but without podsecuritypolicies
+	clientset := fake.NewSimpleClientset()
+	clientset.Resources = []*metav1.APIResourceList{
+		{GroupVersion: "policy/v1", APIResources: []metav1.APIResource{{Name: "poddisruptionbudgets"}}},
+		{GroupVersion: "policy/v1beta1", APIResources: []metav1.APIResource{{Name: "poddisruptionbudgets"}}},
+	}
+
+	count, err := countPodSecurityPolicies(context.Background(), clientset, false)
+	if err != nil || count != 0 {
+		t.Errorf("countPodSecurityPolicies without psp = %d, %v, want 0 and no error", count, err)
+	}
+	if _, err := countPodSecurityPolicies(context.Background(), clientset, true); err == nil {
+		t.Errorf("countPodSecurityPolicies without psp and --strict returned no error")
+	}
+}
+
+func TestProgressGroup(t *testing.T) {
+	var out bytes.Buffer
+	g := &progressGroup{group: new(errgroup.Group), progress: &progress{out: &out}}
+	g.Go("namespaces", func() error { return nil })
+	g.Go("nodes", func() error { return errors.New("forbidden") })
+	if err := g.Wait(); err == nil || err.Error() != "forbidden" {
+		t.Errorf("Wait() = %v, want forbidden", err)
+	}
+	for _, want := range []string{"Listed namespaces (", "Listed nodes (", "(2/2)"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("progress output %q does not contain %q", out.String(), want)
+		}
+	}
+	if !strings.HasSuffix(out.String(), "\r\033[K") {
+		t.Errorf("progress output %q does not end by clearing the line", out.String())
+	}
+}
@@ -0,0 +1,136 @@
+/*
+Copyright © 2021 Alex Krzos akrzos@redhat.com
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package capacity
+
+import (
+	"context"
+	"io"
+	"sort"
+
+	"github.com/akrzos/kubeSize/internal/capacity"
+	"github.com/akrzos/kubeSize/internal/kube"
+	"github.com/akrzos/kubeSize/internal/output"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// noStorageClass groups claims and volumes without a storage class
+const noStorageClass = "<none>"
+
+var storageCmd = &cobra.Command{
+	Use:     "storage",
+	Aliases: []string{"st"},
+	Short:   "Get persistent storage capacity grouped by storage class",
+	Long:    `Get persistent volume claim requests and persistent volume capacity grouped by storage class`,
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		return output.ValidateOutput(*cmd)
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runWatch(cmd, runStorage)
+	},
+}
+
+// runStorage collects and displays persistent storage capacity data grouped by storage class
+func runStorage(ctx context.Context, cmd *cobra.Command) error {
+	clientset, err := kube.CreateClientSet(KubernetesConfigFlags)
+	if err != nil {
+		return errors.Wrap(err, "failed to create clientset")
+	}
+
+	namespace, _ := cmd.Flags().GetString("namespace")
+
+	persistentVolumeClaims, err := clientset.CoreV1().PersistentVolumeClaims(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return errors.Wrap(err, "failed to list persistentvolumeclaims")
+	}
+
+	persistentVolumes, err := clientset.CoreV1().PersistentVolumes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return errors.Wrap(err, "failed to list persistentvolumes")
+	}
+
+	storageCapacityData := make(map[string]*output.StorageCapacityData)
+	storageClassNames := make([]string, 0)
+
+	storageClass := func(name string) *output.StorageCapacityData {
+		if name == "" {
+			name = noStorageClass
+		}
+		if _, ok := storageCapacityData[name]; !ok {
+			storageClassNames = append(storageClassNames, name)
+			storageCapacityData[name] = new(output.StorageCapacityData)
+		}
+		return storageCapacityData[name]
+	}
+
+	for _, pvc := range persistentVolumeClaims.Items {
+		className := ""
+		if pvc.Spec.StorageClassName != nil {
+			className = *pvc.Spec.StorageClassName
+		}
+		data := storageClass(className)
+		data.TotalPVCCount++
+		switch pvc.Status.Phase {
+		case corev1.ClaimBound:
+			data.BoundPVCCount++
+		case corev1.ClaimPending:
+			data.PendingPVCCount++
+		}
+		data.TotalRequestsStorage.Add(*pvc.Spec.Resources.Requests.Storage())
+	}
+
+	for _, pv := range persistentVolumes.Items {
+		data := storageClass(pv.Spec.StorageClassName)
+		data.TotalPVCount++
+		data.TotalCapacityStorage.Add(*pv.Spec.Capacity.Storage())
+	}
+
+	storageCapacityData["*total*"] = new(output.StorageCapacityData)
+
+	// Populate "Human" readable capacity data values and the *total* "storage class"
+	for _, className := range storageClassNames {
+		storageCapacityData[className].TotalRequestsStorageGB = capacity.ReadableStorage(storageCapacityData[className].TotalRequestsStorage)
+		storageCapacityData[className].TotalCapacityStorageGB = capacity.ReadableStorage(storageCapacityData[className].TotalCapacityStorage)
+		storageCapacityData["*total*"].TotalPVCCount += storageCapacityData[className].TotalPVCCount
+		storageCapacityData["*total*"].BoundPVCCount += storageCapacityData[className].BoundPVCCount
+		storageCapacityData["*total*"].PendingPVCCount += storageCapacityData[className].PendingPVCCount
+		storageCapacityData["*total*"].TotalRequestsStorage.Add(storageCapacityData[className].TotalRequestsStorage)
+		storageCapacityData["*total*"].TotalRequestsStorageGB += storageCapacityData[className].TotalRequestsStorageGB
+		storageCapacityData["*total*"].TotalPVCount += storageCapacityData[className].TotalPVCount
+		storageCapacityData["*total*"].TotalCapacityStorage.Add(storageCapacityData[className].TotalCapacityStorage)
+		storageCapacityData["*total*"].TotalCapacityStorageGB += storageCapacityData[className].TotalCapacityStorageGB
+	}
+
+	sort.Strings(storageClassNames)
+	reverseNames(cmd, storageClassNames)
+
+	displayTotal, _ := cmd.Flags().GetBool("display-total")
+
+	if displayTotal {
+		storageClassNames = append(storageClassNames, "*total*")
+	}
+
+	return writeOutput(cmd, func(out io.Writer) {
+		output.DisplayStorageData(out, storageCapacityData, storageClassNames, getDisplayOptions(cmd))
+	})
+}
+
+func init() {
+	rootCmd.AddCommand(storageCmd)
+	storageCmd.Flags().BoolP("display-total", "t", false, "Display sum of all storage class capacity data in table output")
+}
@@ -0,0 +1,156 @@
+/*
+Copyright © 2021 Alex Krzos akrzos@redhat.com
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package capacity
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/akrzos/kubeSize/internal/capacity"
+	"github.com/akrzos/kubeSize/internal/output"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+var windowCmd = &cobra.Command{
+	Use:     "window",
+	Aliases: []string{"w"},
+	Short:   "Get cluster capacity data over a window of snapshots",
+	Long:    `Get min, max and average cluster allocatable and available capacity across json snapshots of the cluster command`,
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		return output.ValidateOutput(*cmd)
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		snapshotDir, _ := cmd.Flags().GetString("snapshots")
+		if snapshotDir == "" {
+			return errors.New("--snapshots directory is required")
+		}
+
+		snapshots, err := loadClusterSnapshots(snapshotDir)
+		if err != nil {
+			return err
+		}
+
+		windowCapacityData := new(output.WindowCapacityData)
+		windowCapacityData.SnapshotCount = len(snapshots)
+
+		var totalNodeCount int
+		for i, snapshot := range snapshots {
+			if i == 0 || snapshot.TotalNodeCount < windowCapacityData.MinNodeCount {
+				windowCapacityData.MinNodeCount = snapshot.TotalNodeCount
+			}
+			if snapshot.TotalNodeCount > windowCapacityData.MaxNodeCount {
+				windowCapacityData.MaxNodeCount = snapshot.TotalNodeCount
+			}
+			totalNodeCount += snapshot.TotalNodeCount
+		}
+		windowCapacityData.AvgNodeCount = float64(totalNodeCount) / float64(len(snapshots))
+
+		windowCapacityData.MinAllocatableCPU, windowCapacityData.MaxAllocatableCPU, windowCapacityData.AvgAllocatableCPU = quantityWindow(snapshots, func(c output.ClusterCapacityData) resource.Quantity { return c.TotalAllocatableCPU })
+		windowCapacityData.MinAllocatableMemory, windowCapacityData.MaxAllocatableMemory, windowCapacityData.AvgAllocatableMemory = quantityWindow(snapshots, func(c output.ClusterCapacityData) resource.Quantity { return c.TotalAllocatableMemory })
+		windowCapacityData.MinAvailableCPU, windowCapacityData.MaxAvailableCPU, windowCapacityData.AvgAvailableCPU = quantityWindow(snapshots, func(c output.ClusterCapacityData) resource.Quantity { return c.TotalAvailableCPU })
+		windowCapacityData.MinAvailableMemory, windowCapacityData.MaxAvailableMemory, windowCapacityData.AvgAvailableMemory = quantityWindow(snapshots, func(c output.ClusterCapacityData) resource.Quantity { return c.TotalAvailableMemory })
+
+		// Populate "Human" readable capacity data values
+		windowCapacityData.MinAllocatableCPUCores = capacity.ReadableCPU(windowCapacityData.MinAllocatableCPU)
+		windowCapacityData.MaxAllocatableCPUCores = capacity.ReadableCPU(windowCapacityData.MaxAllocatableCPU)
+		windowCapacityData.AvgAllocatableCPUCores = capacity.ReadableCPU(windowCapacityData.AvgAllocatableCPU)
+		windowCapacityData.MinAllocatableMemoryGiB = capacity.ReadableMem(windowCapacityData.MinAllocatableMemory)
+		windowCapacityData.MaxAllocatableMemoryGiB = capacity.ReadableMem(windowCapacityData.MaxAllocatableMemory)
+		windowCapacityData.AvgAllocatableMemoryGiB = capacity.ReadableMem(windowCapacityData.AvgAllocatableMemory)
+		windowCapacityData.MinAvailableCPUCores = capacity.ReadableCPU(windowCapacityData.MinAvailableCPU)
+		windowCapacityData.MaxAvailableCPUCores = capacity.ReadableCPU(windowCapacityData.MaxAvailableCPU)
+		windowCapacityData.AvgAvailableCPUCores = capacity.ReadableCPU(windowCapacityData.AvgAvailableCPU)
+		windowCapacityData.MinAvailableMemoryGiB = capacity.ReadableMem(windowCapacityData.MinAvailableMemory)
+		windowCapacityData.MaxAvailableMemoryGiB = capacity.ReadableMem(windowCapacityData.MaxAvailableMemory)
+		windowCapacityData.AvgAvailableMemoryGiB = capacity.ReadableMem(windowCapacityData.AvgAvailableMemory)
+
+		return writeOutput(cmd, func(out io.Writer) {
+			output.DisplayWindowData(out, *windowCapacityData, getDisplayOptions(cmd))
+		})
+	},
+}
+
+// loadClusterSnapshots reads every json file in dir produced by "capacity cluster -o json", in file name order
+func loadClusterSnapshots(dir string) ([]output.ClusterCapacityData, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read snapshots directory")
+	}
+
+	var fileNames []string
+	for _, file := range files {
+		if !file.IsDir() && strings.HasSuffix(file.Name(), ".json") {
+			fileNames = append(fileNames, file.Name())
+		}
+	}
+	sort.Strings(fileNames)
+
+	if len(fileNames) == 0 {
+		return nil, errors.Errorf("no json snapshots found in %s", dir)
+	}
+
+	snapshots := make([]output.ClusterCapacityData, 0, len(fileNames))
+	for _, fileName := range fileNames {
+		data, err := ioutil.ReadFile(filepath.Join(dir, fileName))
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read snapshot %s", fileName)
+		}
+		data, err = output.UnwrapEnvelope(data)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse snapshot %s", fileName)
+		}
+		var snapshot output.ClusterCapacityData
+		if err := json.Unmarshal(data, &snapshot); err != nil {
+			return nil, errors.Wrapf(err, "failed to parse snapshot %s", fileName)
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	return snapshots, nil
+}
+
+// quantityWindow returns the min, max and average of a quantity selected from each snapshot
+func quantityWindow(snapshots []output.ClusterCapacityData, quantity func(output.ClusterCapacityData) resource.Quantity) (resource.Quantity, resource.Quantity, resource.Quantity) {
+	var min, max resource.Quantity
+	var sum float64
+	format := resource.DecimalSI
+	for i, snapshot := range snapshots {
+		q := quantity(snapshot)
+		if i == 0 {
+			min = q.DeepCopy()
+			max = q.DeepCopy()
+			format = q.Format
+		} else if q.Cmp(min) < 0 {
+			min = q.DeepCopy()
+		} else if q.Cmp(max) > 0 {
+			max = q.DeepCopy()
+		}
+		sum += float64(q.MilliValue())
+	}
+	avg := resource.NewMilliQuantity(int64(sum/float64(len(snapshots))), format)
+	return min, max, *avg
+}
+
+func init() {
+	rootCmd.AddCommand(windowCmd)
+	windowCmd.Flags().StringP("snapshots", "", "", "Directory of json snapshots from \"capacity cluster -o json\" to summarize")
+}
@@ -0,0 +1,152 @@
+/*
+Copyright © 2021 Alex Krzos akrzos@redhat.com
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package capacity
+
+import (
+	"context"
+	"io"
+	"sort"
+
+	"github.com/akrzos/kubeSize/internal/capacity"
+	"github.com/akrzos/kubeSize/internal/kube"
+	"github.com/akrzos/kubeSize/internal/output"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+)
+
+var workloadsCmd = &cobra.Command{
+	Use:     "workloads",
+	Aliases: []string{"wl"},
+	Short:   "Get pod count and requests grouped by workload",
+	Long:    `Get the count and requests of non-terminated pods grouped by their top-level owner (Deployment, StatefulSet, DaemonSet or Job)`,
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		return output.ValidateOutput(*cmd)
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runWatch(cmd, runWorkloads)
+	},
+}
+
+// runWorkloads collects and displays workload capacity data
+func runWorkloads(ctx context.Context, cmd *cobra.Command) error {
+	clientset, err := kube.CreateClientSet(KubernetesConfigFlags)
+	if err != nil {
+		return errors.Wrap(err, "failed to create clientset")
+	}
+
+	chunkSize, _ := cmd.Flags().GetInt64("chunk-size")
+	nsFlag, _ := cmd.Flags().GetString("namespace")
+
+	selector := "status.phase!=" + string(corev1.PodSucceeded) + ",status.phase!=" + string(corev1.PodFailed)
+	if nsFlag != "" {
+		selector += ",metadata.namespace=" + nsFlag
+	}
+	fieldSelector, err := fields.ParseSelector(selector)
+	if err != nil {
+		return errors.Wrap(err, "failed to create fieldSelector")
+	}
+
+	podListOptions, err := withFieldSelector(cmd, metav1.ListOptions{FieldSelector: fieldSelector.String()})
+	if err != nil {
+		return err
+	}
+
+	nonTermPods, err := kube.ListPods(ctx, clientset, "", podListOptions, chunkSize)
+	if err != nil {
+		return errors.Wrap(err, "failed to list non-term pods")
+	}
+
+	replicaSets, err := clientset.AppsV1().ReplicaSets(nsFlag).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return errors.Wrap(err, "failed to list replicasets")
+	}
+
+	workloadCapacityData, workloadNames := collectWorkloadData(nonTermPods.Items, replicaSets.Items)
+
+	// Most pods first, workloads with equal pod counts stay in namespace, kind and name order
+	sort.Strings(workloadNames)
+	sort.SliceStable(workloadNames, func(i, j int) bool {
+		return workloadCapacityData[workloadNames[i]].TotalPodCount > workloadCapacityData[workloadNames[j]].TotalPodCount
+	})
+	reverseNames(cmd, workloadNames)
+
+	// --top only limits the rows displayed, the *total* "workload" still sums every workload
+	workloadNames = topNames(cmd, append(workloadNames, "*bare*"))
+
+	if displayTotal, _ := cmd.Flags().GetBool("display-total"); displayTotal {
+		workloadNames = append(workloadNames, "*total*")
+	}
+
+	return writeOutput(cmd, func(out io.Writer) {
+		output.DisplayWorkloadData(out, workloadCapacityData, workloadNames, getDisplayOptions(cmd))
+	})
+}
+
+// collectWorkloadData groups non-terminated pods by their top-level owner, keyed by namespace/kind/name. Pods without a
+// controller owner are grouped under *bare*. The workload names returned are unsorted and exclude the pseudo-rows.
+func collectWorkloadData(pods []corev1.Pod, replicaSets []appsv1.ReplicaSet) (map[string]*output.WorkloadCapacityData, []string) {
+	// Deployments own pods through a ReplicaSet, map each ReplicaSet to its controller
+	replicaSetOwners := make(map[string]*metav1.OwnerReference)
+	for i := range replicaSets {
+		if owner := metav1.GetControllerOf(&replicaSets[i]); owner != nil {
+			replicaSetOwners[replicaSets[i].Namespace+"/"+replicaSets[i].Name] = owner
+		}
+	}
+
+	workloadCapacityData := make(map[string]*output.WorkloadCapacityData)
+	workloadNames := make([]string, 0)
+	workloadCapacityData["*total*"] = &output.WorkloadCapacityData{Name: "*total*"}
+	workloadCapacityData["*bare*"] = &output.WorkloadCapacityData{Name: "*bare*"}
+
+	for i := range pods {
+		pod := pods[i]
+		workloadName := "*bare*"
+		if owner := metav1.GetControllerOf(&pod); owner != nil {
+			kind, name := owner.Kind, owner.Name
+			if replicaSetOwner, ok := replicaSetOwners[pod.Namespace+"/"+name]; ok && kind == "ReplicaSet" {
+				kind, name = replicaSetOwner.Kind, replicaSetOwner.Name
+			}
+			workloadName = pod.Namespace + "/" + kind + "/" + name
+			if _, ok := workloadCapacityData[workloadName]; !ok {
+				workloadNames = append(workloadNames, workloadName)
+				workloadCapacityData[workloadName] = &output.WorkloadCapacityData{Namespace: pod.Namespace, Kind: kind, Name: name}
+			}
+		}
+		podRequests, _ := capacity.PodRequestsAndLimits(pod)
+		for _, k := range []string{workloadName, "*total*"} {
+			workloadCapacityData[k].TotalPodCount++
+			workloadCapacityData[k].TotalRequestsCPU.Add(*podRequests.Cpu())
+			workloadCapacityData[k].TotalRequestsMemory.Add(*podRequests.Memory())
+		}
+	}
+
+	// Populate "Human" readable capacity data values
+	for _, workload := range append([]string{"*total*", "*bare*"}, workloadNames...) {
+		workloadCapacityData[workload].TotalRequestsCPUCores = capacity.ReadableCPU(workloadCapacityData[workload].TotalRequestsCPU)
+		workloadCapacityData[workload].TotalRequestsMemoryGiB = capacity.ReadableMem(workloadCapacityData[workload].TotalRequestsMemory)
+	}
+	return workloadCapacityData, workloadNames
+}
+
+func init() {
+	rootCmd.AddCommand(workloadsCmd)
+	workloadsCmd.Flags().IntP("top", "", 0, "Only include the N workloads with the most pods in the output, 0 includes all")
+	workloadsCmd.Flags().BoolP("display-total", "t", false, "Display sum of all workload capacity data in table output")
+}
@@ -0,0 +1,83 @@
+/*
+Copyright © 2021 Alex Krzos akrzos@redhat.com
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package capacity
+
+import (
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ownedBy sets the controller owner reference of obj
+func ownedBy(obj metav1.Object, kind, name string) {
+	controller := true
+	obj.SetOwnerReferences([]metav1.OwnerReference{{Kind: kind, Name: name, Controller: &controller}})
+}
+
+func TestCollectWorkloadData(t *testing.T) {
+	replicaSet := appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{Name: "web-5d4f", Namespace: "default"}}
+	ownedBy(&replicaSet, "Deployment", "web")
+	bareReplicaSet := appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{Name: "legacy", Namespace: "default"}}
+
+	requests := corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m"), corev1.ResourceMemory: resource.MustParse("1Gi")}
+	pods := []corev1.Pod{
+		testPod("web-5d4f-a", "worker-0", requests),
+		testPod("web-5d4f-b", "worker-1", requests),
+		testPod("legacy-a", "worker-0", requests),
+		testPod("db-0", "worker-0", requests),
+		testPod("fluentd-x", "worker-0", requests),
+		testPod("debug", "worker-0", requests),
+	}
+	ownedBy(&pods[0], "ReplicaSet", "web-5d4f")
+	ownedBy(&pods[1], "ReplicaSet", "web-5d4f")
+	ownedBy(&pods[2], "ReplicaSet", "legacy")
+	ownedBy(&pods[3], "StatefulSet", "db")
+	ownedBy(&pods[4], "DaemonSet", "fluentd")
+
+	workloadCapacityData, workloadNames := collectWorkloadData(pods, []appsv1.ReplicaSet{replicaSet, bareReplicaSet})
+
+	if len(workloadNames) != 4 {
+		t.Errorf("workload names = %v, want 4 workloads", workloadNames)
+	}
+	tests := []struct {
+		workload string
+		wantPods int
+		wantCPU  float64
+	}{
+		{"default/Deployment/web", 2, 1},
+		{"default/ReplicaSet/legacy", 1, 0.5},
+		{"default/StatefulSet/db", 1, 0.5},
+		{"default/DaemonSet/fluentd", 1, 0.5},
+		{"*bare*", 1, 0.5},
+		{"*total*", 6, 3},
+	}
+	for _, tt := range tests {
+		workloadData, ok := workloadCapacityData[tt.workload]
+		if !ok {
+			t.Errorf("no %s workload", tt.workload)
+			continue
+		}
+		if workloadData.TotalPodCount != tt.wantPods {
+			t.Errorf("%s pods = %d, want %d", tt.workload, workloadData.TotalPodCount, tt.wantPods)
+		}
+		if workloadData.TotalRequestsCPUCores != tt.wantCPU {
+			t.Errorf("%s cpu requests = %v, want %v", tt.workload, workloadData.TotalRequestsCPUCores, tt.wantCPU)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+/*
+Copyright © 2021 Alex Krzos akrzos@redhat.com
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package capacity
+
+import (
+	"context"
+	"io"
+
+	"github.com/akrzos/kubeSize/internal/kube"
+	"github.com/akrzos/kubeSize/internal/output"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+var zoneCmd = &cobra.Command{
+	Use:     "zone",
+	Aliases: []string{"z"},
+	Short:   "Get cluster capacity data grouped by zone",
+	Long:    `Get metrics and data related to cluster capacity grouped by the topology.kubernetes.io/zone node label, or the node label set by --label-key`,
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		return output.ValidateOutput(*cmd)
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runWatch(cmd, runZone)
+	},
+}
+
+// runZone collects and displays cluster capacity data grouped by the value of the --label-key node label
+func runZone(ctx context.Context, cmd *cobra.Command) error {
+	labelKey, _ := cmd.Flags().GetString("label-key")
+	labelSelectorFlag, _ := cmd.Flags().GetString("label-selector")
+	labelSelector, err := labels.Parse(labelSelectorFlag)
+	if err != nil {
+		return errors.Wrap(err, "failed to parse label-selector")
+	}
+
+	clientset, err := kube.CreateClientSet(KubernetesConfigFlags)
+	if err != nil {
+		return errors.Wrap(err, "failed to create clientset")
+	}
+
+	chunkSize, _ := cmd.Flags().GetInt64("chunk-size")
+
+	nodes, err := kube.ListNodes(ctx, clientset, metav1.ListOptions{LabelSelector: labelSelector.String()}, chunkSize)
+	if err != nil {
+		return errors.Wrap(err, "failed to list nodes")
+	}
+
+	podListOptions, err := withFieldSelector(cmd, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+
+	pods, err := kube.ListPods(ctx, clientset, "", podListOptions, chunkSize)
+	if err != nil {
+		return errors.Wrap(err, "failed to list pods")
+	}
+	pods.Items = namespacePods(cmd, pods.Items)
+
+	labelCapacityData, labelValues := collectNodeLabelData(cmd, nodes.Items, pods.Items, labelKey)
+
+	displayOptions := getDisplayOptions(cmd)
+	displayOptions.Schedulable, _ = cmd.Flags().GetBool("schedulable")
+
+	return writeOutput(cmd, func(out io.Writer) {
+		output.DisplayNodeLabelData(out, labelCapacityData, labelValues, labelKey, displayOptions)
+		if displayOptions.Explain {
+			output.DisplayExplain(out, displayOptions)
+		}
+	})
+}
+
+// collectNodeLabelData sums the capacity of nodes and the requests of pods by the value of the labelKey node label, nodes
+// without the label are summed under <none>
+func collectNodeLabelData(cmd *cobra.Command, nodes []corev1.Node, pods []corev1.Pod, labelKey string) (map[string]*output.ClusterCapacityData, []string) {
+	return collectNodeGroupData(cmd, nodes, pods, nil, func(node corev1.Node) sets.String {
+		if labelValue, ok := node.Labels[labelKey]; ok && labelValue != "" {
+			return sets.NewString(labelValue)
+		}
+		return sets.NewString()
+	})
+}
+
+func init() {
+	rootCmd.AddCommand(zoneCmd)
+	addCapacityFlags(zoneCmd)
+	zoneCmd.Flags().StringP("label-key", "", "topology.kubernetes.io/zone", "Node label whose values group the nodes (Ex node.kubernetes.io/instance-type)")
+	zoneCmd.Flags().BoolP("unassigned", "u", false, "Include unassigned pod row, pods which do not have a node")
+	zoneCmd.Flags().BoolP("display-total", "t", false, "Display sum of all zone capacity data in table output")
+	zoneCmd.Flags().BoolP("schedulable", "", false, "Include schedulable node count and allocatable capacity, excluding cordoned and control-plane tainted nodes, in table output")
+	zoneCmd.Flags().BoolP("exclude-unready", "", false, "Exclude the capacity and allocatable of nodes which are not Ready, along with the requests of their pods, node counts are unaffected")
+	zoneCmd.Flags().BoolP("qos", "", false, "Include Guaranteed, Burstable and BestEffort non-terminated pod counts and their cpu and memory requests in table output")
+	zoneCmd.Flags().BoolP("hide-empty-unassigned", "", false, "Omit the unassigned pod row when there are no unassigned pods")
+	zoneCmd.Flags().StringP("label-selector", "l", "", "Only include nodes matching this label selector (Ex node.kubernetes.io/instance-type=m5.xlarge)")
+}
@@ -0,0 +1,67 @@
+/*
+Copyright © 2021 Alex Krzos akrzos@redhat.com
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package capacity
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestCollectNodeLabelData(t *testing.T) {
+	nodes := []corev1.Node{
+		testNode("worker-0", "worker", "4", "16Gi", "100G"),
+		testNode("worker-1", "worker", "4", "16Gi", "100G"),
+		testNode("worker-2", "worker", "8", "32Gi", "100G"),
+		testNode("infra-0", "infra", "4", "16Gi", "100G"),
+	}
+	nodes[0].Labels["topology.kubernetes.io/zone"] = "us-east-1a"
+	nodes[1].Labels["topology.kubernetes.io/zone"] = "us-east-1a"
+	nodes[2].Labels["topology.kubernetes.io/zone"] = "us-east-1b"
+	pods := []corev1.Pod{
+		testPod("app-0", "worker-0", corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")}),
+		testPod("app-1", "worker-1", corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2")}),
+		testPod("app-2", "worker-2", corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m")}),
+	}
+
+	zoneCapacityData, zoneNames := collectNodeLabelData(zoneCmd, nodes, pods, "topology.kubernetes.io/zone")
+	if len(zoneNames) != 3 || zoneNames[0] != "<none>" || zoneNames[1] != "us-east-1a" || zoneNames[2] != "us-east-1b" {
+		t.Fatalf("zoneNames = %v, want [<none> us-east-1a us-east-1b]", zoneNames)
+	}
+	tests := []struct {
+		zone        string
+		nodes       int
+		allocatable string
+		requests    string
+	}{
+		{"us-east-1a", 2, "8", "3"},
+		{"us-east-1b", 1, "8", "500m"},
+		{"<none>", 1, "4", "0"},
+	}
+	for _, tt := range tests {
+		data := zoneCapacityData[tt.zone]
+		if data.TotalNodeCount != tt.nodes {
+			t.Errorf("%s TotalNodeCount = %d, want %d", tt.zone, data.TotalNodeCount, tt.nodes)
+		}
+		if want := resource.MustParse(tt.allocatable); data.TotalAllocatableCPU.Cmp(want) != 0 {
+			t.Errorf("%s TotalAllocatableCPU = %s, want %s", tt.zone, data.TotalAllocatableCPU.String(), tt.allocatable)
+		}
+		if want := resource.MustParse(tt.requests); data.TotalRequestsCPU.Cmp(want) != 0 {
+			t.Errorf("%s TotalRequestsCPU = %s, want %s", tt.zone, data.TotalRequestsCPU.String(), tt.requests)
+		}
+	}
+}
@@ -15,7 +15,13 @@ limitations under the License.
 */
 package capacity
 
-import "k8s.io/apimachinery/pkg/api/resource"
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
 
 func StringInSlice(a string, list []string) bool {
 	for _, b := range list {
@@ -31,12 +37,260 @@ func ReadableCPU(cpu resource.Quantity) float64 {
 	return float64(cpu.MilliValue()) / 1000
 }
 
+const (
+	BinaryUnits  string = "binary"
+	DecimalUnits string = "decimal"
+)
+
+// Bases used by TableMem and TableStorage, by default memory is GiB (Gibibyte) and storage is GB (Gigabyte). The
+// readable json and yaml fields (*GiB and *GB) always keep the unit in their name regardless of --units.
+var (
+	memoryBase  float64 = 1024
+	storageBase float64 = 1000
+)
+
+// SetUnits selects a single base for both memory and storage in table output, binary (GiB) or decimal (GB). An empty
+// units restores the default of GiB memory and GB storage.
+func SetUnits(units string) error {
+	switch units {
+	case "":
+		memoryBase, storageBase = 1024, 1000
+	case BinaryUnits:
+		memoryBase, storageBase = 1024, 1024
+	case DecimalUnits:
+		memoryBase, storageBase = 1000, 1000
+	default:
+		return fmt.Errorf("Units \"%s\" is invalid. Valid values are %v", units, []string{BinaryUnits, DecimalUnits})
+	}
+	return nil
+}
+
+// MemoryUnit is the unit of TableMem values
+func MemoryUnit() string {
+	return unitName(memoryBase)
+}
+
+// StorageUnit is the unit of TableStorage values
+func StorageUnit() string {
+	return unitName(storageBase)
+}
+
+func unitName(base float64) string {
+	if base == 1024 {
+		return "GiB"
+	}
+	return "GB"
+}
+
 func ReadableMem(mem resource.Quantity) float64 {
-	// Convert from KiB to GiB (Gibibyte)
+	// Convert from bytes to GiB (Gibibyte)
 	return float64(mem.Value()) / 1024 / 1024 / 1024
 }
 
 func ReadableStorage(storage resource.Quantity) float64 {
-	// Convert from KiB to GB (Gigabyte)
+	// Convert from bytes to GB (Gigabyte)
 	return float64(storage.Value()) / 1000 / 1000 / 1000
 }
+
+// TableMem converts a ReadableMem GiB value to the MemoryUnit of table output
+func TableMem(gib float64) float64 {
+	return gib * 1024 * 1024 * 1024 / memoryBase / memoryBase / memoryBase
+}
+
+// TableStorage converts a ReadableStorage GB value to the StorageUnit of table output
+func TableStorage(gb float64) float64 {
+	return gb * 1000 * 1000 * 1000 / storageBase / storageBase / storageBase
+}
+
+// Reserved returns the capacity - allocatable of a node resource, the amount held back by kube-reserved,
+// system-reserved and the eviction threshold
+func Reserved(node corev1.Node, resourceName corev1.ResourceName) resource.Quantity {
+	reserved := node.Status.Capacity[resourceName]
+	reserved = reserved.DeepCopy()
+	reserved.Sub(node.Status.Allocatable[resourceName])
+	return reserved
+}
+
+// Utilization returns used as a percentage of allocatable, 0 when nothing is allocatable (Ex an unassigned row)
+func Utilization(used, allocatable resource.Quantity) float64 {
+	if allocatable.IsZero() {
+		return 0
+	}
+	return float64(used.MilliValue()) / float64(allocatable.MilliValue()) * 100
+}
+
+// Ratio returns limits as a multiple of requests, 0 when there are no requests (Ex limits set without requests)
+func Ratio(limits, requests resource.Quantity) float64 {
+	if requests.IsZero() {
+		return 0
+	}
+	return float64(limits.MilliValue()) / float64(requests.MilliValue())
+}
+
+func ImageRegistry(image string) string {
+	// Images without a registry host component (Ex nginx:latest or library/nginx) are pulled from Docker Hub
+	slash := strings.Index(image, "/")
+	if slash == -1 {
+		return "docker.io"
+	}
+	host := image[:slash]
+	if !strings.ContainsAny(host, ".:") && host != "localhost" {
+		return "docker.io"
+	}
+	if host == "index.docker.io" || host == "registry-1.docker.io" {
+		return "docker.io"
+	}
+	return strings.ToLower(host)
+}
+
+// IsSchedulable is false for cordoned nodes and control-plane nodes tainted against general workloads
+func IsSchedulable(node corev1.Node) bool {
+	if node.Spec.Unschedulable {
+		return false
+	}
+	for _, taint := range node.Spec.Taints {
+		if (taint.Key == "node-role.kubernetes.io/master" || taint.Key == "node-role.kubernetes.io/control-plane") && taint.Effect == corev1.TaintEffectNoSchedule {
+			return false
+		}
+	}
+	return true
+}
+
+// AcceptsWorkloads is false for cordoned nodes and nodes with a NoSchedule or NoExecute taint that general workloads do
+// not tolerate. Only the node.kubernetes.io/not-ready and node.kubernetes.io/unreachable NoExecute taints are tolerated,
+// matching the tolerations the DefaultTolerationSeconds admission plugin adds to every pod.
+func AcceptsWorkloads(node corev1.Node) bool {
+	if node.Spec.Unschedulable {
+		return false
+	}
+	for _, taint := range node.Spec.Taints {
+		switch taint.Effect {
+		case corev1.TaintEffectNoSchedule:
+			return false
+		case corev1.TaintEffectNoExecute:
+			if taint.Key != corev1.TaintNodeNotReady && taint.Key != corev1.TaintNodeUnreachable {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// FormatTaint formats a node taint as key=value:effect, or key:effect when the taint has no value
+func FormatTaint(taint corev1.Taint) string {
+	if taint.Value == "" {
+		return taint.Key + ":" + string(taint.Effect)
+	}
+	return taint.Key + "=" + taint.Value + ":" + string(taint.Effect)
+}
+
+// IsNodeReady is true for nodes with a true Ready condition
+func IsNodeReady(node corev1.Node) bool {
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == corev1.NodeReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// IsDaemonSetPod is true for pods owned by a DaemonSet
+func IsDaemonSetPod(pod corev1.Pod) bool {
+	for _, owner := range pod.OwnerReferences {
+		if owner.Kind == "DaemonSet" {
+			return true
+		}
+	}
+	return false
+}
+
+// IsPodReady is true for pods with a true Ready condition, running pods only serve traffic once Ready
+func IsPodReady(pod corev1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// PodQOSClass returns the QoS class of a pod with the Kubernetes rules, only cpu and memory are considered. A pod without
+// any requests or limits is BestEffort. A pod is Guaranteed when every container, init containers included, has cpu and
+// memory limits and the summed requests equal the summed limits. Every other pod is Burstable.
+func PodQOSClass(pod corev1.Pod) corev1.PodQOSClass {
+	requests, limits := corev1.ResourceList{}, corev1.ResourceList{}
+	isGuaranteed := true
+	containers := append(append([]corev1.Container{}, pod.Spec.Containers...), pod.Spec.InitContainers...)
+	for _, container := range containers {
+		for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+			if quantity, ok := container.Resources.Requests[name]; ok && quantity.Sign() > 0 {
+				addResourceList(requests, corev1.ResourceList{name: quantity})
+			}
+			if quantity, ok := container.Resources.Limits[name]; ok && quantity.Sign() > 0 {
+				addResourceList(limits, corev1.ResourceList{name: quantity})
+			} else {
+				isGuaranteed = false
+			}
+		}
+	}
+	if len(requests) == 0 && len(limits) == 0 {
+		return corev1.PodQOSBestEffort
+	}
+	if isGuaranteed {
+		for name, request := range requests {
+			if limit, ok := limits[name]; !ok || limit.Cmp(request) != 0 {
+				isGuaranteed = false
+				break
+			}
+		}
+	}
+	if isGuaranteed && len(requests) == len(limits) {
+		return corev1.PodQOSGuaranteed
+	}
+	return corev1.PodQOSBurstable
+}
+
+// PodRequestsAndLimits returns the effective pod requests and limits the scheduler uses, the sum of the app containers
+// or the largest init container for each resource, whichever is greater, plus the RuntimeClass pod overhead
+func PodRequestsAndLimits(pod corev1.Pod) (corev1.ResourceList, corev1.ResourceList) {
+	requests, limits := corev1.ResourceList{}, corev1.ResourceList{}
+	for _, container := range pod.Spec.Containers {
+		addResourceList(requests, container.Resources.Requests)
+		addResourceList(limits, container.Resources.Limits)
+	}
+	// Init containers run serially before the app containers so only the largest one counts
+	for _, container := range pod.Spec.InitContainers {
+		maxResourceList(requests, container.Resources.Requests)
+		maxResourceList(limits, container.Resources.Limits)
+	}
+	// Overhead is nil unless the pod's RuntimeClass defines one, limits only include it where a limit is already set
+	if pod.Spec.Overhead != nil {
+		addResourceList(requests, pod.Spec.Overhead)
+		for name, quantity := range pod.Spec.Overhead {
+			if value, ok := limits[name]; ok {
+				value.Add(quantity)
+				limits[name] = value
+			}
+		}
+	}
+	return requests, limits
+}
+
+func addResourceList(list, newList corev1.ResourceList) {
+	for name, quantity := range newList {
+		if value, ok := list[name]; ok {
+			value.Add(quantity)
+			list[name] = value
+		} else {
+			list[name] = quantity.DeepCopy()
+		}
+	}
+}
+
+func maxResourceList(list, newList corev1.ResourceList) {
+	for name, quantity := range newList {
+		if value, ok := list[name]; !ok || quantity.Cmp(value) > 0 {
+			list[name] = quantity.DeepCopy()
+		}
+	}
+}
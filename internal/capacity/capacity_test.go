@@ -0,0 +1,216 @@
+/*
+Copyright © 2021 Alex Krzos akrzos@redhat.com
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package capacity
+
+import (
+	"math"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestImageRegistry(t *testing.T) {
+	tests := []struct {
+		image string
+		want  string
+	}{
+		{"nginx", "docker.io"},
+		{"nginx:1.21", "docker.io"},
+		{"library/nginx:latest", "docker.io"},
+		{"docker.io/library/nginx", "docker.io"},
+		{"index.docker.io/library/nginx", "docker.io"},
+		{"registry-1.docker.io/library/nginx", "docker.io"},
+		{"quay.io/prometheus/prometheus:v2.26.0", "quay.io"},
+		{"Quay.IO/prometheus/prometheus", "quay.io"},
+		{"registry.example.com:5000/team/app:1.0", "registry.example.com:5000"},
+		{"registry:5000/app", "registry:5000"},
+		{"localhost/app", "localhost"},
+		{"localhost:5000/app:dev", "localhost:5000"},
+		{"nginx@sha256:0d17b565c37bcbd895e9d92315a05c1c3c9a29f762b011a10c54a66cd53c9b31", "docker.io"},
+		{"gcr.io/google-containers/pause@sha256:0d17b565c37bcbd895e9d92315a05c1c3c9a29f762b011a10c54a66cd53c9b31", "gcr.io"},
+	}
+	for _, tt := range tests {
+		if got := ImageRegistry(tt.image); got != tt.want {
+			t.Errorf("ImageRegistry(%q) = %q, want %q", tt.image, got, tt.want)
+		}
+	}
+}
+
+func testContainer(cpu, memory string) corev1.Container {
+	requests := corev1.ResourceList{}
+	if cpu != "" {
+		requests[corev1.ResourceCPU] = resource.MustParse(cpu)
+	}
+	if memory != "" {
+		requests[corev1.ResourceMemory] = resource.MustParse(memory)
+	}
+	return corev1.Container{Resources: corev1.ResourceRequirements{Requests: requests}}
+}
+
+func TestPodRequestsAndLimitsInitContainers(t *testing.T) {
+	tests := []struct {
+		name           string
+		containers     []corev1.Container
+		initContainers []corev1.Container
+		wantCPU        string
+		wantMemory     string
+	}{
+		{
+			name:       "app containers only",
+			containers: []corev1.Container{testContainer("250m", "256Mi"), testContainer("250m", "256Mi")},
+			wantCPU:    "500m",
+			wantMemory: "512Mi",
+		},
+		{
+			name:           "init container larger than the sum of app containers",
+			containers:     []corev1.Container{testContainer("250m", "256Mi"), testContainer("250m", "256Mi")},
+			initContainers: []corev1.Container{testContainer("2", "1Gi")},
+			wantCPU:        "2",
+			wantMemory:     "1Gi",
+		},
+		{
+			name:           "init container smaller than the sum of app containers",
+			containers:     []corev1.Container{testContainer("250m", "256Mi"), testContainer("250m", "256Mi")},
+			initContainers: []corev1.Container{testContainer("400m", "128Mi")},
+			wantCPU:        "500m",
+			wantMemory:     "512Mi",
+		},
+		{
+			name:           "largest init container per resource",
+			containers:     []corev1.Container{testContainer("100m", "1Gi")},
+			initContainers: []corev1.Container{testContainer("1", "64Mi"), testContainer("300m", "2Gi")},
+			wantCPU:        "1",
+			wantMemory:     "2Gi",
+		},
+		{
+			name:           "init containers are not summed",
+			containers:     []corev1.Container{testContainer("100m", "")},
+			initContainers: []corev1.Container{testContainer("600m", ""), testContainer("600m", "")},
+			wantCPU:        "600m",
+			wantMemory:     "0",
+		},
+	}
+	for _, tt := range tests {
+		pod := corev1.Pod{Spec: corev1.PodSpec{Containers: tt.containers, InitContainers: tt.initContainers}}
+		requests, _ := PodRequestsAndLimits(pod)
+		if want := resource.MustParse(tt.wantCPU); requests.Cpu().Cmp(want) != 0 {
+			t.Errorf("%s: cpu requests = %s, want %s", tt.name, requests.Cpu().String(), tt.wantCPU)
+		}
+		if want := resource.MustParse(tt.wantMemory); requests.Memory().Cmp(want) != 0 {
+			t.Errorf("%s: memory requests = %s, want %s", tt.name, requests.Memory().String(), tt.wantMemory)
+		}
+	}
+}
+
+func TestPodRequestsAndLimitsOverhead(t *testing.T) {
+	overhead := corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m")}
+	limited := testContainer("250m", "")
+	limited.Resources.Limits = corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m")}
+	tests := []struct {
+		name            string
+		container       corev1.Container
+		wantCPURequests string
+		wantCPULimits   string
+		wantLimitSet    bool
+	}{
+		{"without a cpu limit", testContainer("250m", ""), "350m", "0", false},
+		{"with a cpu limit", limited, "350m", "600m", true},
+	}
+	for _, tt := range tests {
+		pod := corev1.Pod{Spec: corev1.PodSpec{Containers: []corev1.Container{tt.container}, Overhead: overhead}}
+		requests, limits := PodRequestsAndLimits(pod)
+		if want := resource.MustParse(tt.wantCPURequests); requests.Cpu().Cmp(want) != 0 {
+			t.Errorf("%s: cpu requests = %s, want %s", tt.name, requests.Cpu().String(), tt.wantCPURequests)
+		}
+		if want := resource.MustParse(tt.wantCPULimits); limits.Cpu().Cmp(want) != 0 {
+			t.Errorf("%s: cpu limits = %s, want %s", tt.name, limits.Cpu().String(), tt.wantCPULimits)
+		}
+		if _, ok := limits[corev1.ResourceCPU]; ok != tt.wantLimitSet {
+			t.Errorf("%s: cpu limit set = %v, want %v", tt.name, ok, tt.wantLimitSet)
+		}
+	}
+}
+
+func TestUnitsOnlyApplyToTableValues(t *testing.T) {
+	defer SetUnits("")
+	if err := SetUnits(DecimalUnits); err != nil {
+		t.Fatal(err)
+	}
+	gib := ReadableMem(resource.MustParse("1Gi"))
+	if gib != 1 {
+		t.Errorf("ReadableMem(1Gi) = %v, want 1 with --units decimal", gib)
+	}
+	if got := TableMem(gib); math.Abs(got-1.073741824) > 1e-9 {
+		t.Errorf("TableMem(1) = %v, want 1.073741824 with --units decimal", got)
+	}
+	if err := SetUnits(BinaryUnits); err != nil {
+		t.Fatal(err)
+	}
+	gb := ReadableStorage(resource.MustParse("1G"))
+	if gb != 1 {
+		t.Errorf("ReadableStorage(1G) = %v, want 1 with --units binary", gb)
+	}
+	if got, want := TableStorage(gb), 1e9/(1<<30); math.Abs(got-want) > 1e-9 {
+		t.Errorf("TableStorage(1) = %v, want %v with --units binary", got, want)
+	}
+}
+
+func TestPodQOSClass(t *testing.T) {
+	guaranteed := testContainer("500m", "1Gi")
+	guaranteed.Resources.Limits = corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m"), corev1.ResourceMemory: resource.MustParse("1Gi")}
+	higherLimits := testContainer("500m", "1Gi")
+	higherLimits.Resources.Limits = corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1"), corev1.ResourceMemory: resource.MustParse("1Gi")}
+	cpuLimitOnly := testContainer("500m", "1Gi")
+	cpuLimitOnly.Resources.Limits = corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m")}
+	tests := []struct {
+		name           string
+		containers     []corev1.Container
+		initContainers []corev1.Container
+		want           corev1.PodQOSClass
+	}{
+		{"no requests or limits", []corev1.Container{testContainer("", "")}, nil, corev1.PodQOSBestEffort},
+		{"requests equal limits", []corev1.Container{guaranteed, guaranteed}, nil, corev1.PodQOSGuaranteed},
+		{"requests only", []corev1.Container{testContainer("500m", "")}, nil, corev1.PodQOSBurstable},
+		{"limits above requests", []corev1.Container{higherLimits}, nil, corev1.PodQOSBurstable},
+		{"missing memory limit", []corev1.Container{cpuLimitOnly}, nil, corev1.PodQOSBurstable},
+		{"one container without limits", []corev1.Container{guaranteed, testContainer("", "")}, nil, corev1.PodQOSBurstable},
+		{"init container without limits", []corev1.Container{guaranteed}, []corev1.Container{testContainer("100m", "")}, corev1.PodQOSBurstable},
+		{"best effort app with requesting init container", []corev1.Container{testContainer("", "")}, []corev1.Container{testContainer("100m", "")}, corev1.PodQOSBurstable},
+	}
+	for _, tt := range tests {
+		pod := corev1.Pod{Spec: corev1.PodSpec{Containers: tt.containers, InitContainers: tt.initContainers}}
+		if got := PodQOSClass(pod); got != tt.want {
+			t.Errorf("%s: PodQOSClass = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestFormatTaint(t *testing.T) {
+	tests := []struct {
+		taint corev1.Taint
+		want  string
+	}{
+		{corev1.Taint{Key: "dedicated", Value: "gpu", Effect: corev1.TaintEffectNoSchedule}, "dedicated=gpu:NoSchedule"},
+		{corev1.Taint{Key: "node-role.kubernetes.io/master", Effect: corev1.TaintEffectNoSchedule}, "node-role.kubernetes.io/master:NoSchedule"},
+		{corev1.Taint{Key: corev1.TaintNodeUnreachable, Effect: corev1.TaintEffectNoExecute}, "node.kubernetes.io/unreachable:NoExecute"},
+	}
+	for _, tt := range tests {
+		if got := FormatTaint(tt.taint); got != tt.want {
+			t.Errorf("FormatTaint(%v) = %q, want %q", tt.taint, got, tt.want)
+		}
+	}
+}
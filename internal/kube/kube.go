@@ -16,16 +16,103 @@ limitations under the License.
 package kube
 
 import (
+	"context"
+	"encoding/json"
+	"time"
+
 	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	metricsclientset "k8s.io/metrics/pkg/client/clientset/versioned"
 )
 
-func CreateClientSet(kubernetesConfigFlags *genericclioptions.ConfigFlags) (*kubernetes.Clientset, error) {
+// inCluster forces the in-cluster ServiceAccount config instead of the kubeconfig
+var inCluster bool
+
+// SetInCluster selects the in-cluster ServiceAccount config (token and CA mounted into the pod) over the kubeconfig
+func SetInCluster(enabled bool) {
+	inCluster = enabled
+}
+
+// retries and retryInterval bound the retries of List requests failing with a transient error
+var (
+	retries       int
+	retryInterval = time.Second
+)
+
+// SetRetries sets how many times a List request failing with a transient error is retried, waiting interval before the
+// first retry and doubling the wait after each
+func SetRetries(count int, interval time.Duration) {
+	retries = count
+	retryInterval = interval
+}
+
+// IsRetriable reports whether err is transient (Ex the apiserver restarting during an upgrade) and the request can be
+// retried. Errors such as RBAC forbidden are not retriable and fail fast.
+func IsRetriable(err error) bool {
+	return apierrors.IsServerTimeout(err) || apierrors.IsTimeout(err) || apierrors.IsTooManyRequests(err) ||
+		apierrors.IsServiceUnavailable(err) || utilnet.IsConnectionRefused(err) || utilnet.IsConnectionReset(err)
+}
+
+// withRetry calls request until it succeeds, fails with an error which is not retriable, the retries are exhausted or
+// ctx is done, returning the last error
+func withRetry(ctx context.Context, request func() error) error {
+	backoff := wait.Backoff{Duration: retryInterval, Factor: 2, Jitter: 0.1, Steps: retries}
+	err := request()
+	for err != nil && IsRetriable(err) && backoff.Steps > 0 {
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff.Step()):
+		}
+		err = request()
+	}
+	return err
+}
+
+// restConfig returns the in-cluster config when set, otherwise the kubeconfig config falling back to the in-cluster
+// config when no kubeconfig is present (Ex running as a Job with a ServiceAccount)
+func restConfig(kubernetesConfigFlags *genericclioptions.ConfigFlags) (*rest.Config, error) {
+	if inCluster {
+		config, err := rest.InClusterConfig()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read in-cluster config")
+		}
+		return config, nil
+	}
+	rawConfig, err := kubernetesConfigFlags.ToRawKubeConfigLoader().RawConfig()
+	noServerFlag := kubernetesConfigFlags.APIServer == nil || *kubernetesConfigFlags.APIServer == ""
+	if err == nil && clientcmdapi.IsConfigEmpty(&rawConfig) && noServerFlag {
+		config, err := rest.InClusterConfig()
+		if err == rest.ErrNotInCluster {
+			return nil, errors.New("no kubeconfig found and not running in a cluster, set --kubeconfig or KUBECONFIG")
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "no kubeconfig found and failed to read in-cluster config")
+		}
+		return config, nil
+	}
 	config, err := kubernetesConfigFlags.ToRESTConfig()
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to read kubeconfig")
 	}
+	return config, nil
+}
+
+func CreateClientSet(kubernetesConfigFlags *genericclioptions.ConfigFlags) (*kubernetes.Clientset, error) {
+	config, err := restConfig(kubernetesConfigFlags)
+	if err != nil {
+		return nil, err
+	}
 
 	clientset, err := kubernetes.NewForConfig(config)
 	if err != nil {
@@ -34,3 +121,132 @@ func CreateClientSet(kubernetesConfigFlags *genericclioptions.ConfigFlags) (*kub
 
 	return clientset, nil
 }
+
+func CreateMetricsClientSet(kubernetesConfigFlags *genericclioptions.ConfigFlags) (*metricsclientset.Clientset, error) {
+	config, err := restConfig(kubernetesConfigFlags)
+	if err != nil {
+		return nil, err
+	}
+
+	metricsClientset, err := metricsclientset.NewForConfig(config)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create metrics clientset")
+	}
+
+	return metricsClientset, nil
+}
+
+// CreateDynamicClient creates a client for APIs without a typed clientset, such as CRDs installed by add-ons
+func CreateDynamicClient(kubernetesConfigFlags *genericclioptions.ConfigFlags) (dynamic.Interface, error) {
+	config, err := restConfig(kubernetesConfigFlags)
+	if err != nil {
+		return nil, err
+	}
+
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create dynamic client")
+	}
+
+	return dynamicClient, nil
+}
+
+// ListPods lists pods chunkSize at a time, following the continue token until every page is merged into one list. A
+// chunkSize of 0 or less lists every pod in a single request. When the continue token expires (410 Gone) part way
+// through, the pages collected so far are dropped and the pods are listed again in a single request. Each request is
+// retried on transient errors as set by SetRetries.
+func ListPods(ctx context.Context, clientset kubernetes.Interface, namespace string, listOptions metav1.ListOptions, chunkSize int64) (*corev1.PodList, error) {
+	podList := &corev1.PodList{}
+	if chunkSize > 0 {
+		listOptions.Limit = chunkSize
+	}
+	for {
+		var pods *corev1.PodList
+		err := withRetry(ctx, func() (err error) {
+			pods, err = clientset.CoreV1().Pods(namespace).List(ctx, listOptions)
+			return err
+		})
+		if err != nil && apierrors.IsResourceExpired(err) && listOptions.Continue != "" {
+			podList.Items = nil
+			listOptions.Continue = ""
+			listOptions.Limit = 0
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		podList.ListMeta = pods.ListMeta
+		podList.Items = append(podList.Items, pods.Items...)
+		if pods.Continue == "" {
+			return podList, nil
+		}
+		listOptions.Continue = pods.Continue
+	}
+}
+
+// ListNodes lists nodes chunkSize at a time, following the continue token until every page is merged into one list. A
+// chunkSize of 0 or less lists every node in a single request. When the continue token expires (410 Gone) part way
+// through, the pages collected so far are dropped and the nodes are listed again in a single request. Each request is
+// retried on transient errors as set by SetRetries.
+func ListNodes(ctx context.Context, clientset kubernetes.Interface, listOptions metav1.ListOptions, chunkSize int64) (*corev1.NodeList, error) {
+	nodeList := &corev1.NodeList{}
+	if chunkSize > 0 {
+		listOptions.Limit = chunkSize
+	}
+	for {
+		var nodes *corev1.NodeList
+		err := withRetry(ctx, func() (err error) {
+			nodes, err = clientset.CoreV1().Nodes().List(ctx, listOptions)
+			return err
+		})
+		if err != nil && apierrors.IsResourceExpired(err) && listOptions.Continue != "" {
+			nodeList.Items = nil
+			listOptions.Continue = ""
+			listOptions.Limit = 0
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		nodeList.ListMeta = nodes.ListMeta
+		nodeList.Items = append(nodeList.Items, nodes.Items...)
+		if nodes.Continue == "" {
+			return nodeList, nil
+		}
+		listOptions.Continue = nodes.Continue
+	}
+}
+
+// statsSummary is the part of the kubelet stats summary (/stats/summary) holding the ephemeral storage used by each pod
+type statsSummary struct {
+	Pods []struct {
+		EphemeralStorage *struct {
+			UsedBytes *uint64 `json:"usedBytes"`
+		} `json:"ephemeral-storage"`
+	} `json:"pods"`
+}
+
+// NodeEphemeralUsage returns the ephemeral storage used by the pods on a node, the sum of the pod usedBytes of the
+// kubelet stats summary read through the api server node proxy. The request is retried on transient errors as set by
+// SetRetries.
+func NodeEphemeralUsage(ctx context.Context, clientset kubernetes.Interface, nodeName string) (resource.Quantity, error) {
+	var summaryData []byte
+	err := withRetry(ctx, func() (err error) {
+		summaryData, err = clientset.CoreV1().RESTClient().Get().Resource("nodes").Name(nodeName).SubResource("proxy").Suffix("stats/summary").DoRaw(ctx)
+		return err
+	})
+	if err != nil {
+		return resource.Quantity{}, err
+	}
+	summary := statsSummary{}
+	if err := json.Unmarshal(summaryData, &summary); err != nil {
+		return resource.Quantity{}, errors.Wrapf(err, "failed to parse stats summary of node %s", nodeName)
+	}
+	var usedBytes int64
+	for _, pod := range summary.Pods {
+		if pod.EphemeralStorage != nil && pod.EphemeralStorage.UsedBytes != nil {
+			usedBytes += int64(*pod.EphemeralStorage.UsedBytes)
+		}
+	}
+	return *resource.NewQuantity(usedBytes, resource.BinarySI), nil
+}
@@ -0,0 +1,109 @@
+/*
+Copyright © 2021 Alex Krzos akrzos@redhat.com
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package kube
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// failingClientset returns a clientset whose pod List requests fail with the errors in order before succeeding, and
+// a pointer to the number of List requests made
+func failingClientset(errs ...error) (*fake.Clientset, *int) {
+	clientset := fake.NewSimpleClientset(&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "app-0", Namespace: "default"}})
+	requests := 0
+	clientset.PrependReactor("list", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		requests++
+		if requests <= len(errs) {
+			return true, nil, errs[requests-1]
+		}
+		return false, nil, nil
+	})
+	return clientset, &requests
+}
+
+func TestListPodsRetries(t *testing.T) {
+	defer SetRetries(0, time.Second)
+	SetRetries(3, time.Millisecond)
+	pods := schema.GroupResource{Resource: "pods"}
+
+	clientset, requests := failingClientset(apierrors.NewServerTimeout(pods, "list", 1), apierrors.NewTooManyRequests("slow down", 1))
+	podList, err := ListPods(context.Background(), clientset, "", metav1.ListOptions{}, 0)
+	if err != nil {
+		t.Fatalf("ListPods after transient errors: %v", err)
+	}
+	if len(podList.Items) != 1 || *requests != 3 {
+		t.Errorf("ListPods returned %d pods after %d requests, want 1 pod after 3 requests", len(podList.Items), *requests)
+	}
+
+	clientset, requests = failingClientset(apierrors.NewForbidden(pods, "", nil))
+	if _, err := ListPods(context.Background(), clientset, "", metav1.ListOptions{}, 0); !apierrors.IsForbidden(err) {
+		t.Errorf("ListPods error = %v, want forbidden", err)
+	}
+	if *requests != 1 {
+		t.Errorf("forbidden ListPods made %d requests, want 1 without retrying", *requests)
+	}
+
+	timeout := apierrors.NewServerTimeout(pods, "list", 1)
+	clientset, requests = failingClientset(timeout, timeout, timeout, timeout, timeout)
+	if _, err := ListPods(context.Background(), clientset, "", metav1.ListOptions{}, 0); !apierrors.IsServerTimeout(err) {
+		t.Errorf("ListPods error = %v, want server timeout once retries are exhausted", err)
+	}
+	if *requests != 4 {
+		t.Errorf("ListPods made %d requests, want 4 with --retries 3", *requests)
+	}
+}
+
+func TestNodeEphemeralUsage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/nodes/worker-0/proxy/stats/summary" {
+			http.NotFound(w, r)
+			return
+		}
+		// The second pod has no ephemeral-storage stats yet (Ex just started)
+		fmt.Fprint(w, `{"node":{"nodeName":"worker-0"},"pods":[{"ephemeral-storage":{"usedBytes":1073741824}},{},{"ephemeral-storage":{"usedBytes":536870912}}]}`)
+	}))
+	defer server.Close()
+	clientset, err := kubernetes.NewForConfig(&rest.Config{Host: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	usage, err := NodeEphemeralUsage(context.Background(), clientset, "worker-0")
+	if err != nil {
+		t.Fatalf("NodeEphemeralUsage: %v", err)
+	}
+	if usage.Value() != 1610612736 {
+		t.Errorf("usage = %s, want 1536Mi", usage.String())
+	}
+	if _, err := NodeEphemeralUsage(context.Background(), clientset, "worker-1"); err == nil {
+		t.Error("NodeEphemeralUsage of a node without a summary succeeded, want an error")
+	}
+}
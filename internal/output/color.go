@@ -0,0 +1,70 @@
+/*
+Copyright © 2021 Alex Krzos akrzos@redhat.com
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package output
+
+import (
+	"fmt"
+	"io"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// ANSI color escape codes, colorDefault is the same length as the other colors so every cell of a colored column
+// carries equally long escape codes and the tabwriter keeps the columns aligned
+const (
+	colorRed     = "\x1b[31m"
+	colorYellow  = "\x1b[33m"
+	colorDefault = "\x1b[39m"
+	colorReset   = "\x1b[0m"
+)
+
+// colorize wraps text in the color escape codes when color output is enabled
+func colorize(text, color string, displayOptions DisplayOptions) string {
+	if !displayOptions.Color {
+		return text
+	}
+	return color + text + colorReset
+}
+
+// utilizationColor returns red at or above the critical threshold and yellow at or above the warning threshold
+func utilizationColor(utilization float64, displayOptions DisplayOptions) string {
+	switch {
+	case utilization >= displayOptions.CritThreshold:
+		return colorRed
+	case utilization >= displayOptions.WarnThreshold:
+		return colorYellow
+	}
+	return colorDefault
+}
+
+// printUtilizationHeaders prints the utilization column group title, the cpu and memory cells are padded with the
+// escape codes of their colored data cells
+func printUtilizationHeaders(w io.Writer, displayOptions DisplayOptions) {
+	fmt.Fprintf(w, "%s\t%s\t\t", colorize("UTILIZATION (%)", colorDefault, displayOptions), colorize("", colorDefault, displayOptions))
+}
+
+func printUtilizationSubHeaders(w io.Writer, displayOptions DisplayOptions) {
+	fmt.Fprintf(w, "%s\t%s\tPods\t", colorize("CPU", colorDefault, displayOptions), colorize("Memory", colorDefault, displayOptions))
+}
+
+// printRequestsUtilization prints a cpu or memory requests utilization percentage colored by the thresholds
+func printRequestsUtilization(w io.Writer, utilization float64, allocatable resource.Quantity, displayOptions DisplayOptions) {
+	if allocatable.IsZero() {
+		fmt.Fprintf(w, "%s\t", colorize("-", colorDefault, displayOptions))
+		return
+	}
+	fmt.Fprintf(w, "%s\t", colorize(fmt.Sprintf("%.1f", utilization), utilizationColor(utilization, displayOptions), displayOptions))
+}
@@ -0,0 +1,160 @@
+/*
+Copyright © 2021 Alex Krzos akrzos@redhat.com
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package output
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+const customColumnsPrefix = customColumnsDisplay + "="
+
+// nameField is the custom-columns field path of the row name (Ex the node name)
+const nameField = "name"
+
+// customColumnsTypes are the row data structures by command name, fields of a custom-columns spec are checked against
+// them. Commands without an entry do not support custom-columns output.
+var customColumnsTypes = map[string]reflect.Type{
+	"cluster":    reflect.TypeOf(ClusterCapacityData{}),
+	"node-role":  reflect.TypeOf(ClusterCapacityData{}),
+	"zone":       reflect.TypeOf(ClusterCapacityData{}),
+	"node":       reflect.TypeOf(NodeCapacityData{}),
+	"namespace":  reflect.TypeOf(NamespaceCapacityData{}),
+	"size":       reflect.TypeOf(ClusterSizeData{}),
+	"registry":   reflect.TypeOf(RegistryCapacityData{}),
+	"images":     reflect.TypeOf(ImageCapacityData{}),
+	"storage":    reflect.TypeOf(StorageCapacityData{}),
+	"pod":        reflect.TypeOf(PodCapacityData{}),
+	"deployment": reflect.TypeOf(DeploymentCapacityData{}),
+	"workloads":  reflect.TypeOf(WorkloadCapacityData{}),
+	"window":     reflect.TypeOf(WindowCapacityData{}),
+	"diagnose":   reflect.TypeOf(PodDiagnosisData{}),
+}
+
+// CustomColumn is a column of custom-columns output, Field is the name of the data structure field shown under Header
+// or "name" for the row name
+type CustomColumn struct {
+	Header string
+	Field  string
+}
+
+// ParseFormat splits an --output value into the display format and the columns of a custom-columns=<spec> value, other
+// formats are returned unchanged without columns
+func ParseFormat(format string) (string, []CustomColumn, error) {
+	if !strings.HasPrefix(format, customColumnsPrefix) {
+		return format, nil, nil
+	}
+	columns, err := parseCustomColumns(strings.TrimPrefix(format, customColumnsPrefix))
+	return customColumnsDisplay, columns, err
+}
+
+// parseCustomColumns parses a comma separated list of HEADER:.Field columns (Ex NAME:.name,CPU:.TotalAvailableCPUCores)
+func parseCustomColumns(spec string) ([]CustomColumn, error) {
+	if spec == "" {
+		return nil, errors.New("custom-columns format specified but no custom columns given")
+	}
+	var columns []CustomColumn
+	for _, column := range strings.Split(spec, ",") {
+		parts := strings.SplitN(column, ":", 2)
+		if len(parts) != 2 || parts[0] == "" || strings.TrimPrefix(parts[1], ".") == "" {
+			return nil, errors.Errorf("unexpected custom-columns spec \"%s\", expected <header>:<field>", column)
+		}
+		field := strings.TrimPrefix(parts[1], ".")
+		if strings.Contains(field, ".") {
+			return nil, errors.Errorf("custom-columns field \"%s\" is invalid, nested fields are not supported", parts[1])
+		}
+		columns = append(columns, CustomColumn{Header: parts[0], Field: field})
+	}
+	return columns, nil
+}
+
+// validateCustomColumns checks a custom-columns spec against the row data structure of the command
+func validateCustomColumns(cmd cobra.Command, spec string) error {
+	columns, err := parseCustomColumns(spec)
+	if err != nil {
+		return err
+	}
+	dataType, ok := customColumnsTypes[cmd.Name()]
+	if !ok {
+		return errors.Errorf("custom-columns output is not supported by the %s command", cmd.Name())
+	}
+	for _, column := range columns {
+		if column.Field == nameField {
+			continue
+		}
+		if _, ok := dataType.FieldByName(column.Field); !ok {
+			return errors.Errorf("custom-columns field \"%s\" is not a field of %s", column.Field, dataType.Name())
+		}
+	}
+	return nil
+}
+
+// writeCustomColumns writes only the custom-columns fields of each row as a table, fields the row does not have (Ex
+// node fields of node --group-by rows) show as <none>
+func writeCustomColumns(out io.Writer, rows []metricsRow, displayOptions DisplayOptions) {
+	w := new(tabwriter.Writer)
+	w.Init(out, 0, 5, 1, ' ', 0)
+	if displayOptions.Headers {
+		for _, column := range displayOptions.CustomColumns {
+			fmt.Fprintf(w, "%s\t", column.Header)
+		}
+		fmt.Fprintln(w, "")
+	}
+	for _, row := range rows {
+		for _, column := range displayOptions.CustomColumns {
+			if column.Field == nameField {
+				fmt.Fprintf(w, "%s\t", row.labelValue)
+				continue
+			}
+			fmt.Fprintf(w, "%s\t", customColumnValue(row.data.FieldByName(column.Field), displayOptions))
+		}
+		fmt.Fprintln(w, "")
+	}
+	w.Flush()
+}
+
+// customColumnValue formats a field the way the table output does, quantities in the default format and readable
+// values with --precision decimals
+func customColumnValue(value reflect.Value, displayOptions DisplayOptions) string {
+	if !value.IsValid() {
+		return "<none>"
+	}
+	switch v := value.Interface().(type) {
+	case resource.Quantity:
+		return v.String()
+	case float64:
+		return readable(v, displayOptions)
+	case sets.String:
+		return strings.Join(v.List(), ",")
+	case []string:
+		return strings.Join(v, ",")
+	case *time.Time:
+		if v == nil {
+			return "<none>"
+		}
+		return v.Format(time.RFC3339)
+	}
+	return fmt.Sprint(value.Interface())
+}
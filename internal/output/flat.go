@@ -0,0 +1,57 @@
+/*
+Copyright © 2021 Alex Krzos akrzos@redhat.com
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package output
+
+import (
+	"reflect"
+	"strings"
+)
+
+// flatData returns a single level object of the numeric and boolean fields of a capacity data struct keyed by snake_case
+// field name (Ex total_allocatable_cpu_cores) for dashboards. The "Human" readable values are used in place of the
+// quantities they duplicate, quantities without one (Ex TotalCapacityPods) are in base units, maps are omitted.
+func flatData(data reflect.Value) map[string]interface{} {
+	flat := make(map[string]interface{})
+	dataType := data.Type()
+	for i := 0; i < dataType.NumField(); i++ {
+		field := dataType.Field(i)
+		switch {
+		case field.Type == quantityType:
+			if hasReadable(dataType, field.Name) {
+				continue
+			}
+			flat[flatName(field.Name)] = metricValue(data.Field(i), "")
+		case field.Type.Kind() == reflect.Int, field.Type.Kind() == reflect.Float64, field.Type.Kind() == reflect.Bool:
+			flat[flatName(field.Name)] = data.Field(i).Interface()
+		}
+	}
+	return flat
+}
+
+// hasReadable is true when a quantity has a "Human" readable field (Ex TotalCapacityCPU and TotalCapacityCPUCores)
+func hasReadable(dataType reflect.Type, fieldName string) bool {
+	for _, suffix := range []string{"Cores", "GiB", "GB", "Count"} {
+		if _, ok := dataType.FieldByName(fieldName + suffix); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// flatName converts a Go field name into a snake_case key, GiB is kept as one word (Ex total_capacity_memory_gib)
+func flatName(fieldName string) string {
+	return metricName(strings.Replace(fieldName, "GiB", "Gib", 1))
+}
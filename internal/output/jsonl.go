@@ -0,0 +1,61 @@
+/*
+Copyright © 2021 Alex Krzos akrzos@redhat.com
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// writeRows writes row based output as openmetrics, json lines or custom-columns
+func writeRows(out io.Writer, displayOptions DisplayOptions, subsystem, labelName string, rows []metricsRow) {
+	switch displayOptions.Format {
+	case jsonlDisplay:
+		writeJSONLines(out, labelName, rows)
+		return
+	case customColumnsDisplay:
+		writeCustomColumns(out, rows, displayOptions)
+		return
+	}
+	writeOpenMetrics(out, subsystem, labelName, rows)
+}
+
+// writeJSONLines writes each row as a single line json object, the row's label value is included under labelName
+func writeJSONLines(out io.Writer, labelName string, rows []metricsRow) {
+	for _, row := range rows {
+		line, err := json.Marshal(row.data.Interface())
+		if err != nil {
+			fmt.Fprintln(out, err)
+			return
+		}
+		if labelName != "" {
+			key, err := json.Marshal(map[string]string{labelName: row.labelValue})
+			if err != nil {
+				fmt.Fprintln(out, err)
+				return
+			}
+			if !bytes.Equal(line, []byte("{}")) {
+				key = append(key[:len(key)-1], ',')
+				line = append(key, line[1:]...)
+			} else {
+				line = key
+			}
+		}
+		fmt.Fprintln(out, string(line))
+	}
+}
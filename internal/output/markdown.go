@@ -0,0 +1,148 @@
+/*
+Copyright © 2021 Alex Krzos akrzos@redhat.com
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package output
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// markdownWriter buffers the tab separated table rows of a display function and renders them as GitHub-flavored
+// markdown tables on Flush. Lines without a tab (Ex the size section titles) end the current table and are rendered as
+// a bold title, the header rows of the table that follows are counted again.
+type markdownWriter struct {
+	out        io.Writer
+	headerRows int
+	buf        bytes.Buffer
+}
+
+func (m *markdownWriter) Write(p []byte) (int, error) {
+	return m.buf.Write(p)
+}
+
+func (m *markdownWriter) Flush() error {
+	var rows [][]string
+	for _, line := range strings.Split(strings.TrimSuffix(m.buf.String(), "\n"), "\n") {
+		if line == "" {
+			continue
+		}
+		if !strings.Contains(line, "\t") {
+			writeMarkdownTable(m.out, rows, m.headerRows)
+			rows = nil
+			fmt.Fprintf(m.out, "**%s**\n\n", strings.TrimSpace(line))
+			continue
+		}
+		rows = append(rows, strings.Split(line, "\t"))
+	}
+	writeMarkdownTable(m.out, rows, m.headerRows)
+	m.buf.Reset()
+	return nil
+}
+
+// writeMarkdownTable writes rows as a markdown table. The header rows are merged into one, an empty cell of a header
+// row other than the last belongs to the column group started by the cell before it (Ex "PODS Capacity"). Numeric
+// columns are right aligned.
+func writeMarkdownTable(out io.Writer, rows [][]string, headerRows int) {
+	if len(rows) == 0 {
+		return
+	}
+	if headerRows > len(rows) {
+		headerRows = len(rows)
+	}
+	columns := 0
+	for _, row := range rows {
+		for i := len(row) - 1; i >= columns; i-- {
+			if strings.TrimSpace(row[i]) != "" {
+				columns = i + 1
+				break
+			}
+		}
+	}
+
+	header := make([]string, columns)
+	for r, row := range rows[:headerRows] {
+		group := ""
+		for i := 0; i < columns; i++ {
+			cell := markdownCell(row, i)
+			if cell != "" {
+				group = cell
+			} else if r < headerRows-1 {
+				cell = group
+			}
+			if cell != "" {
+				header[i] = strings.TrimSpace(header[i] + " " + cell)
+			}
+		}
+	}
+
+	data := rows[headerRows:]
+	dividers := make([]string, columns)
+	for i := range dividers {
+		dividers[i] = "---"
+		numeric := false
+		for _, row := range data {
+			cell := markdownCell(row, i)
+			if cell == "" || cell == "-" {
+				continue
+			}
+			if !isNumericCell(cell) {
+				numeric = false
+				break
+			}
+			numeric = true
+		}
+		if numeric {
+			dividers[i] = "--:"
+		}
+	}
+
+	writeMarkdownRow(out, header)
+	writeMarkdownRow(out, dividers)
+	for _, row := range data {
+		cells := make([]string, columns)
+		for i := range cells {
+			cells[i] = markdownCell(row, i)
+		}
+		writeMarkdownRow(out, cells)
+	}
+	fmt.Fprintln(out, "")
+}
+
+// markdownCell returns the trimmed cell i of a row with pipes escaped, or an empty string past the end of the row
+func markdownCell(row []string, i int) string {
+	if i >= len(row) {
+		return ""
+	}
+	return strings.ReplaceAll(strings.TrimSpace(row[i]), "|", "\\|")
+}
+
+// isNumericCell reports whether a cell is a number or a resource quantity (Ex 2.5 or 16Gi)
+func isNumericCell(cell string) bool {
+	if _, err := strconv.ParseFloat(cell, 64); err == nil {
+		return true
+	}
+	_, err := resource.ParseQuantity(cell)
+	return err == nil
+}
+
+func writeMarkdownRow(out io.Writer, cells []string) {
+	fmt.Fprintf(out, "| %s |\n", strings.Join(cells, " | "))
+}
@@ -0,0 +1,133 @@
+/*
+Copyright © 2021 Alex Krzos akrzos@redhat.com
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package output
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"time"
+	"unicode"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// metricsRow is a single labeled instance of a capacity data struct
+type metricsRow struct {
+	labelValue string
+	data       reflect.Value
+}
+
+var quantityType = reflect.TypeOf(resource.Quantity{})
+
+// writeOpenMetrics emits one gauge family per numeric field of the capacity data structs. Quantities are exported
+// in base units (cores and bytes) and the "Human" readable float fields are skipped since they duplicate them.
+func writeOpenMetrics(w io.Writer, subsystem string, labelName string, rows []metricsRow) {
+	writeMetricFamilies(w, subsystem, labelName, rows)
+	fmt.Fprintln(w, "# EOF")
+}
+
+// writeMetricFamilies emits the gauge families of writeOpenMetrics without the terminating EOF, so output made of
+// several kinds of rows (Ex cronjobs and jobs) can be combined into one exposition
+func writeMetricFamilies(w io.Writer, subsystem string, labelName string, rows []metricsRow) {
+	if len(rows) == 0 {
+		return
+	}
+	timestamp := float64(time.Now().UnixNano()) / 1e9
+	dataType := rows[0].data.Type()
+	for i := 0; i < dataType.NumField(); i++ {
+		field := dataType.Field(i)
+		unit := ""
+		switch {
+		case field.Type == quantityType:
+			switch {
+			case strings.Contains(field.Name, "CPU"):
+				unit = "cores"
+			case strings.Contains(field.Name, "Memory"), strings.Contains(field.Name, "Storage"):
+				unit = "bytes"
+			}
+		case field.Type.Kind() == reflect.Int && isQuantityCount(dataType, field.Name):
+			// Readable counts of a quantity (Ex TotalCapacityGPUCount) duplicate the quantity
+			continue
+		case field.Type.Kind() == reflect.Int, field.Type.Kind() == reflect.Bool:
+		default:
+			continue
+		}
+		name := "kubesize_" + subsystem + "_" + metricName(strings.TrimPrefix(field.Name, "Total"))
+		if unit != "" {
+			name += "_" + unit
+		}
+		fmt.Fprintf(w, "# TYPE %s gauge\n", name)
+		if unit != "" {
+			fmt.Fprintf(w, "# UNIT %s %s\n", name, unit)
+		}
+		fmt.Fprintf(w, "# HELP %s %s\n", name, field.Name)
+		for _, row := range rows {
+			labels := ""
+			if labelName != "" {
+				labels = fmt.Sprintf("{%s=\"%s\"}", labelName, escapeLabelValue(row.labelValue))
+			}
+			fmt.Fprintf(w, "%s%s %v %.3f\n", name, labels, metricValue(row.data.Field(i), unit), timestamp)
+		}
+	}
+}
+
+func isQuantityCount(dataType reflect.Type, fieldName string) bool {
+	if !strings.HasSuffix(fieldName, "Count") {
+		return false
+	}
+	quantityField, ok := dataType.FieldByName(strings.TrimSuffix(fieldName, "Count"))
+	return ok && quantityField.Type == quantityType
+}
+
+func metricValue(v reflect.Value, unit string) interface{} {
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			return 1
+		}
+		return 0
+	case reflect.Int:
+		return v.Int()
+	}
+	quantity := v.Interface().(resource.Quantity)
+	if unit == "cores" {
+		return float64(quantity.MilliValue()) / 1000
+	}
+	return quantity.Value()
+}
+
+// metricName converts a Go field name such as AllocatableCPU into allocatable_cpu
+func metricName(fieldName string) string {
+	runes := []rune(fieldName)
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) && i > 0 {
+			prevLower := unicode.IsLower(runes[i-1])
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if prevLower || (unicode.IsUpper(runes[i-1]) && nextLower) {
+				b.WriteRune('_')
+			}
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
+
+func escapeLabelValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
@@ -18,13 +18,20 @@ package output
 import (
 	"encoding/json"
 	"fmt"
-	"os"
+	"io"
+	"math"
+	"reflect"
 	"sort"
+	"strconv"
 	"strings"
 	"text/tabwriter"
+	"time"
 
+	"github.com/akrzos/kubeSize/internal/capacity"
 	"github.com/spf13/cobra"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/duration"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"sigs.k8s.io/yaml"
 )
@@ -33,8 +40,88 @@ const (
 	tableDisplay string = "table"
 	jsonDisplay  string = "json"
 	yamlDisplay  string = "yaml"
+	jsonlDisplay string = "jsonl"
+
+	markdownDisplay      string = "markdown"
+	openMetricsDisplay   string = "openmetrics"
+	customColumnsDisplay string = "custom-columns"
 )
 
+// DisplayOptions are the output settings used when rendering capacity data
+type DisplayOptions struct {
+	Format           string
+	Default          bool
+	Precision        int
+	Headers          bool
+	Compact          bool
+	Wide             bool
+	Flat             bool
+	EphemeralStorage bool
+	GPU              bool
+	Reserved         bool
+	LimitsAvailable  bool
+	Hugepages        bool
+	Utilization      bool
+	Ratios           bool
+	Color            bool
+	WarnThreshold    float64
+	CritThreshold    float64
+	FullThreshold    float64
+	Usage            bool
+	SortByRole       bool
+	AllNamespaces    bool
+	MinPods          int
+	Schedulable      bool
+	PodReservation   bool
+	Taints           bool
+	Stats            bool
+	Quota            bool
+	ClusterPercent   bool
+	NodeSpread       bool
+	Efficiency       bool
+	Age              bool
+	Versions         bool
+	Explain          bool
+	Phases           bool
+	QOS              bool
+	PodReadiness     bool
+
+	// ExtraResources are the --extra-resources names, one table column group each
+	ExtraResources []string
+
+	// WorkloadAvailable adds the available capacity of nodes accepting general workloads to the Schedulable columns
+	WorkloadAvailable bool
+
+	// Metadata wraps json and yaml output in an Envelope when set
+	Metadata *Metadata
+
+	// CustomColumns are the columns of custom-columns output
+	CustomColumns []CustomColumn
+
+	// hugepageSizes are the page sizes discovered across the displayed data, one table column group each
+	hugepageSizes []string
+}
+
+// Metadata identifies the cluster and time json and yaml output was collected
+type Metadata struct {
+	Context   string
+	Server    string
+	Timestamp time.Time
+}
+
+// Envelope wraps json and yaml output so documents from multiple clusters can be told apart
+type Envelope struct {
+	Metadata Metadata
+	Data     interface{}
+}
+
+// ResourceTriple is the accounting for a single --extra-resources extended resource (Ex example.com/fpga)
+type ResourceTriple struct {
+	Capacity    resource.Quantity
+	Allocatable resource.Quantity
+	Requests    resource.Quantity
+}
+
 // Available = allocatable - (scheduled aka non-term pod or requests.cpu/memory)
 type ClusterCapacityData struct {
 	TotalNodeCount                     int
@@ -43,6 +130,21 @@ type ClusterCapacityData struct {
 	TotalUnschedulableNodeCount        int
 	TotalPodCount                      int
 	TotalNonTermPodCount               int
+	RunningPodCount                    int
+	PendingPodCount                    int
+	SucceededPodCount                  int
+	FailedPodCount                     int
+	GuaranteedPodCount                 int
+	BurstablePodCount                  int
+	BestEffortPodCount                 int
+	GuaranteedRequestsCPU              resource.Quantity
+	GuaranteedRequestsCPUCores         float64
+	GuaranteedRequestsMemory           resource.Quantity
+	GuaranteedRequestsMemoryGiB        float64
+	BurstableRequestsCPU               resource.Quantity
+	BurstableRequestsCPUCores          float64
+	BurstableRequestsMemory            resource.Quantity
+	BurstableRequestsMemoryGiB         float64
 	TotalCapacityPods                  resource.Quantity
 	TotalCapacityCPU                   resource.Quantity
 	TotalCapacityCPUCores              float64
@@ -76,6 +178,50 @@ type ClusterCapacityData struct {
 	TotalLimitsEphemeralStorageGB      float64
 	TotalAvailableEphemeralStorage     resource.Quantity
 	TotalAvailableEphemeralStorageGB   float64
+	TotalReservedCPU                   resource.Quantity
+	TotalReservedCPUCores              float64
+	TotalReservedMemory                resource.Quantity
+	TotalReservedMemoryGiB             float64
+	TotalReservedEphemeralStorage      resource.Quantity
+	TotalReservedEphemeralStorageGB    float64
+	TotalLimitsAvailableCPU            resource.Quantity
+	TotalLimitsAvailableCPUCores       float64
+	TotalLimitsAvailableMemory         resource.Quantity
+	TotalLimitsAvailableMemoryGiB      float64
+	TotalCapacityGPU                   resource.Quantity
+	TotalCapacityGPUCount              int
+	TotalAllocatableGPU                resource.Quantity
+	TotalAllocatableGPUCount           int
+	TotalRequestsGPU                   resource.Quantity
+	TotalRequestsGPUCount              int
+	TotalAvailableGPU                  resource.Quantity
+	TotalAvailableGPUCount             int
+	Hugepages                          map[string]*HugepagesCapacityData
+	ExtraResources                     map[string]*ResourceTriple
+	RequestsCPUUtilization             float64
+	RequestsMemoryUtilization          float64
+	PodUtilization                     float64
+	RequestsCPUCapacityPct             float64
+	RequestsMemoryCapacityPct          float64
+	CPUOvercommitted                   bool
+	CPUOvercommitRatio                 float64
+	MemoryOvercommitted                bool
+	MemoryOvercommitRatio              float64
+	TotalUsageCPU                      resource.Quantity
+	TotalUsageCPUCores                 float64
+	TotalUsageMemory                   resource.Quantity
+	TotalUsageMemoryGiB                float64
+	TotalUsageEphemeralStorage         resource.Quantity
+	TotalUsageEphemeralStorageGB       float64
+	SchedulableNodeCount               int
+	SchedulableAllocatableCPU          resource.Quantity
+	SchedulableAllocatableCPUCores     float64
+	SchedulableAllocatableMemory       resource.Quantity
+	SchedulableAllocatableMemoryGiB    float64
+	WorkloadAvailableCPU               resource.Quantity
+	WorkloadAvailableCPUCores          float64
+	WorkloadAvailableMemory            resource.Quantity
+	WorkloadAvailableMemoryGiB         float64
 }
 
 type ClusterSizeData struct {
@@ -91,15 +237,16 @@ type ClusterSizeData struct {
 	ResourceQuota      int
 	NetworkPolicy      int
 	// Workloads APIs
-	Container         int
-	Pod               int
-	ReplicaSet        int
-	ReplicaController int
-	Deployment        int
-	Daemonset         int
-	StatefulSet       int
-	CronJob           int
-	Job               int
+	Container               int
+	Pod                     int
+	ReplicaSet              int
+	ReplicaController       int
+	Deployment              int
+	Daemonset               int
+	StatefulSet             int
+	CronJob                 int
+	Job                     int
+	HorizontalPodAutoscaler int
 	// Service APIs
 	EndPoints int
 	Service   int
@@ -115,14 +262,36 @@ type ClusterSizeData struct {
 	LimitRange          int
 	PodDisruptionBudget int
 	PodSecurityPolicy   int
+	// Add-on APIs
+	VerticalPodAutoscaler int
+	MetricsAPI            bool
 }
 
 type NodeCapacityData struct {
 	TotalPodCount                      int
 	TotalNonTermPodCount               int
+	TotalContainerCount                int
+	TotalInitContainerCount            int
+	RunningPodCount                    int
+	PendingPodCount                    int
+	SucceededPodCount                  int
+	FailedPodCount                     int
+	ReadyPodCount                      int
+	NonTermWorkloadPodCount            int
 	Roles                              sets.String
+	Taints                             []string
+	CreationTimestamp                  *time.Time
+	KubeletVersion                     string
+	KernelVersion                      string
+	OSImage                            string
 	Ready                              bool
 	Schedulable                        bool
+	MemoryPressure                     bool
+	DiskPressure                       bool
+	PIDPressure                        bool
+	EvictionRisk                       bool
+	PodReservationGap                  int
+	PodReservationExceeded             bool
 	TotalCapacityPods                  resource.Quantity
 	TotalCapacityCPU                   resource.Quantity
 	TotalCapacityCPUCores              float64
@@ -156,12 +325,70 @@ type NodeCapacityData struct {
 	TotalLimitsEphemeralStorageGB      float64
 	TotalAvailableEphemeralStorage     resource.Quantity
 	TotalAvailableEphemeralStorageGB   float64
+	TotalReservedCPU                   resource.Quantity
+	TotalReservedCPUCores              float64
+	TotalReservedMemory                resource.Quantity
+	TotalReservedMemoryGiB             float64
+	TotalReservedEphemeralStorage      resource.Quantity
+	TotalReservedEphemeralStorageGB    float64
+	TotalLimitsAvailableCPU            resource.Quantity
+	TotalLimitsAvailableCPUCores       float64
+	TotalLimitsAvailableMemory         resource.Quantity
+	TotalLimitsAvailableMemoryGiB      float64
+	TotalCapacityGPU                   resource.Quantity
+	TotalCapacityGPUCount              int
+	TotalAllocatableGPU                resource.Quantity
+	TotalAllocatableGPUCount           int
+	TotalRequestsGPU                   resource.Quantity
+	TotalRequestsGPUCount              int
+	TotalAvailableGPU                  resource.Quantity
+	TotalAvailableGPUCount             int
+	Hugepages                          map[string]*HugepagesCapacityData
+	ExtraResources                     map[string]*ResourceTriple
+	RequestsCPUUtilization             float64
+	RequestsMemoryUtilization          float64
+	AllocatableCPUEfficiency           float64
+	AllocatableMemoryEfficiency        float64
+	PodUtilization                     float64
+	CPULimitRequestRatio               float64
+	MemoryLimitRequestRatio            float64
+	TotalUsageCPU                      resource.Quantity
+	TotalUsageCPUCores                 float64
+	TotalUsageMemory                   resource.Quantity
+	TotalUsageMemoryGiB                float64
+	TotalUsageEphemeralStorage         resource.Quantity
+	TotalUsageEphemeralStorageGB       float64
+}
+
+// HugepagesCapacityData is the hugepages accounting for a single page size (Ex 2Mi or 1Gi)
+type HugepagesCapacityData struct {
+	Capacity       resource.Quantity
+	CapacityGiB    float64
+	Allocatable    resource.Quantity
+	AllocatableGiB float64
+	Requests       resource.Quantity
+	RequestsGiB    float64
+	Available      resource.Quantity
+	AvailableGiB   float64
 }
 
 type NamespaceCapacityData struct {
 	TotalPodCount                   int
 	TotalNonTermPodCount            int
 	TotalUnassignedNodePodCount     int
+	TotalContainerCount             int
+	TotalInitContainerCount         int
+	RunningPodCount                 int
+	PendingPodCount                 int
+	SucceededPodCount               int
+	FailedPodCount                  int
+	ReadyPodCount                   int
+	AvgContainersPerPod             float64
+	AvgCPURequestPerPod             float64
+	AvgMemoryRequestPerPod          float64
+	HasPodQuota                     bool
+	PodQuotaHard                    int
+	PodQuotaUsed                    int
 	TotalRequestsCPU                resource.Quantity
 	TotalRequestsCPUCores           float64
 	TotalLimitsCPU                  resource.Quantity
@@ -174,254 +401,865 @@ type NamespaceCapacityData struct {
 	TotalRequestsEphemeralStorageGB float64
 	TotalLimitsEphemeralStorage     resource.Quantity
 	TotalLimitsEphemeralStorageGB   float64
+	CPULimitRequestRatio            float64
+	MemoryLimitRequestRatio         float64
+	RequestsCPUClusterPct           float64
+	RequestsMemoryClusterPct        float64
+	DistinctNodeCount               int
+}
+
+type RegistryCapacityData struct {
+	TotalContainerCount    int
+	TotalRequestsCPU       resource.Quantity
+	TotalRequestsCPUCores  float64
+	TotalLimitsCPU         resource.Quantity
+	TotalLimitsCPUCores    float64
+	TotalRequestsMemory    resource.Quantity
+	TotalRequestsMemoryGiB float64
+	TotalLimitsMemory      resource.Quantity
+	TotalLimitsMemoryGiB   float64
+}
+
+// ImageCapacityData is the count of containers and unique container images of non-terminated pods on a node
+type ImageCapacityData struct {
+	TotalContainerCount int
+	UniqueImageCount    int
+}
+
+type StorageCapacityData struct {
+	TotalPVCCount          int
+	BoundPVCCount          int
+	PendingPVCCount        int
+	TotalRequestsStorage   resource.Quantity
+	TotalRequestsStorageGB float64
+	TotalPVCount           int
+	TotalCapacityStorage   resource.Quantity
+	TotalCapacityStorageGB float64
+}
+
+type PodCapacityData struct {
+	Name                   string
+	Namespace              string
+	Node                   string
+	Phase                  string
+	TotalRequestsCPU       resource.Quantity
+	TotalRequestsCPUCores  float64
+	TotalLimitsCPU         resource.Quantity
+	TotalLimitsCPUCores    float64
+	TotalRequestsMemory    resource.Quantity
+	TotalRequestsMemoryGiB float64
+	TotalLimitsMemory      resource.Quantity
+	TotalLimitsMemoryGiB   float64
+}
+
+type DeploymentCapacityData struct {
+	Name                   string
+	Namespace              string
+	TotalPodCount          int
+	TotalNonTermPodCount   int
+	TotalRequestsCPU       resource.Quantity
+	TotalRequestsCPUCores  float64
+	TotalLimitsCPU         resource.Quantity
+	TotalLimitsCPUCores    float64
+	TotalRequestsMemory    resource.Quantity
+	TotalRequestsMemoryGiB float64
+	TotalLimitsMemory      resource.Quantity
+	TotalLimitsMemoryGiB   float64
+}
+
+// WorkloadCapacityData is the count and requests of the non-terminated pods of a top-level owner (Ex a Deployment)
+type WorkloadCapacityData struct {
+	Kind                   string
+	Name                   string
+	Namespace              string
+	TotalPodCount          int
+	TotalRequestsCPU       resource.Quantity
+	TotalRequestsCPUCores  float64
+	TotalRequestsMemory    resource.Quantity
+	TotalRequestsMemoryGiB float64
+}
+
+type WindowCapacityData struct {
+	SnapshotCount           int
+	MinNodeCount            int
+	MaxNodeCount            int
+	AvgNodeCount            float64
+	MinAllocatableCPU       resource.Quantity
+	MinAllocatableCPUCores  float64
+	MaxAllocatableCPU       resource.Quantity
+	MaxAllocatableCPUCores  float64
+	AvgAllocatableCPU       resource.Quantity
+	AvgAllocatableCPUCores  float64
+	MinAllocatableMemory    resource.Quantity
+	MinAllocatableMemoryGiB float64
+	MaxAllocatableMemory    resource.Quantity
+	MaxAllocatableMemoryGiB float64
+	AvgAllocatableMemory    resource.Quantity
+	AvgAllocatableMemoryGiB float64
+	MinAvailableCPU         resource.Quantity
+	MinAvailableCPUCores    float64
+	MaxAvailableCPU         resource.Quantity
+	MaxAvailableCPUCores    float64
+	AvgAvailableCPU         resource.Quantity
+	AvgAvailableCPUCores    float64
+	MinAvailableMemory      resource.Quantity
+	MinAvailableMemoryGiB   float64
+	MaxAvailableMemory      resource.Quantity
+	MaxAvailableMemoryGiB   float64
+	AvgAvailableMemory      resource.Quantity
+	AvgAvailableMemoryGiB   float64
+}
+
+type PodDiagnosisData struct {
+	Namespace         string
+	Pod               string
+	RequestsCPU       resource.Quantity
+	RequestsCPUCores  float64
+	RequestsMemory    resource.Quantity
+	RequestsMemoryGiB float64
+	FitNodeCount      int
+	Hints             []string
 }
 
-func DisplayClusterData(clusterCapacityData ClusterCapacityData, displayDefault bool, displayHeaders bool, displayEphemeralStorage bool, displayFormat string) {
-	switch displayFormat {
+// JobsData is the CronJobs and the Jobs not created by a CronJob, each keyed by namespace/name
+type JobsData struct {
+	CronJobs map[string]*CronJobData
+	Jobs     map[string]*JobData
+}
+
+type CronJobData struct {
+	Name             string
+	Namespace        string
+	Schedule         string
+	Suspended        bool
+	ActiveJobCount   int
+	LastScheduleTime *time.Time
+}
+
+// Completions is the desired number of successful pods, 0 when unset
+type JobData struct {
+	Name        string
+	Namespace   string
+	Completions int
+	Active      int
+	Succeeded   int
+	Failed      int
+}
+
+// DisplayExplain prints a footnote after table output explaining how available values are computed, machine readable
+// formats are left untouched
+func DisplayExplain(out io.Writer, displayOptions DisplayOptions) {
+	switch displayOptions.Format {
+	case jsonDisplay, yamlDisplay, jsonlDisplay, openMetricsDisplay, customColumnsDisplay:
+		return
+	}
+	fmt.Fprintln(out, "")
+	fmt.Fprintln(out, "Available cpu, memory and ephemeral storage = allocatable - requests of non-terminated pods")
+	fmt.Fprintln(out, "Available pods = allocatable pods - non-terminated pods")
+	fmt.Fprintln(out, "Negative available values are shown as 0 unless --allow-negative is set.")
+	fmt.Fprintln(out, "Non-terminated pods not yet assigned to a node (Ex Pending) count against cluster available pods but not against")
+	fmt.Fprintln(out, "any node, so cluster available pods can be lower than the sum of node available pods.")
+}
+
+// DisplayOvercommit prints a verdict after table output on whether cpu and memory requests exceed allocatable, machine
+// readable formats already include the overcommit fields. name prefixes the verdict when set (Ex a kubeconfig context)
+func DisplayOvercommit(out io.Writer, name string, clusterCapacityData ClusterCapacityData, displayOptions DisplayOptions) {
+	switch displayOptions.Format {
+	case jsonDisplay, yamlDisplay, jsonlDisplay, openMetricsDisplay, customColumnsDisplay:
+		return
+	}
+	if name != "" {
+		name += " "
+	}
+	fmt.Fprintln(out, "")
+	fmt.Fprintf(out, "%s%s\n", name, overcommitVerdict("CPU", clusterCapacityData.CPUOvercommitted, clusterCapacityData.CPUOvercommitRatio))
+	fmt.Fprintf(out, "%s%s\n", name, overcommitVerdict("Memory", clusterCapacityData.MemoryOvercommitted, clusterCapacityData.MemoryOvercommitRatio))
+}
+
+func overcommitVerdict(resourceName string, overcommitted bool, ratio float64) string {
+	if overcommitted {
+		return fmt.Sprintf("%s overcommitted: %.2fx", resourceName, ratio)
+	}
+	return fmt.Sprintf("%s not overcommitted: %.2fx", resourceName, ratio)
+}
+
+func DisplayClusterData(out io.Writer, clusterCapacityData ClusterCapacityData, displayOptions DisplayOptions) {
+	switch displayOptions.Format {
 	case jsonDisplay:
-		jsonClusterData, err := json.MarshalIndent(&clusterCapacityData, "", "  ")
+		var data interface{} = withMetadata(&clusterCapacityData, displayOptions)
+		if displayOptions.Flat {
+			data = flatData(reflect.ValueOf(clusterCapacityData))
+		}
+		jsonClusterData, err := marshalJSON(data, displayOptions.Compact)
 		if err != nil {
-			fmt.Println(err)
+			fmt.Fprintln(out, err)
 			return
 		}
-		fmt.Println(string(jsonClusterData))
+		fmt.Fprintln(out, string(jsonClusterData))
 	case yamlDisplay:
-		yamlClusterData, err := yaml.Marshal(clusterCapacityData)
+		yamlClusterData, err := yaml.Marshal(withMetadata(clusterCapacityData, displayOptions))
 		if err != nil {
-			fmt.Println(err)
+			fmt.Fprintln(out, err)
 			return
 		}
-		fmt.Print(string(yamlClusterData))
+		fmt.Fprint(out, string(yamlClusterData))
+	case openMetricsDisplay, jsonlDisplay, customColumnsDisplay:
+		writeRows(out, displayOptions, "cluster", "", []metricsRow{{data: reflect.ValueOf(clusterCapacityData)}})
 	default:
-		w := new(tabwriter.Writer)
-		w.Init(os.Stdout, 0, 5, 1, ' ', 0)
-		if displayHeaders {
-			if displayDefault {
-				fmt.Fprintf(w, "NODES\t\t\t\tPODS\t\t\t\t\tCPU\t\t\t\t\tMEMORY\t\t\t\t\t")
-				if displayEphemeralStorage {
-					fmt.Fprintf(w, "EPHEMERAL STORAGE")
-				}
-				fmt.Fprintln(w, "")
-			} else {
-				fmt.Fprintf(w, "NODES\t\t\t\tPODS\t\t\t\t\tCPU (cores)\t\t\t\t\tMEMORY (GiB)\t\t\t\t\t")
-				if displayEphemeralStorage {
-					fmt.Fprintf(w, "EPHEMERAL STORAGE (GB)")
-				}
-				fmt.Fprintln(w, "")
+		w := newTableWriter(out, displayOptions, 2)
+		if displayOptions.Hugepages {
+			displayOptions.hugepageSizes = hugepageSizes(nil, clusterCapacityData.Hugepages)
+		}
+		if displayOptions.Headers {
+			printClusterHeaders(w, "", displayOptions)
+		}
+		printClusterData(w, &clusterCapacityData, displayOptions)
+		w.Flush()
+		if displayOptions.Utilization {
+			printCapacityPctFooter(out, &clusterCapacityData)
+		}
+	}
+}
+
+// printCapacityPctFooter prints cpu and memory requests as a percentage of raw node capacity, unlike the utilization
+// columns which are a percentage of allocatable (capacity minus system reserved)
+func printCapacityPctFooter(out io.Writer, clusterCapacityData *ClusterCapacityData) {
+	capacityPct := func(pct float64, capacity resource.Quantity) string {
+		if capacity.IsZero() {
+			return "-"
+		}
+		return fmt.Sprintf("%.1f%%", pct)
+	}
+	fmt.Fprintln(out, "")
+	fmt.Fprintf(out, "Requests of capacity: CPU %s, Memory %s\n", capacityPct(clusterCapacityData.RequestsCPUCapacityPct, clusterCapacityData.TotalCapacityCPU),
+		capacityPct(clusterCapacityData.RequestsMemoryCapacityPct, clusterCapacityData.TotalCapacityMemory))
+}
+
+// printClusterHeaders prints the table headers shared by cluster and grouped cluster (Ex node-role) data, groupName
+// is the header of the leading group column and is omitted when empty
+func printClusterHeaders(w io.Writer, groupName string, displayOptions DisplayOptions) {
+	if groupName != "" {
+		fmt.Fprintf(w, "%s\t", groupName)
+	}
+	fmt.Fprintf(w, "NODES\t\t\t\tPODS\t\t\t\t\t")
+	printResourceHeaders(w, displayOptions)
+	if displayOptions.Default {
+		if displayOptions.Phases {
+			fmt.Fprintf(w, "PHASES\t\t\t\t")
+		}
+		if displayOptions.QOS {
+			fmt.Fprintf(w, "QOS PODS\t\t\tQOS CPU REQUESTS\t\tQOS MEMORY REQUESTS\t\t")
+		}
+		if displayOptions.GPU {
+			fmt.Fprintf(w, "GPU\t\t\t\t")
+		}
+		printExtraResourcesHeaders(w, displayOptions)
+		printHugepagesHeaders(w, displayOptions)
+		printReservedHeaders(w, displayOptions)
+		printLimitsAvailableHeaders(w, displayOptions)
+		if displayOptions.Utilization {
+			printUtilizationHeaders(w, displayOptions)
+		}
+		if displayOptions.Usage {
+			fmt.Fprintf(w, "USAGE\t\t")
+		}
+		if displayOptions.Schedulable {
+			fmt.Fprintf(w, "SCHEDULABLE\t\t\t")
+			if displayOptions.WorkloadAvailable {
+				fmt.Fprintf(w, "WORKLOAD AVAIL\t\t")
 			}
-			fmt.Fprintf(w, "Total\tReady\tUnready\tUnsch\tCapacity\tAllocatable\tTotal\tNon-Term\tAvail\tCapacity\tAllocatable\tRequests\tLimits\tAvail\tCapacity\tAllocatable\tRequests\tLimits\tAvail\t")
-			if displayEphemeralStorage {
-				fmt.Fprintf(w, "Capacity\tAllocatable\tRequests\tLimits\tAvail")
+		}
+	} else {
+		if displayOptions.Phases {
+			fmt.Fprintf(w, "PHASES\t\t\t\t")
+		}
+		if displayOptions.QOS {
+			fmt.Fprintf(w, "QOS PODS\t\t\tQOS CPU REQUESTS (cores)\t\tQOS MEMORY REQUESTS (%s)\t\t", capacity.MemoryUnit())
+		}
+		if displayOptions.GPU {
+			fmt.Fprintf(w, "GPU\t\t\t\t")
+		}
+		printExtraResourcesHeaders(w, displayOptions)
+		printHugepagesHeaders(w, displayOptions)
+		printReservedHeaders(w, displayOptions)
+		printLimitsAvailableHeaders(w, displayOptions)
+		if displayOptions.Utilization {
+			printUtilizationHeaders(w, displayOptions)
+		}
+		if displayOptions.Usage {
+			fmt.Fprintf(w, "USAGE (cores/%s)\t\t", capacity.MemoryUnit())
+		}
+		if displayOptions.Schedulable {
+			fmt.Fprintf(w, "SCHEDULABLE (cores/%s)\t\t\t", capacity.MemoryUnit())
+			if displayOptions.WorkloadAvailable {
+				fmt.Fprintf(w, "WORKLOAD AVAIL (cores/%s)\t\t", capacity.MemoryUnit())
 			}
-			fmt.Fprintln(w, "")
 		}
-		fmt.Fprintf(w, "%d\t%d\t%d\t%d\t", clusterCapacityData.TotalNodeCount, clusterCapacityData.TotalReadyNodeCount, clusterCapacityData.TotalUnreadyNodeCount, clusterCapacityData.TotalUnschedulableNodeCount)
-		fmt.Fprintf(w, "%s\t%s\t", &clusterCapacityData.TotalCapacityPods, &clusterCapacityData.TotalAllocatablePods)
-		fmt.Fprintf(w, "%d\t%d\t", clusterCapacityData.TotalPodCount, clusterCapacityData.TotalNonTermPodCount)
-		fmt.Fprintf(w, "%d\t", clusterCapacityData.TotalAvailablePods)
-		if displayDefault {
-			fmt.Fprintf(w, "%s\t%s\t", &clusterCapacityData.TotalCapacityCPU, &clusterCapacityData.TotalAllocatableCPU)
-			fmt.Fprintf(w, "%s\t%s\t", &clusterCapacityData.TotalRequestsCPU, &clusterCapacityData.TotalLimitsCPU)
-			fmt.Fprintf(w, "%s\t", &clusterCapacityData.TotalAvailableCPU)
-			fmt.Fprintf(w, "%s\t%s\t", &clusterCapacityData.TotalCapacityMemory, &clusterCapacityData.TotalAllocatableMemory)
-			fmt.Fprintf(w, "%s\t%s\t", &clusterCapacityData.TotalRequestsMemory, &clusterCapacityData.TotalLimitsMemory)
-			fmt.Fprintf(w, "%s\t", &clusterCapacityData.TotalAvailableMemory)
-			if displayEphemeralStorage {
-				fmt.Fprintf(w, "%s\t%s\t", &clusterCapacityData.TotalCapacityEphemeralStorage, &clusterCapacityData.TotalAllocatableEphemeralStorage)
-				fmt.Fprintf(w, "%s\t%s\t", &clusterCapacityData.TotalRequestsEphemeralStorage, &clusterCapacityData.TotalLimitsEphemeralStorage)
-				fmt.Fprintf(w, "%s\t", &clusterCapacityData.TotalAvailableEphemeralStorage)
+	}
+	fmt.Fprintln(w, "")
+	if groupName != "" {
+		fmt.Fprintf(w, "\t")
+	}
+	fmt.Fprintf(w, "Total\tReady\tUnready\tUnsch\tCapacity\tAllocatable\tTotal\tNon-Term\tAvail\t")
+	printResourceSubHeaders(w, displayOptions)
+	if displayOptions.Phases {
+		fmt.Fprintf(w, "Running\tPending\tSucceeded\tFailed\t")
+	}
+	if displayOptions.QOS {
+		fmt.Fprintf(w, "Guaranteed\tBurstable\tBestEffort\tGuaranteed\tBurstable\tGuaranteed\tBurstable\t")
+	}
+	if displayOptions.GPU {
+		fmt.Fprintf(w, "Capacity\tAllocatable\tRequests\tAvail\t")
+	}
+	for range displayOptions.ExtraResources {
+		fmt.Fprintf(w, "Capacity\tAllocatable\tRequests\t")
+	}
+	for range displayOptions.hugepageSizes {
+		fmt.Fprintf(w, "Capacity\tAllocatable\tRequests\tAvail\t")
+	}
+	if displayOptions.Reserved {
+		fmt.Fprintf(w, "CPU\tMemory\tStorage\t")
+	}
+	if displayOptions.LimitsAvailable {
+		fmt.Fprintf(w, "CPU\tMemory\t")
+	}
+	if displayOptions.Utilization {
+		printUtilizationSubHeaders(w, displayOptions)
+	}
+	if displayOptions.Usage {
+		fmt.Fprintf(w, "CPU\tMemory\t")
+	}
+	if displayOptions.Schedulable {
+		fmt.Fprintf(w, "Nodes\tAllocatable CPU\tAllocatable Memory\t")
+		if displayOptions.WorkloadAvailable {
+			fmt.Fprintf(w, "CPU\tMemory\t")
+		}
+	}
+	fmt.Fprintln(w, "")
+}
+
+func printClusterData(w io.Writer, clusterCapacityData *ClusterCapacityData, displayOptions DisplayOptions) {
+	fmt.Fprintf(w, "%d\t%d\t%d\t%d\t", clusterCapacityData.TotalNodeCount, clusterCapacityData.TotalReadyNodeCount, clusterCapacityData.TotalUnreadyNodeCount, clusterCapacityData.TotalUnschedulableNodeCount)
+	fmt.Fprintf(w, "%s\t%s\t", &clusterCapacityData.TotalCapacityPods, &clusterCapacityData.TotalAllocatablePods)
+	fmt.Fprintf(w, "%d\t%d\t", clusterCapacityData.TotalPodCount, clusterCapacityData.TotalNonTermPodCount)
+	fmt.Fprintf(w, "%d\t", clusterCapacityData.TotalAvailablePods)
+	printResourceColumns(w, [5]*resource.Quantity{&clusterCapacityData.TotalCapacityCPU, &clusterCapacityData.TotalAllocatableCPU, &clusterCapacityData.TotalRequestsCPU,
+		&clusterCapacityData.TotalLimitsCPU, &clusterCapacityData.TotalAvailableCPU}, [5]float64{clusterCapacityData.TotalCapacityCPUCores,
+		clusterCapacityData.TotalAllocatableCPUCores, clusterCapacityData.TotalRequestsCPUCores, clusterCapacityData.TotalLimitsCPUCores,
+		clusterCapacityData.TotalAvailableCPUCores}, displayOptions)
+	printResourceColumns(w, [5]*resource.Quantity{&clusterCapacityData.TotalCapacityMemory, &clusterCapacityData.TotalAllocatableMemory, &clusterCapacityData.TotalRequestsMemory,
+		&clusterCapacityData.TotalLimitsMemory, &clusterCapacityData.TotalAvailableMemory}, [5]float64{capacity.TableMem(clusterCapacityData.TotalCapacityMemoryGiB),
+		capacity.TableMem(clusterCapacityData.TotalAllocatableMemoryGiB), capacity.TableMem(clusterCapacityData.TotalRequestsMemoryGiB),
+		capacity.TableMem(clusterCapacityData.TotalLimitsMemoryGiB), capacity.TableMem(clusterCapacityData.TotalAvailableMemoryGiB)}, displayOptions)
+	if displayOptions.EphemeralStorage {
+		printResourceColumns(w, [5]*resource.Quantity{&clusterCapacityData.TotalCapacityEphemeralStorage, &clusterCapacityData.TotalAllocatableEphemeralStorage,
+			&clusterCapacityData.TotalRequestsEphemeralStorage, &clusterCapacityData.TotalLimitsEphemeralStorage, &clusterCapacityData.TotalAvailableEphemeralStorage},
+			[5]float64{capacity.TableStorage(clusterCapacityData.TotalCapacityEphemeralStorageGB), capacity.TableStorage(clusterCapacityData.TotalAllocatableEphemeralStorageGB),
+				capacity.TableStorage(clusterCapacityData.TotalRequestsEphemeralStorageGB), capacity.TableStorage(clusterCapacityData.TotalLimitsEphemeralStorageGB),
+				capacity.TableStorage(clusterCapacityData.TotalAvailableEphemeralStorageGB)}, displayOptions)
+	}
+	if displayOptions.Default {
+		if displayOptions.Phases {
+			printPhases(w, clusterCapacityData.RunningPodCount, clusterCapacityData.PendingPodCount, clusterCapacityData.SucceededPodCount, clusterCapacityData.FailedPodCount)
+		}
+		if displayOptions.QOS {
+			fmt.Fprintf(w, "%d\t%d\t%d\t", clusterCapacityData.GuaranteedPodCount, clusterCapacityData.BurstablePodCount, clusterCapacityData.BestEffortPodCount)
+			fmt.Fprintf(w, "%s\t%s\t", &clusterCapacityData.GuaranteedRequestsCPU, &clusterCapacityData.BurstableRequestsCPU)
+			fmt.Fprintf(w, "%s\t%s\t", &clusterCapacityData.GuaranteedRequestsMemory, &clusterCapacityData.BurstableRequestsMemory)
+		}
+		if displayOptions.GPU {
+			fmt.Fprintf(w, "%s\t%s\t", &clusterCapacityData.TotalCapacityGPU, &clusterCapacityData.TotalAllocatableGPU)
+			fmt.Fprintf(w, "%s\t%s\t", &clusterCapacityData.TotalRequestsGPU, &clusterCapacityData.TotalAvailableGPU)
+		}
+		printExtraResourcesData(w, clusterCapacityData.ExtraResources, displayOptions)
+		printHugepagesData(w, clusterCapacityData.Hugepages, displayOptions)
+		if displayOptions.Reserved {
+			printReservedData(w, clusterCapacityData.TotalReservedCPU, clusterCapacityData.TotalReservedMemory, clusterCapacityData.TotalReservedEphemeralStorage, displayOptions)
+		}
+		if displayOptions.LimitsAvailable {
+			printLimitsAvailableData(w, clusterCapacityData.TotalLimitsAvailableCPU, clusterCapacityData.TotalLimitsAvailableMemory, displayOptions)
+		}
+		if displayOptions.Utilization {
+			printRequestsUtilization(w, clusterCapacityData.RequestsCPUUtilization, clusterCapacityData.TotalAllocatableCPU, displayOptions)
+			printRequestsUtilization(w, clusterCapacityData.RequestsMemoryUtilization, clusterCapacityData.TotalAllocatableMemory, displayOptions)
+			printUtilization(w, clusterCapacityData.PodUtilization, clusterCapacityData.TotalAllocatablePods)
+		}
+		if displayOptions.Usage {
+			fmt.Fprintf(w, "%s\t%s\t", &clusterCapacityData.TotalUsageCPU, &clusterCapacityData.TotalUsageMemory)
+		}
+		if displayOptions.Schedulable {
+			fmt.Fprintf(w, "%d\t%s\t%s\t", clusterCapacityData.SchedulableNodeCount, &clusterCapacityData.SchedulableAllocatableCPU, &clusterCapacityData.SchedulableAllocatableMemory)
+			if displayOptions.WorkloadAvailable {
+				fmt.Fprintf(w, "%s\t%s\t", &clusterCapacityData.WorkloadAvailableCPU, &clusterCapacityData.WorkloadAvailableMemory)
 			}
-			fmt.Fprintln(w, "")
-		} else {
-			fmt.Fprintf(w, "%.1f\t%.1f\t", clusterCapacityData.TotalCapacityCPUCores, clusterCapacityData.TotalAllocatableCPUCores)
-			fmt.Fprintf(w, "%.1f\t%.1f\t", clusterCapacityData.TotalRequestsCPUCores, clusterCapacityData.TotalLimitsCPUCores)
-			fmt.Fprintf(w, "%.1f\t", clusterCapacityData.TotalAvailableCPUCores)
-			fmt.Fprintf(w, "%.1f\t%.1f\t", clusterCapacityData.TotalCapacityMemoryGiB, clusterCapacityData.TotalAllocatableMemoryGiB)
-			fmt.Fprintf(w, "%.1f\t%.1f\t", clusterCapacityData.TotalRequestsMemoryGiB, clusterCapacityData.TotalLimitsMemoryGiB)
-			fmt.Fprintf(w, "%.1f\t", clusterCapacityData.TotalAvailableMemoryGiB)
-			if displayEphemeralStorage {
-				fmt.Fprintf(w, "%.1f\t%.1f\t", clusterCapacityData.TotalCapacityEphemeralStorageGB, clusterCapacityData.TotalAllocatableEphemeralStorageGB)
-				fmt.Fprintf(w, "%.1f\t%.1f\t", clusterCapacityData.TotalRequestsEphemeralStorageGB, clusterCapacityData.TotalLimitsEphemeralStorageGB)
-				fmt.Fprintf(w, "%.1f\t", clusterCapacityData.TotalAvailableEphemeralStorageGB)
+		}
+	} else {
+		if displayOptions.Phases {
+			printPhases(w, clusterCapacityData.RunningPodCount, clusterCapacityData.PendingPodCount, clusterCapacityData.SucceededPodCount, clusterCapacityData.FailedPodCount)
+		}
+		if displayOptions.QOS {
+			fmt.Fprintf(w, "%d\t%d\t%d\t", clusterCapacityData.GuaranteedPodCount, clusterCapacityData.BurstablePodCount, clusterCapacityData.BestEffortPodCount)
+			fmt.Fprintf(w, "%s\t%s\t", readable(clusterCapacityData.GuaranteedRequestsCPUCores, displayOptions), readable(clusterCapacityData.BurstableRequestsCPUCores, displayOptions))
+			fmt.Fprintf(w, "%s\t%s\t", readable(capacity.TableMem(clusterCapacityData.GuaranteedRequestsMemoryGiB), displayOptions), readable(capacity.TableMem(clusterCapacityData.BurstableRequestsMemoryGiB), displayOptions))
+		}
+		if displayOptions.GPU {
+			fmt.Fprintf(w, "%d\t%d\t", clusterCapacityData.TotalCapacityGPUCount, clusterCapacityData.TotalAllocatableGPUCount)
+			fmt.Fprintf(w, "%d\t%d\t", clusterCapacityData.TotalRequestsGPUCount, clusterCapacityData.TotalAvailableGPUCount)
+		}
+		printExtraResourcesData(w, clusterCapacityData.ExtraResources, displayOptions)
+		printHugepagesData(w, clusterCapacityData.Hugepages, displayOptions)
+		if displayOptions.Reserved {
+			printReservedData(w, clusterCapacityData.TotalReservedCPU, clusterCapacityData.TotalReservedMemory, clusterCapacityData.TotalReservedEphemeralStorage, displayOptions)
+		}
+		if displayOptions.LimitsAvailable {
+			printLimitsAvailableData(w, clusterCapacityData.TotalLimitsAvailableCPU, clusterCapacityData.TotalLimitsAvailableMemory, displayOptions)
+		}
+		if displayOptions.Utilization {
+			printRequestsUtilization(w, clusterCapacityData.RequestsCPUUtilization, clusterCapacityData.TotalAllocatableCPU, displayOptions)
+			printRequestsUtilization(w, clusterCapacityData.RequestsMemoryUtilization, clusterCapacityData.TotalAllocatableMemory, displayOptions)
+			printUtilization(w, clusterCapacityData.PodUtilization, clusterCapacityData.TotalAllocatablePods)
+		}
+		if displayOptions.Usage {
+			fmt.Fprintf(w, "%s\t%s\t", readable(clusterCapacityData.TotalUsageCPUCores, displayOptions), readable(capacity.TableMem(clusterCapacityData.TotalUsageMemoryGiB), displayOptions))
+		}
+		if displayOptions.Schedulable {
+			fmt.Fprintf(w, "%d\t%s\t%s\t", clusterCapacityData.SchedulableNodeCount, readable(clusterCapacityData.SchedulableAllocatableCPUCores, displayOptions), readable(capacity.TableMem(clusterCapacityData.SchedulableAllocatableMemoryGiB), displayOptions))
+			if displayOptions.WorkloadAvailable {
+				fmt.Fprintf(w, "%s\t%s\t", readable(clusterCapacityData.WorkloadAvailableCPUCores, displayOptions), readable(capacity.TableMem(clusterCapacityData.WorkloadAvailableMemoryGiB), displayOptions))
 			}
-			fmt.Fprintln(w, "")
 		}
-		w.Flush()
+	}
+	fmt.Fprintln(w, "")
+}
+
+// printResourceHeaders prints the cpu, memory and, with --ephemeral-storage, ephemeral storage column group headers.
+// --wide prints each group twice, the quantities followed by the readable values.
+func printResourceHeaders(w io.Writer, displayOptions DisplayOptions) {
+	groups := [][2]string{{"CPU", "CPU (cores)"}, {"MEMORY", fmt.Sprintf("MEMORY (%s)", capacity.MemoryUnit())}}
+	if displayOptions.EphemeralStorage {
+		groups = append(groups, [2]string{"EPHEMERAL STORAGE", fmt.Sprintf("EPHEMERAL STORAGE (%s)", capacity.StorageUnit())})
+	}
+	for _, group := range groups {
+		if displayOptions.Default || displayOptions.Wide {
+			fmt.Fprintf(w, "%s\t\t\t\t\t", group[0])
+		}
+		if !displayOptions.Default || displayOptions.Wide {
+			fmt.Fprintf(w, "%s\t\t\t\t\t", group[1])
+		}
+	}
+}
+
+// printResourceSubHeaders prints the sub-headers of the printResourceHeaders column groups
+func printResourceSubHeaders(w io.Writer, displayOptions DisplayOptions) {
+	groups := 2
+	if displayOptions.EphemeralStorage {
+		groups++
+	}
+	if displayOptions.Wide {
+		groups *= 2
+	}
+	for i := 0; i < groups; i++ {
+		fmt.Fprintf(w, "Capacity\tAllocatable\tRequests\tLimits\tAvail\t")
+	}
+}
+
+// printResourceColumns prints the capacity, allocatable, requests, limits and available columns of a resource as
+// quantities with --default-format, as readable values otherwise, or both side by side with --wide
+func printResourceColumns(w io.Writer, quantities [5]*resource.Quantity, values [5]float64, displayOptions DisplayOptions) {
+	if displayOptions.Default || displayOptions.Wide {
+		for _, quantity := range quantities {
+			fmt.Fprintf(w, "%s\t", quantity)
+		}
+	}
+	if !displayOptions.Default || displayOptions.Wide {
+		for _, value := range values {
+			fmt.Fprintf(w, "%s\t", readable(value, displayOptions))
+		}
+	}
+}
+
+// readable formats a "Human" readable value (Ex cores or GiB) with the --precision number of decimals
+func readable(value float64, displayOptions DisplayOptions) string {
+	return strconv.FormatFloat(value, 'f', displayOptions.Precision, 64)
+}
+
+// printUtilization prints a utilization percentage, or "-" when there is nothing allocatable to compare against
+func printUtilization(w io.Writer, utilization float64, allocatable resource.Quantity) {
+	if allocatable.IsZero() {
+		fmt.Fprintf(w, "-\t")
+		return
+	}
+	fmt.Fprintf(w, "%.1f\t", utilization)
+}
+
+// printPhases prints the pod counts of the Running, Pending, Succeeded and Failed phases
+func printPhases(w io.Writer, running, pending, succeeded, failed int) {
+	fmt.Fprintf(w, "%d\t%d\t%d\t%d\t", running, pending, succeeded, failed)
+}
+
+// printRatio prints a limits to requests ratio, or "-" when there are no requests to compare against
+func printRatio(w io.Writer, ratio float64, requests resource.Quantity) {
+	if requests.IsZero() {
+		fmt.Fprintf(w, "-\t")
+		return
+	}
+	fmt.Fprintf(w, "%.2f\t", ratio)
+}
+
+// hugepageSizes adds the page sizes found in hugepages to sizes, returning the sorted union
+func hugepageSizes(sizes []string, hugepages map[string]*HugepagesCapacityData) []string {
+	for size := range hugepages {
+		if !containsString(sizes, size) {
+			sizes = append(sizes, size)
+		}
+	}
+	sort.Strings(sizes)
+	return sizes
+}
+
+func containsString(slice []string, s string) bool {
+	for _, item := range slice {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+func printHugepagesHeaders(w io.Writer, displayOptions DisplayOptions) {
+	for _, size := range displayOptions.hugepageSizes {
+		if displayOptions.Default {
+			fmt.Fprintf(w, "HUGEPAGES %s\t\t\t\t", size)
+		} else {
+			fmt.Fprintf(w, "HUGEPAGES %s (%s)\t\t\t\t", size, capacity.MemoryUnit())
+		}
+	}
+}
+
+func printExtraResourcesHeaders(w io.Writer, displayOptions DisplayOptions) {
+	for _, name := range displayOptions.ExtraResources {
+		fmt.Fprintf(w, "%s\t\t\t", strings.ToUpper(name))
+	}
+}
+
+func printReservedHeaders(w io.Writer, displayOptions DisplayOptions) {
+	if !displayOptions.Reserved {
+		return
+	}
+	if displayOptions.Default {
+		fmt.Fprintf(w, "RESERVED\t\t\t")
+	} else {
+		fmt.Fprintf(w, "RESERVED (cores/%s/%s)\t\t\t", capacity.MemoryUnit(), capacity.StorageUnit())
+	}
+}
+
+// printReservedData prints the capacity - allocatable cpu, memory and ephemeral storage held back for the system
+func printReservedData(w io.Writer, cpu, memory, ephemeralStorage resource.Quantity, displayOptions DisplayOptions) {
+	if displayOptions.Default {
+		fmt.Fprintf(w, "%s\t%s\t%s\t", &cpu, &memory, &ephemeralStorage)
+	} else {
+		fmt.Fprintf(w, "%s\t%s\t%s\t", readable(capacity.ReadableCPU(cpu), displayOptions), readable(capacity.TableMem(capacity.ReadableMem(memory)), displayOptions), readable(capacity.TableStorage(capacity.ReadableStorage(ephemeralStorage)), displayOptions))
+	}
+}
+
+func printLimitsAvailableHeaders(w io.Writer, displayOptions DisplayOptions) {
+	if !displayOptions.LimitsAvailable {
+		return
+	}
+	if displayOptions.Default {
+		fmt.Fprintf(w, "LIMITS AVAIL\t\t")
+	} else {
+		fmt.Fprintf(w, "LIMITS AVAIL (cores/%s)\t\t", capacity.MemoryUnit())
+	}
+}
+
+// printLimitsAvailableData prints the allocatable - limits cpu and memory, negative when aggregate limits overcommit
+func printLimitsAvailableData(w io.Writer, cpu, memory resource.Quantity, displayOptions DisplayOptions) {
+	if displayOptions.Default {
+		fmt.Fprintf(w, "%s\t%s\t", &cpu, &memory)
+	} else {
+		fmt.Fprintf(w, "%s\t%s\t", readable(capacity.ReadableCPU(cpu), displayOptions), readable(capacity.TableMem(capacity.ReadableMem(memory)), displayOptions))
 	}
 }
 
-func DisplayClusterSizeData(clusterSizeData ClusterSizeData, displayHeaders bool, displayFormat string) {
-	switch displayFormat {
+// printHugepagesData prints a column group per discovered page size, sizes missing from hugepages print as zero
+func printHugepagesData(w io.Writer, hugepages map[string]*HugepagesCapacityData, displayOptions DisplayOptions) {
+	for _, size := range displayOptions.hugepageSizes {
+		data, ok := hugepages[size]
+		if !ok {
+			data = &HugepagesCapacityData{}
+		}
+		if displayOptions.Default {
+			fmt.Fprintf(w, "%s\t%s\t", &data.Capacity, &data.Allocatable)
+			fmt.Fprintf(w, "%s\t%s\t", &data.Requests, &data.Available)
+		} else {
+			fmt.Fprintf(w, "%s\t%s\t", readable(capacity.TableMem(data.CapacityGiB), displayOptions), readable(capacity.TableMem(data.AllocatableGiB), displayOptions))
+			fmt.Fprintf(w, "%s\t%s\t", readable(capacity.TableMem(data.RequestsGiB), displayOptions), readable(capacity.TableMem(data.AvailableGiB), displayOptions))
+		}
+	}
+}
+
+// printExtraResourcesData prints a column group per --extra-resources name, names missing from extraResources print as
+// zero. Extended resources are counted in their own units so are never converted to "Human" readable values.
+func printExtraResourcesData(w io.Writer, extraResources map[string]*ResourceTriple, displayOptions DisplayOptions) {
+	for _, name := range displayOptions.ExtraResources {
+		data, ok := extraResources[name]
+		if !ok {
+			data = &ResourceTriple{}
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t", &data.Capacity, &data.Allocatable, &data.Requests)
+	}
+}
+
+func DisplayClusterSizeData(out io.Writer, clusterSizeData ClusterSizeData, displayOptions DisplayOptions) {
+	switch displayOptions.Format {
 	case jsonDisplay:
-		jsonClusterData, err := json.MarshalIndent(&clusterSizeData, "", "  ")
+		jsonClusterData, err := marshalJSON(withMetadata(&clusterSizeData, displayOptions), displayOptions.Compact)
 		if err != nil {
-			fmt.Println(err)
+			fmt.Fprintln(out, err)
 			return
 		}
-		fmt.Println(string(jsonClusterData))
+		fmt.Fprintln(out, string(jsonClusterData))
 	case yamlDisplay:
-		yamlClusterData, err := yaml.Marshal(clusterSizeData)
+		yamlClusterData, err := yaml.Marshal(withMetadata(clusterSizeData, displayOptions))
 		if err != nil {
-			fmt.Println(err)
+			fmt.Fprintln(out, err)
 			return
 		}
-		fmt.Print(string(yamlClusterData))
+		fmt.Fprint(out, string(yamlClusterData))
+	case openMetricsDisplay, jsonlDisplay, customColumnsDisplay:
+		writeRows(out, displayOptions, "size", "", []metricsRow{{data: reflect.ValueOf(clusterSizeData)}})
 	default:
-		w := new(tabwriter.Writer)
-		w.Init(os.Stdout, 0, 5, 1, ' ', 0)
-		if displayHeaders {
+		w := newTableWriter(out, displayOptions, 1)
+		if displayOptions.Headers {
 			fmt.Fprintln(w, "CLUSTER APIs")
 			fmt.Fprintln(w, "Namespaces\tNodes\tPersistentVolumes\tServiceAccounts\tClusterRoles\tClusterRoleBindings\tRoles\tRoleBindings\tResourceQuotas\tNetworkPolicies")
 		}
 		fmt.Fprintf(w, "%d\t%d\t%d\t%d\t", clusterSizeData.Namespace, clusterSizeData.Node, clusterSizeData.PersistentVolume, clusterSizeData.ServiceAccount)
 		fmt.Fprintf(w, "%d\t%d\t%d\t%d\t", clusterSizeData.ClusterRole, clusterSizeData.ClusterRoleBinding, clusterSizeData.Role, clusterSizeData.RoleBinding)
 		fmt.Fprintf(w, "%d\t%d\n", clusterSizeData.ResourceQuota, clusterSizeData.NetworkPolicy)
-		if displayHeaders {
+		if displayOptions.Headers {
 			fmt.Fprintln(w, "WORKLOAD APIs")
-			fmt.Fprintln(w, "Containers\tPods\tReplicaSets\tReplicationControllers\tDeployments\tDaemonSets\tStatefulSets\tCronJobs\tJobs")
+			fmt.Fprintln(w, "Containers\tPods\tReplicaSets\tReplicationControllers\tDeployments\tDaemonSets\tStatefulSets\tCronJobs\tJobs\tHorizontalPodAutoscalers")
 		}
 		fmt.Fprintf(w, "%d\t%d\t%d\t%d\t", clusterSizeData.Container, clusterSizeData.Pod, clusterSizeData.ReplicaSet, clusterSizeData.ReplicaController)
 		fmt.Fprintf(w, "%d\t%d\t%d\t%d\t", clusterSizeData.Deployment, clusterSizeData.Daemonset, clusterSizeData.StatefulSet, clusterSizeData.CronJob)
-		fmt.Fprintf(w, "%d\n", clusterSizeData.Job)
-		if displayHeaders {
+		fmt.Fprintf(w, "%d\t%d\n", clusterSizeData.Job, clusterSizeData.HorizontalPodAutoscaler)
+		if displayOptions.Headers {
 			fmt.Fprintln(w, "SERVICE APIs")
 			fmt.Fprintln(w, "Endpoints\tIngresses\tServices")
 		}
 		fmt.Fprintf(w, "%d\t%d\t%d\n", clusterSizeData.EndPoints, clusterSizeData.Ingress, clusterSizeData.Service)
-		if displayHeaders {
+		if displayOptions.Headers {
 			fmt.Fprintln(w, "CONFIG And STORAGE APIs")
 			fmt.Fprintln(w, "ConfigMaps\tSecrets\tPersistentVolumeClaims\tStorageClasses\tVolumes\tVolumeAttachments")
 		}
 		fmt.Fprintf(w, "%d\t%d\t%d\t%d\t", clusterSizeData.Configmap, clusterSizeData.Secret, clusterSizeData.PersistentVolumeClaim, clusterSizeData.StorageClass)
 		fmt.Fprintf(w, "%d\t\n", clusterSizeData.VolumeAttachment)
-		if displayHeaders {
+		if displayOptions.Headers {
 			fmt.Fprintln(w, "METADATA APIs")
 			fmt.Fprintln(w, "Events\tLimitRanges\tPodDisruptionBudgets\tPodSecurityPolicies")
 		}
 		fmt.Fprintf(w, "%d\t%d\t%d\t%d\t\n", clusterSizeData.Event, clusterSizeData.LimitRange, clusterSizeData.PodDisruptionBudget, clusterSizeData.PodSecurityPolicy)
+		if displayOptions.Headers {
+			fmt.Fprintln(w, "ADD-ON APIs")
+			fmt.Fprintln(w, "VerticalPodAutoscalers\tMetricsAPI")
+		}
+		fmt.Fprintf(w, "%d\t%t\n", clusterSizeData.VerticalPodAutoscaler, clusterSizeData.MetricsAPI)
 
 		w.Flush()
 	}
 }
 
-func DisplayNodeRoleData(nodeRoleCapacityData map[string]*ClusterCapacityData, sortedRoleNames []string, displayDefault bool, displayHeaders bool, displayEphemeralStorage bool, displayFormat string) {
-	switch displayFormat {
+func DisplayNodeRoleData(out io.Writer, nodeRoleCapacityData map[string]*ClusterCapacityData, sortedRoleNames []string, displayOptions DisplayOptions) {
+	displayClusterGroupData(out, nodeRoleCapacityData, sortedRoleNames, "ROLE", "node_role", "role", displayOptions)
+}
+
+// DisplayNodeLabelData displays cluster capacity data grouped by the value of a node label (Ex topology.kubernetes.io/zone),
+// the last segment of the label key names the group column and the openmetrics label (Ex ZONE and zone)
+func DisplayNodeLabelData(out io.Writer, labelCapacityData map[string]*ClusterCapacityData, sortedLabelValues []string, labelKey string, displayOptions DisplayOptions) {
+	name := labelKey[strings.LastIndex(labelKey, "/")+1:]
+	labelName := strings.NewReplacer("-", "_", ".", "_").Replace(strings.ToLower(name))
+	displayClusterGroupData(out, labelCapacityData, sortedLabelValues, strings.ToUpper(name), "node_"+labelName, labelName, displayOptions)
+}
+
+// DisplayContextData displays cluster capacity data of multiple kubeconfig contexts
+func DisplayContextData(out io.Writer, contextCapacityData map[string]*ClusterCapacityData, sortedContextNames []string, displayOptions DisplayOptions) {
+	displayClusterGroupData(out, contextCapacityData, sortedContextNames, "CONTEXT", "cluster", "context", displayOptions)
+}
+
+// displayClusterGroupData displays cluster capacity data grouped by a name (Ex node-role or context), groupName is the
+// table header of the group column, subsystem and labelName name the openmetrics metrics and group label
+func displayClusterGroupData(out io.Writer, groupCapacityData map[string]*ClusterCapacityData, sortedGroupNames []string, groupName, subsystem, labelName string, displayOptions DisplayOptions) {
+	switch displayOptions.Format {
 	case jsonDisplay:
-		jsonNodeRoleData, err := json.MarshalIndent(&nodeRoleCapacityData, "", "  ")
+		jsonGroupData, err := marshalJSON(withMetadata(&groupCapacityData, displayOptions), displayOptions.Compact)
 		if err != nil {
-			fmt.Println(err)
+			fmt.Fprintln(out, err)
 			return
 		}
-		fmt.Println(string(jsonNodeRoleData))
+		fmt.Fprintln(out, string(jsonGroupData))
 	case yamlDisplay:
-		yamlNodeRoleData, err := yaml.Marshal(nodeRoleCapacityData)
+		yamlGroupData, err := yaml.Marshal(withMetadata(groupCapacityData, displayOptions))
 		if err != nil {
-			fmt.Println(err)
+			fmt.Fprintln(out, err)
 			return
 		}
-		fmt.Print(string(yamlNodeRoleData))
+		fmt.Fprint(out, string(yamlGroupData))
+	case openMetricsDisplay, jsonlDisplay, customColumnsDisplay:
+		rows := make([]metricsRow, 0, len(sortedGroupNames))
+		for _, k := range sortedGroupNames {
+			rows = append(rows, metricsRow{labelValue: k, data: reflect.ValueOf(*groupCapacityData[k])})
+		}
+		writeRows(out, displayOptions, subsystem, labelName, rows)
 	default:
-		w := new(tabwriter.Writer)
-		w.Init(os.Stdout, 0, 5, 1, ' ', 0)
-		if displayHeaders {
-			if displayDefault {
-				fmt.Fprintf(w, "ROLE\tNODES\t\t\t\tPODS\t\t\t\t\tCPU\t\t\t\t\tMEMORY\t\t\t\t\t")
-				if displayEphemeralStorage {
-					fmt.Fprintf(w, "EPHEMERAL STORAGE")
-				}
-				fmt.Fprintln(w, "")
-			} else {
-				fmt.Fprintf(w, "ROLE\tNODES\t\t\t\tPODS\t\t\t\t\tCPU (cores)\t\t\t\t\tMEMORY (GiB)\t\t\t\t\t")
-				if displayEphemeralStorage {
-					fmt.Fprintf(w, "EPHEMERAL STORAGE (GB)")
-				}
-				fmt.Fprintln(w, "")
-			}
-			fmt.Fprintf(w, "\tTotal\tReady\tUnready\tUnsch\tCapacity\tAllocatable\tTotal\tNon-Term\tAvail\tCapacity\tAllocatable\tRequests\tLimits\tAvail\tCapacity\tAllocatable\tRequests\tLimits\tAvail\t")
-			if displayEphemeralStorage {
-				fmt.Fprintf(w, "Capacity\tAllocatable\tRequests\tLimits\tAvail")
+		w := newTableWriter(out, displayOptions, 2)
+		if displayOptions.Hugepages {
+			for _, k := range sortedGroupNames {
+				displayOptions.hugepageSizes = hugepageSizes(displayOptions.hugepageSizes, groupCapacityData[k].Hugepages)
 			}
-			fmt.Fprintln(w, "")
 		}
-		for _, k := range sortedRoleNames {
+		if displayOptions.Headers {
+			printClusterHeaders(w, groupName, displayOptions)
+		}
+		for _, k := range sortedGroupNames {
 			fmt.Fprintf(w, "%s\t", k)
-			fmt.Fprintf(w, "%d\t%d\t%d\t%d\t", nodeRoleCapacityData[k].TotalNodeCount, nodeRoleCapacityData[k].TotalReadyNodeCount, nodeRoleCapacityData[k].TotalUnreadyNodeCount, nodeRoleCapacityData[k].TotalUnschedulableNodeCount)
-			fmt.Fprintf(w, "%s\t%s\t", &nodeRoleCapacityData[k].TotalCapacityPods, &nodeRoleCapacityData[k].TotalAllocatablePods)
-			fmt.Fprintf(w, "%d\t%d\t", nodeRoleCapacityData[k].TotalPodCount, nodeRoleCapacityData[k].TotalNonTermPodCount)
-			fmt.Fprintf(w, "%d\t", nodeRoleCapacityData[k].TotalAvailablePods)
-			if displayDefault {
-				fmt.Fprintf(w, "%s\t%s\t", &nodeRoleCapacityData[k].TotalCapacityCPU, &nodeRoleCapacityData[k].TotalAllocatableCPU)
-				fmt.Fprintf(w, "%s\t%s\t", &nodeRoleCapacityData[k].TotalRequestsCPU, &nodeRoleCapacityData[k].TotalLimitsCPU)
-				fmt.Fprintf(w, "%s\t", &nodeRoleCapacityData[k].TotalAvailableCPU)
-				fmt.Fprintf(w, "%s\t%s\t", &nodeRoleCapacityData[k].TotalCapacityMemory, &nodeRoleCapacityData[k].TotalAllocatableMemory)
-				fmt.Fprintf(w, "%s\t%s\t", &nodeRoleCapacityData[k].TotalRequestsMemory, &nodeRoleCapacityData[k].TotalLimitsMemory)
-				fmt.Fprintf(w, "%s\t", &nodeRoleCapacityData[k].TotalAvailableMemory)
-				if displayEphemeralStorage {
-					fmt.Fprintf(w, "%s\t%s\t", &nodeRoleCapacityData[k].TotalCapacityEphemeralStorage, &nodeRoleCapacityData[k].TotalAllocatableEphemeralStorage)
-					fmt.Fprintf(w, "%s\t%s\t", &nodeRoleCapacityData[k].TotalRequestsEphemeralStorage, &nodeRoleCapacityData[k].TotalLimitsEphemeralStorage)
-					fmt.Fprintf(w, "%s\t", &nodeRoleCapacityData[k].TotalAvailableEphemeralStorage)
-				}
-				fmt.Fprintln(w, "")
-			} else {
-				fmt.Fprintf(w, "%.1f\t%.1f\t", nodeRoleCapacityData[k].TotalCapacityCPUCores, nodeRoleCapacityData[k].TotalAllocatableCPUCores)
-				fmt.Fprintf(w, "%.1f\t%.1f\t", nodeRoleCapacityData[k].TotalRequestsCPUCores, nodeRoleCapacityData[k].TotalLimitsCPUCores)
-				fmt.Fprintf(w, "%.1f\t", nodeRoleCapacityData[k].TotalAvailableCPUCores)
-				fmt.Fprintf(w, "%.1f\t%.1f\t", nodeRoleCapacityData[k].TotalCapacityMemoryGiB, nodeRoleCapacityData[k].TotalAllocatableMemoryGiB)
-				fmt.Fprintf(w, "%.1f\t%.1f\t", nodeRoleCapacityData[k].TotalRequestsMemoryGiB, nodeRoleCapacityData[k].TotalLimitsMemoryGiB)
-				fmt.Fprintf(w, "%.1f\t", nodeRoleCapacityData[k].TotalAvailableMemoryGiB)
-				if displayEphemeralStorage {
-					fmt.Fprintf(w, "%.1f\t%.1f\t", nodeRoleCapacityData[k].TotalCapacityEphemeralStorageGB, nodeRoleCapacityData[k].TotalAllocatableEphemeralStorageGB)
-					fmt.Fprintf(w, "%.1f\t%.1f\t", nodeRoleCapacityData[k].TotalRequestsEphemeralStorageGB, nodeRoleCapacityData[k].TotalLimitsEphemeralStorageGB)
-					fmt.Fprintf(w, "%.1f\t", nodeRoleCapacityData[k].TotalAvailableEphemeralStorageGB)
-				}
-				fmt.Fprintln(w, "")
-			}
+			printClusterData(w, groupCapacityData[k], displayOptions)
 		}
 		w.Flush()
 	}
 }
 
-func DisplayNodeData(nodesCapacityData map[string]*NodeCapacityData, sortedNodeNames []string, displayDefault bool, displayHeaders bool, displayEphemeralStorage bool, displayFormat string, sortByRole bool, nodesByRole map[string][]string) {
-	switch displayFormat {
+func DisplayNodeData(out io.Writer, nodesCapacityData map[string]*NodeCapacityData, sortedNodeNames []string, nodesByRole map[string][]string, displayOptions DisplayOptions) {
+	switch displayOptions.Format {
 	case jsonDisplay:
-		jsonNodeData, err := json.MarshalIndent(&nodesCapacityData, "", "  ")
+		jsonNodeData, err := marshalJSON(withMetadata(&nodesCapacityData, displayOptions), displayOptions.Compact)
 		if err != nil {
-			fmt.Println(err)
+			fmt.Fprintln(out, err)
 			return
 		}
-		fmt.Println(string(jsonNodeData))
+		fmt.Fprintln(out, string(jsonNodeData))
 	case yamlDisplay:
-		yamlNodeData, err := yaml.Marshal(nodesCapacityData)
+		yamlNodeData, err := yaml.Marshal(withMetadata(nodesCapacityData, displayOptions))
 		if err != nil {
-			fmt.Println(err)
+			fmt.Fprintln(out, err)
 			return
 		}
-		fmt.Print(string(yamlNodeData))
+		fmt.Fprint(out, string(yamlNodeData))
+	case openMetricsDisplay, jsonlDisplay, customColumnsDisplay:
+		rows := make([]metricsRow, 0, len(sortedNodeNames))
+		for _, k := range sortedNodeNames {
+			rows = append(rows, metricsRow{labelValue: k, data: reflect.ValueOf(*nodesCapacityData[k])})
+		}
+		writeRows(out, displayOptions, "node", "node", rows)
 	default:
-		w := new(tabwriter.Writer)
-		w.Init(os.Stdout, 0, 5, 1, ' ', 0)
-		if displayHeaders {
-			if displayDefault {
-				fmt.Fprintf(w, "NAME\tSTATUS\tROLES\tPODS\t\t\t\t\tCPU\t\t\t\t\tMEMORY\t\t\t\t\t")
-				if displayEphemeralStorage {
-					fmt.Fprintf(w, "EPHEMERAL STORAGE")
-				}
-				fmt.Fprintln(w, "")
-			} else {
-				fmt.Fprintf(w, "NAME\tSTATUS\tROLES\tPODS\t\t\t\t\tCPU (cores)\t\t\t\t\tMEMORY (GiB)\t\t\t\t\t")
-				if displayEphemeralStorage {
-					fmt.Fprintf(w, "EPHEMERAL STORAGE (GB)")
+		w := newTableWriter(out, displayOptions, 2)
+		if displayOptions.Hugepages {
+			for _, k := range sortedNodeNames {
+				displayOptions.hugepageSizes = hugepageSizes(displayOptions.hugepageSizes, nodesCapacityData[k].Hugepages)
+			}
+		}
+		if displayOptions.Headers {
+			fmt.Fprintf(w, "NAME\tSTATUS\tROLES\t")
+			if displayOptions.Age {
+				fmt.Fprintf(w, "AGE\t")
+			}
+			if displayOptions.Versions {
+				fmt.Fprintf(w, "VERSION\tOS-IMAGE\tKERNEL-VERSION\t")
+			}
+			fmt.Fprintf(w, "PODS\t\t\t\t\t")
+			if displayOptions.PodReadiness {
+				fmt.Fprintf(w, "\t")
+			}
+			printResourceHeaders(w, displayOptions)
+			fmt.Fprintf(w, "CONTAINERS\t\t")
+			if displayOptions.Phases {
+				fmt.Fprintf(w, "PHASES\t\t\t\t")
+			}
+			if displayOptions.GPU {
+				fmt.Fprintf(w, "GPU\t\t\t\t")
+			}
+			printExtraResourcesHeaders(w, displayOptions)
+			printHugepagesHeaders(w, displayOptions)
+			printReservedHeaders(w, displayOptions)
+			if displayOptions.Efficiency {
+				fmt.Fprintf(w, "EFFICIENCY (%%)\t\t")
+			}
+			printLimitsAvailableHeaders(w, displayOptions)
+			if displayOptions.Utilization {
+				printUtilizationHeaders(w, displayOptions)
+			}
+			if displayOptions.Ratios {
+				fmt.Fprintf(w, "LIMIT/REQUEST RATIO\t\t")
+			}
+			if displayOptions.Usage {
+				if displayOptions.Default {
+					fmt.Fprintf(w, "USAGE\t\t")
+				} else {
+					fmt.Fprintf(w, "USAGE (cores/%s)\t\t", capacity.MemoryUnit())
 				}
-				fmt.Fprintln(w, "")
 			}
-			fmt.Fprintf(w, "\t\t\tCapacity\tAllocatable\tTotal\tNon-Term\tAvail\tCapacity\tAllocatable\tRequests\tLimits\tAvail\tCapacity\tAllocatable\tRequests\tLimits\tAvail\t")
-			if displayEphemeralStorage {
-				fmt.Fprintf(w, "Capacity\tAllocatable\tRequests\tLimits\tAvail")
+			if displayOptions.PodReservation {
+				fmt.Fprintf(w, "POD RESERVATION\t")
+			}
+			if displayOptions.Taints {
+				fmt.Fprintf(w, "TAINTS\t")
+			}
+			fmt.Fprintln(w, "")
+			fmt.Fprintf(w, "\t\t\t")
+			if displayOptions.Age {
+				fmt.Fprintf(w, "\t")
+			}
+			if displayOptions.Versions {
+				fmt.Fprintf(w, "\t\t\t")
+			}
+			fmt.Fprintf(w, "Capacity\tAllocatable\tTotal\tNon-Term\t")
+			if displayOptions.PodReadiness {
+				fmt.Fprintf(w, "Ready\t")
+			}
+			fmt.Fprintf(w, "Avail\t")
+			printResourceSubHeaders(w, displayOptions)
+			fmt.Fprintf(w, "Total\tInit\t")
+			if displayOptions.Phases {
+				fmt.Fprintf(w, "Running\tPending\tSucceeded\tFailed\t")
+			}
+			if displayOptions.GPU {
+				fmt.Fprintf(w, "Capacity\tAllocatable\tRequests\tAvail\t")
+			}
+			for range displayOptions.ExtraResources {
+				fmt.Fprintf(w, "Capacity\tAllocatable\tRequests\t")
+			}
+			for range displayOptions.hugepageSizes {
+				fmt.Fprintf(w, "Capacity\tAllocatable\tRequests\tAvail\t")
+			}
+			if displayOptions.Reserved {
+				fmt.Fprintf(w, "CPU\tMemory\tStorage\t")
+			}
+			if displayOptions.Efficiency {
+				fmt.Fprintf(w, "CPU\tMemory\t")
+			}
+			if displayOptions.LimitsAvailable {
+				fmt.Fprintf(w, "CPU\tMemory\t")
+			}
+			if displayOptions.Utilization {
+				printUtilizationSubHeaders(w, displayOptions)
+			}
+			if displayOptions.Ratios {
+				fmt.Fprintf(w, "CPU\tMemory\t")
+			}
+			if displayOptions.Usage {
+				fmt.Fprintf(w, "CPU\tMemory\t")
+			}
+			if displayOptions.PodReservation {
+				fmt.Fprintf(w, "Gap\t")
+			}
+			if displayOptions.Taints {
+				fmt.Fprintf(w, "\t")
 			}
 			fmt.Fprintln(w, "")
 		}
 
-		if sortByRole {
+		if displayOptions.SortByRole {
 			// Sort by role
 			roles := make([]string, 0, len(nodesByRole))
 			for role := range nodesByRole {
@@ -431,136 +1269,875 @@ func DisplayNodeData(nodesCapacityData map[string]*NodeCapacityData, sortedNodeN
 
 			for _, role := range roles {
 				for _, node := range nodesByRole[role] {
-					printNodeData(w, node, nodesCapacityData[node], displayDefault, displayEphemeralStorage)
+					printNodeData(w, node, nodesCapacityData[node], displayOptions)
 				}
 			}
 		} else {
 			// Sort by Node Name
 			for _, k := range sortedNodeNames {
-				printNodeData(w, k, nodesCapacityData[k], displayDefault, displayEphemeralStorage)
+				printNodeData(w, k, nodesCapacityData[k], displayOptions)
 			}
 		}
 
 		w.Flush()
+		if displayOptions.Utilization {
+			printFullNodesFooter(out, nodesCapacityData, sortedNodeNames, displayOptions.FullThreshold)
+		}
+		if displayOptions.Versions {
+			printVersionsFooter(out, nodesCapacityData, sortedNodeNames)
+		}
 	}
 }
 
-func printNodeData(w *tabwriter.Writer, nodeName string, nodeData *NodeCapacityData, displayDefault bool, displayEphemeralStorage bool) {
-	fmt.Fprintf(w, "%s\t", nodeName)
-	if nodeName != "*unassigned*" && nodeName != "*total*" {
-		if nodeData.Ready {
-			fmt.Fprint(w, "Ready")
-		} else {
-			fmt.Fprint(w, "NotReady")
+// printVersionsFooter prints the kubelet versions of the displayed nodes with their node counts, most common first, so
+// nodes left behind by an upgrade stand out. Pseudo-rows (Ex *total*) are not counted.
+func printVersionsFooter(out io.Writer, nodesCapacityData map[string]*NodeCapacityData, sortedNodeNames []string) {
+	versionCounts := make(map[string]int)
+	for _, k := range sortedNodeNames {
+		if strings.HasPrefix(k, "*") {
+			continue
 		}
-		if !nodeData.Schedulable {
-			fmt.Fprintf(w, ",Unschedulable")
+		versionCounts[nodesCapacityData[k].KubeletVersion]++
+	}
+	if len(versionCounts) == 0 {
+		return
+	}
+	versions := make([]string, 0, len(versionCounts))
+	for version := range versionCounts {
+		versions = append(versions, version)
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		if versionCounts[versions[i]] != versionCounts[versions[j]] {
+			return versionCounts[versions[i]] > versionCounts[versions[j]]
+		}
+		return versions[i] < versions[j]
+	})
+	counts := make([]string, 0, len(versions))
+	for _, version := range versions {
+		nodes := "nodes"
+		if versionCounts[version] == 1 {
+			nodes = "node"
+		}
+		counts = append(counts, fmt.Sprintf("%s (%d %s)", version, versionCounts[version], nodes))
+	}
+	fmt.Fprintln(out, "")
+	fmt.Fprintf(out, "Kubelet versions: %s\n", strings.Join(counts, ", "))
+}
+
+// printFullNodesFooter prints how many of the displayed nodes have cpu or memory requests utilization above
+// fullThreshold percent, nodes which are unlikely to fit another average pod. Pseudo-rows (Ex *total*) are not counted.
+func printFullNodesFooter(out io.Writer, nodesCapacityData map[string]*NodeCapacityData, sortedNodeNames []string, fullThreshold float64) {
+	nodeCount, fullCount := 0, 0
+	for _, k := range sortedNodeNames {
+		if strings.HasPrefix(k, "*") {
+			continue
+		}
+		nodeCount++
+		if nodesCapacityData[k].RequestsCPUUtilization > fullThreshold || nodesCapacityData[k].RequestsMemoryUtilization > fullThreshold {
+			fullCount++
+		}
+	}
+	if nodeCount == 0 {
+		return
+	}
+	fmt.Fprintln(out, "")
+	fmt.Fprintf(out, "Nearly full: %d of %d nodes above %g%% cpu or memory requests\n", fullCount, nodeCount, fullThreshold)
+}
+
+func printNodeData(w io.Writer, nodeName string, nodeData *NodeCapacityData, displayOptions DisplayOptions) {
+	fmt.Fprintf(w, "%s\t", nodeName)
+	if nodeName != "*unassigned*" && nodeName != "*total*" {
+		if nodeData.Ready {
+			fmt.Fprint(w, "Ready")
+		} else {
+			fmt.Fprint(w, "NotReady")
+		}
+		if !nodeData.Schedulable {
+			fmt.Fprintf(w, ",Unschedulable")
+		}
+		// Pressure conditions mark nodes which are Ready but should not receive new pods
+		if nodeData.MemoryPressure {
+			fmt.Fprintf(w, ",MemoryPressure")
+		}
+		if nodeData.DiskPressure {
+			fmt.Fprintf(w, ",DiskPressure")
+		}
+		if nodeData.PIDPressure {
+			fmt.Fprintf(w, ",PIDPressure")
+		}
+		if nodeData.EvictionRisk {
+			fmt.Fprintf(w, ",EvictionRisk")
+		}
+		if nodeData.PodReservationExceeded {
+			fmt.Fprintf(w, ",PodReservation")
 		}
 	}
 	fmt.Fprintf(w, "\t")
 	fmt.Fprintf(w, "%s\t", strings.Join(nodeData.Roles.List(), ","))
-	fmt.Fprintf(w, "%s\t%s\t", &nodeData.TotalCapacityPods, &nodeData.TotalCapacityPods)
+	if displayOptions.Age {
+		// Formatted like the AGE column of kubectl get nodes, the *total* and *unassigned* rows have no age
+		if nodeData.CreationTimestamp == nil {
+			fmt.Fprintf(w, "-\t")
+		} else {
+			fmt.Fprintf(w, "%s\t", duration.HumanDuration(time.Since(*nodeData.CreationTimestamp)))
+		}
+	}
+	if displayOptions.Versions {
+		for _, version := range []string{nodeData.KubeletVersion, nodeData.OSImage, nodeData.KernelVersion} {
+			if version == "" {
+				version = "-"
+			}
+			fmt.Fprintf(w, "%s\t", version)
+		}
+	}
+	fmt.Fprintf(w, "%s\t%s\t", &nodeData.TotalCapacityPods, &nodeData.TotalAllocatablePods)
 	fmt.Fprintf(w, "%d\t%d\t", nodeData.TotalPodCount, nodeData.TotalNonTermPodCount)
+	if displayOptions.PodReadiness {
+		fmt.Fprintf(w, "%d\t", nodeData.ReadyPodCount)
+	}
 	fmt.Fprintf(w, "%d\t", nodeData.TotalAvailablePods)
-	if displayDefault {
-		fmt.Fprintf(w, "%s\t%s\t", &nodeData.TotalCapacityCPU, &nodeData.TotalAllocatableCPU)
-		fmt.Fprintf(w, "%s\t%s\t", &nodeData.TotalRequestsCPU, &nodeData.TotalLimitsCPU)
-		fmt.Fprintf(w, "%s\t", &nodeData.TotalAvailableCPU)
-		fmt.Fprintf(w, "%s\t%s\t", &nodeData.TotalCapacityMemory, &nodeData.TotalAllocatableMemory)
-		fmt.Fprintf(w, "%s\t%s\t", &nodeData.TotalRequestsMemory, &nodeData.TotalLimitsMemory)
-		fmt.Fprintf(w, "%s\t", &nodeData.TotalAvailableMemory)
-		if displayEphemeralStorage {
-			fmt.Fprintf(w, "%s\t%s\t", &nodeData.TotalCapacityEphemeralStorage, &nodeData.TotalAllocatableEphemeralStorage)
-			fmt.Fprintf(w, "%s\t%s\t", &nodeData.TotalRequestsEphemeralStorage, &nodeData.TotalLimitsEphemeralStorage)
-			fmt.Fprintf(w, "%s\t", &nodeData.TotalAvailableEphemeralStorage)
-		}
-		fmt.Fprintln(w, "")
+	printResourceColumns(w, [5]*resource.Quantity{&nodeData.TotalCapacityCPU, &nodeData.TotalAllocatableCPU, &nodeData.TotalRequestsCPU, &nodeData.TotalLimitsCPU,
+		&nodeData.TotalAvailableCPU}, [5]float64{nodeData.TotalCapacityCPUCores, nodeData.TotalAllocatableCPUCores, nodeData.TotalRequestsCPUCores,
+		nodeData.TotalLimitsCPUCores, nodeData.TotalAvailableCPUCores}, displayOptions)
+	printResourceColumns(w, [5]*resource.Quantity{&nodeData.TotalCapacityMemory, &nodeData.TotalAllocatableMemory, &nodeData.TotalRequestsMemory,
+		&nodeData.TotalLimitsMemory, &nodeData.TotalAvailableMemory}, [5]float64{capacity.TableMem(nodeData.TotalCapacityMemoryGiB),
+		capacity.TableMem(nodeData.TotalAllocatableMemoryGiB), capacity.TableMem(nodeData.TotalRequestsMemoryGiB), capacity.TableMem(nodeData.TotalLimitsMemoryGiB),
+		capacity.TableMem(nodeData.TotalAvailableMemoryGiB)}, displayOptions)
+	if displayOptions.EphemeralStorage {
+		printResourceColumns(w, [5]*resource.Quantity{&nodeData.TotalCapacityEphemeralStorage, &nodeData.TotalAllocatableEphemeralStorage,
+			&nodeData.TotalRequestsEphemeralStorage, &nodeData.TotalLimitsEphemeralStorage, &nodeData.TotalAvailableEphemeralStorage},
+			[5]float64{capacity.TableStorage(nodeData.TotalCapacityEphemeralStorageGB), capacity.TableStorage(nodeData.TotalAllocatableEphemeralStorageGB),
+				capacity.TableStorage(nodeData.TotalRequestsEphemeralStorageGB), capacity.TableStorage(nodeData.TotalLimitsEphemeralStorageGB),
+				capacity.TableStorage(nodeData.TotalAvailableEphemeralStorageGB)}, displayOptions)
+	}
+	if displayOptions.Default {
+		fmt.Fprintf(w, "%d\t%d\t", nodeData.TotalContainerCount, nodeData.TotalInitContainerCount)
+		if displayOptions.Phases {
+			printPhases(w, nodeData.RunningPodCount, nodeData.PendingPodCount, nodeData.SucceededPodCount, nodeData.FailedPodCount)
+		}
+		if displayOptions.GPU {
+			fmt.Fprintf(w, "%s\t%s\t", &nodeData.TotalCapacityGPU, &nodeData.TotalAllocatableGPU)
+			fmt.Fprintf(w, "%s\t%s\t", &nodeData.TotalRequestsGPU, &nodeData.TotalAvailableGPU)
+		}
 	} else {
-		fmt.Fprintf(w, "%.1f\t%.1f\t", nodeData.TotalCapacityCPUCores, nodeData.TotalAllocatableCPUCores)
-		fmt.Fprintf(w, "%.1f\t%.1f\t", nodeData.TotalRequestsCPUCores, nodeData.TotalLimitsCPUCores)
-		fmt.Fprintf(w, "%.1f\t", nodeData.TotalAvailableCPUCores)
-		fmt.Fprintf(w, "%.1f\t%.1f\t", nodeData.TotalCapacityMemoryGiB, nodeData.TotalAllocatableMemoryGiB)
-		fmt.Fprintf(w, "%.1f\t%.1f\t", nodeData.TotalRequestsMemoryGiB, nodeData.TotalLimitsMemoryGiB)
-		fmt.Fprintf(w, "%.1f\t", nodeData.TotalAvailableMemoryGiB)
-		if displayEphemeralStorage {
-			fmt.Fprintf(w, "%.1f\t%.1f\t", nodeData.TotalCapacityEphemeralStorageGB, nodeData.TotalAllocatableEphemeralStorageGB)
-			fmt.Fprintf(w, "%.1f\t%.1f\t", nodeData.TotalRequestsEphemeralStorageGB, nodeData.TotalLimitsEphemeralStorageGB)
-			fmt.Fprintf(w, "%.1f\t", nodeData.TotalAvailableEphemeralStorageGB)
+		fmt.Fprintf(w, "%d\t%d\t", nodeData.TotalContainerCount, nodeData.TotalInitContainerCount)
+		if displayOptions.Phases {
+			printPhases(w, nodeData.RunningPodCount, nodeData.PendingPodCount, nodeData.SucceededPodCount, nodeData.FailedPodCount)
+		}
+		if displayOptions.GPU {
+			fmt.Fprintf(w, "%d\t%d\t", nodeData.TotalCapacityGPUCount, nodeData.TotalAllocatableGPUCount)
+			fmt.Fprintf(w, "%d\t%d\t", nodeData.TotalRequestsGPUCount, nodeData.TotalAvailableGPUCount)
 		}
-		fmt.Fprintln(w, "")
 	}
+	printExtraResourcesData(w, nodeData.ExtraResources, displayOptions)
+	printHugepagesData(w, nodeData.Hugepages, displayOptions)
+	if displayOptions.Reserved {
+		printReservedData(w, nodeData.TotalReservedCPU, nodeData.TotalReservedMemory, nodeData.TotalReservedEphemeralStorage, displayOptions)
+	}
+	if displayOptions.Efficiency {
+		printUtilization(w, nodeData.AllocatableCPUEfficiency, nodeData.TotalCapacityCPU)
+		printUtilization(w, nodeData.AllocatableMemoryEfficiency, nodeData.TotalCapacityMemory)
+	}
+	if displayOptions.LimitsAvailable {
+		printLimitsAvailableData(w, nodeData.TotalLimitsAvailableCPU, nodeData.TotalLimitsAvailableMemory, displayOptions)
+	}
+	if displayOptions.Utilization {
+		printRequestsUtilization(w, nodeData.RequestsCPUUtilization, nodeData.TotalAllocatableCPU, displayOptions)
+		printRequestsUtilization(w, nodeData.RequestsMemoryUtilization, nodeData.TotalAllocatableMemory, displayOptions)
+		printUtilization(w, nodeData.PodUtilization, nodeData.TotalAllocatablePods)
+	}
+	if displayOptions.Ratios {
+		printRatio(w, nodeData.CPULimitRequestRatio, nodeData.TotalRequestsCPU)
+		printRatio(w, nodeData.MemoryLimitRequestRatio, nodeData.TotalRequestsMemory)
+	}
+	if displayOptions.Usage {
+		if displayOptions.Default {
+			fmt.Fprintf(w, "%s\t%s\t", &nodeData.TotalUsageCPU, &nodeData.TotalUsageMemory)
+		} else {
+			fmt.Fprintf(w, "%s\t%s\t", readable(nodeData.TotalUsageCPUCores, displayOptions), readable(capacity.TableMem(nodeData.TotalUsageMemoryGiB), displayOptions))
+		}
+	}
+	if displayOptions.PodReservation {
+		fmt.Fprintf(w, "%d\t", nodeData.PodReservationGap)
+	}
+	if displayOptions.Taints {
+		if len(nodeData.Taints) == 0 {
+			fmt.Fprintf(w, "<none>\t")
+		} else {
+			fmt.Fprintf(w, "%s\t", strings.Join(nodeData.Taints, ","))
+		}
+	}
+	fmt.Fprintln(w, "")
 }
 
-func DisplayNamespaceData(namespaceCapacityData map[string]*NamespaceCapacityData, sortedNamespaceNames []string, displayDefault bool, displayHeaders bool, displayEphemeralStorage bool, displayFormat string, displayAllNamespaces bool) {
-	switch displayFormat {
+func DisplayNamespaceData(out io.Writer, namespaceCapacityData map[string]*NamespaceCapacityData, sortedNamespaceNames []string, displayOptions DisplayOptions) {
+	switch displayOptions.Format {
 	case jsonDisplay:
-		jsonNamespaceData, err := json.MarshalIndent(&namespaceCapacityData, "", "  ")
+		jsonNamespaceData, err := marshalJSON(withMetadata(&namespaceCapacityData, displayOptions), displayOptions.Compact)
 		if err != nil {
-			fmt.Println(err)
+			fmt.Fprintln(out, err)
 			return
 		}
-		fmt.Println(string(jsonNamespaceData))
+		fmt.Fprintln(out, string(jsonNamespaceData))
 	case yamlDisplay:
-		yamlNamespaceData, err := yaml.Marshal(namespaceCapacityData)
+		yamlNamespaceData, err := yaml.Marshal(withMetadata(namespaceCapacityData, displayOptions))
 		if err != nil {
-			fmt.Println(err)
+			fmt.Fprintln(out, err)
 			return
 		}
-		fmt.Print(string(yamlNamespaceData))
+		fmt.Fprint(out, string(yamlNamespaceData))
+	case openMetricsDisplay, jsonlDisplay, customColumnsDisplay:
+		rows := make([]metricsRow, 0, len(sortedNamespaceNames))
+		for _, k := range sortedNamespaceNames {
+			rows = append(rows, metricsRow{labelValue: k, data: reflect.ValueOf(*namespaceCapacityData[k])})
+		}
+		writeRows(out, displayOptions, "namespace", "namespace", rows)
 	default:
-		w := new(tabwriter.Writer)
-		w.Init(os.Stdout, 0, 5, 1, ' ', 0)
-		if displayHeaders {
-			if displayDefault {
-				fmt.Fprintf(w, "NAMESPACE\tPODS\t\t\tCPU\t\tMEMORY\t\t")
-				if displayEphemeralStorage {
-					fmt.Fprintf(w, "EPHEMERAL STORAGE")
+		w := newTableWriter(out, displayOptions, 2)
+		if displayOptions.Headers {
+			if displayOptions.Default {
+				fmt.Fprintf(w, "NAMESPACE\tPODS\t\t\t")
+				if displayOptions.PodReadiness {
+					fmt.Fprintf(w, "\t")
+				}
+				fmt.Fprintf(w, "CPU\t\tMEMORY\t\t")
+				if displayOptions.EphemeralStorage {
+					fmt.Fprintf(w, "EPHEMERAL STORAGE\t\t")
 				}
-				fmt.Fprintln(w, "")
 			} else {
-				fmt.Fprintf(w, "NAMESPACE\tPODS\t\t\tCPU (cores)\t\tMEMORY (GiB)\t\t")
-				if displayEphemeralStorage {
-					fmt.Fprintf(w, "EPHEMERAL STORAGE (GB)")
+				fmt.Fprintf(w, "NAMESPACE\tPODS\t\t\t")
+				if displayOptions.PodReadiness {
+					fmt.Fprintf(w, "\t")
 				}
-				fmt.Fprintln(w, "")
+				fmt.Fprintf(w, "CPU (cores)\t\tMEMORY (%s)\t\t", capacity.MemoryUnit())
+				if displayOptions.EphemeralStorage {
+					fmt.Fprintf(w, "EPHEMERAL STORAGE (%s)\t\t", capacity.StorageUnit())
+				}
+			}
+			fmt.Fprintf(w, "CONTAINERS\t\t")
+			if displayOptions.Phases {
+				fmt.Fprintf(w, "PHASES\t\t\t\t")
+			}
+			if displayOptions.Stats {
+				fmt.Fprintf(w, "STATS (per pod)\t\t\t")
 			}
-			fmt.Fprintf(w, "\tTotal\tNon-Term\tUnassigned\tRequests\tLimits\tRequests\tLimits\t")
-			if displayEphemeralStorage {
-				fmt.Fprintf(w, "Requests\tLimits")
+			if displayOptions.Ratios {
+				fmt.Fprintf(w, "LIMIT/REQUEST RATIO\t\t")
+			}
+			if displayOptions.ClusterPercent {
+				fmt.Fprintf(w, "%% OF CLUSTER REQUESTS\t\t")
+			}
+			if displayOptions.NodeSpread {
+				fmt.Fprintf(w, "NODES\t")
+			}
+			if displayOptions.Quota {
+				fmt.Fprintf(w, "POD QUOTA")
+			}
+			fmt.Fprintln(w, "")
+			fmt.Fprintf(w, "\tTotal\tNon-Term\t")
+			if displayOptions.PodReadiness {
+				fmt.Fprintf(w, "Ready\t")
+			}
+			fmt.Fprintf(w, "Unassigned\tRequests\tLimits\tRequests\tLimits\t")
+			if displayOptions.EphemeralStorage {
+				fmt.Fprintf(w, "Requests\tLimits\t")
+			}
+			fmt.Fprintf(w, "Total\tInit\t")
+			if displayOptions.Phases {
+				fmt.Fprintf(w, "Running\tPending\tSucceeded\tFailed\t")
+			}
+			if displayOptions.Stats {
+				fmt.Fprintf(w, "Containers\tCPU (cores)\tMemory (%s)\t", capacity.MemoryUnit())
+			}
+			if displayOptions.Ratios {
+				fmt.Fprintf(w, "CPU\tMemory\t")
+			}
+			if displayOptions.ClusterPercent {
+				fmt.Fprintf(w, "CPU\tMemory\t")
+			}
+			if displayOptions.NodeSpread {
+				fmt.Fprintf(w, "Distinct\t")
+			}
+			if displayOptions.Quota {
+				fmt.Fprintf(w, "Hard\tUsed")
 			}
 			fmt.Fprintln(w, "")
 		}
 		for _, k := range sortedNamespaceNames {
-			if (namespaceCapacityData[k].TotalPodCount != 0) || displayAllNamespaces {
+			// The *total* "namespace" is always shown, --min-pods only hides the namespaces themselves
+			if ((namespaceCapacityData[k].TotalPodCount != 0) || displayOptions.AllNamespaces) && (namespaceCapacityData[k].TotalPodCount >= displayOptions.MinPods || k == "*total*") {
 				fmt.Fprintf(w, "%s\t", k)
-				fmt.Fprintf(w, "%d\t%d\t%d\t", namespaceCapacityData[k].TotalPodCount, namespaceCapacityData[k].TotalNonTermPodCount, namespaceCapacityData[k].TotalUnassignedNodePodCount)
-				if displayDefault {
+				fmt.Fprintf(w, "%d\t%d\t", namespaceCapacityData[k].TotalPodCount, namespaceCapacityData[k].TotalNonTermPodCount)
+				if displayOptions.PodReadiness {
+					fmt.Fprintf(w, "%d\t", namespaceCapacityData[k].ReadyPodCount)
+				}
+				fmt.Fprintf(w, "%d\t", namespaceCapacityData[k].TotalUnassignedNodePodCount)
+				if displayOptions.Default {
 					fmt.Fprintf(w, "%s\t%s\t", &namespaceCapacityData[k].TotalRequestsCPU, &namespaceCapacityData[k].TotalLimitsCPU)
 					fmt.Fprintf(w, "%s\t%s\t", &namespaceCapacityData[k].TotalRequestsMemory, &namespaceCapacityData[k].TotalLimitsMemory)
-					if displayEphemeralStorage {
+					if displayOptions.EphemeralStorage {
 						fmt.Fprintf(w, "%s\t%s\t", &namespaceCapacityData[k].TotalRequestsEphemeralStorage, &namespaceCapacityData[k].TotalLimitsEphemeralStorage)
 					}
-					fmt.Fprintln(w, "")
 				} else {
-					fmt.Fprintf(w, "%.1f\t%.1f\t", namespaceCapacityData[k].TotalRequestsCPUCores, namespaceCapacityData[k].TotalLimitsCPUCores)
-					fmt.Fprintf(w, "%.1f\t%.1f\t", namespaceCapacityData[k].TotalRequestsMemoryGiB, namespaceCapacityData[k].TotalLimitsMemoryGiB)
-					if displayEphemeralStorage {
-						fmt.Fprintf(w, "%.1f\t%.1f\t", namespaceCapacityData[k].TotalRequestsEphemeralStorageGB, namespaceCapacityData[k].TotalLimitsEphemeralStorageGB)
+					fmt.Fprintf(w, "%s\t%s\t", readable(namespaceCapacityData[k].TotalRequestsCPUCores, displayOptions), readable(namespaceCapacityData[k].TotalLimitsCPUCores, displayOptions))
+					fmt.Fprintf(w, "%s\t%s\t", readable(capacity.TableMem(namespaceCapacityData[k].TotalRequestsMemoryGiB), displayOptions), readable(capacity.TableMem(namespaceCapacityData[k].TotalLimitsMemoryGiB), displayOptions))
+					if displayOptions.EphemeralStorage {
+						fmt.Fprintf(w, "%s\t%s\t", readable(capacity.TableStorage(namespaceCapacityData[k].TotalRequestsEphemeralStorageGB), displayOptions), readable(capacity.TableStorage(namespaceCapacityData[k].TotalLimitsEphemeralStorageGB), displayOptions))
 					}
-					fmt.Fprintln(w, "")
 				}
+				fmt.Fprintf(w, "%d\t%d\t", namespaceCapacityData[k].TotalContainerCount, namespaceCapacityData[k].TotalInitContainerCount)
+				if displayOptions.Phases {
+					printPhases(w, namespaceCapacityData[k].RunningPodCount, namespaceCapacityData[k].PendingPodCount, namespaceCapacityData[k].SucceededPodCount, namespaceCapacityData[k].FailedPodCount)
+				}
+				if displayOptions.Stats {
+					fmt.Fprintf(w, "%s\t", readable(namespaceCapacityData[k].AvgContainersPerPod, displayOptions))
+					if displayOptions.Default {
+						avgCPU := resource.NewMilliQuantity(int64(math.Round(namespaceCapacityData[k].AvgCPURequestPerPod*1000)), resource.DecimalSI)
+						avgMemory := resource.NewQuantity(int64(math.Round(namespaceCapacityData[k].AvgMemoryRequestPerPod*(1<<30))), resource.BinarySI)
+						fmt.Fprintf(w, "%s\t%s\t", avgCPU, avgMemory)
+					} else {
+						fmt.Fprintf(w, "%s\t%s\t", readable(namespaceCapacityData[k].AvgCPURequestPerPod, displayOptions), readable(capacity.TableMem(namespaceCapacityData[k].AvgMemoryRequestPerPod), displayOptions))
+					}
+				}
+				if displayOptions.Ratios {
+					printRatio(w, namespaceCapacityData[k].CPULimitRequestRatio, namespaceCapacityData[k].TotalRequestsCPU)
+					printRatio(w, namespaceCapacityData[k].MemoryLimitRequestRatio, namespaceCapacityData[k].TotalRequestsMemory)
+				}
+				if displayOptions.ClusterPercent {
+					fmt.Fprintf(w, "%s\t%s\t", readable(namespaceCapacityData[k].RequestsCPUClusterPct, displayOptions), readable(namespaceCapacityData[k].RequestsMemoryClusterPct, displayOptions))
+				}
+				if displayOptions.NodeSpread {
+					fmt.Fprintf(w, "%d\t", namespaceCapacityData[k].DistinctNodeCount)
+				}
+				if displayOptions.Quota {
+					if namespaceCapacityData[k].HasPodQuota {
+						fmt.Fprintf(w, "%d\t%d\t", namespaceCapacityData[k].PodQuotaHard, namespaceCapacityData[k].PodQuotaUsed)
+					} else {
+						fmt.Fprintf(w, "-\t-\t")
+					}
+				}
+				fmt.Fprintln(w, "")
+			}
+		}
+		w.Flush()
+	}
+}
+
+func DisplayRegistryData(out io.Writer, registryCapacityData map[string]*RegistryCapacityData, sortedRegistryNames []string, displayOptions DisplayOptions) {
+	switch displayOptions.Format {
+	case jsonDisplay:
+		jsonRegistryData, err := marshalJSON(withMetadata(&registryCapacityData, displayOptions), displayOptions.Compact)
+		if err != nil {
+			fmt.Fprintln(out, err)
+			return
+		}
+		fmt.Fprintln(out, string(jsonRegistryData))
+	case yamlDisplay:
+		yamlRegistryData, err := yaml.Marshal(withMetadata(registryCapacityData, displayOptions))
+		if err != nil {
+			fmt.Fprintln(out, err)
+			return
+		}
+		fmt.Fprint(out, string(yamlRegistryData))
+	case openMetricsDisplay, jsonlDisplay, customColumnsDisplay:
+		rows := make([]metricsRow, 0, len(sortedRegistryNames))
+		for _, k := range sortedRegistryNames {
+			rows = append(rows, metricsRow{labelValue: k, data: reflect.ValueOf(*registryCapacityData[k])})
+		}
+		writeRows(out, displayOptions, "registry", "registry", rows)
+	default:
+		w := newTableWriter(out, displayOptions, 2)
+		if displayOptions.Headers {
+			if displayOptions.Default {
+				fmt.Fprintln(w, "REGISTRY\tCONTAINERS\tCPU\t\tMEMORY\t")
+			} else {
+				fmt.Fprintf(w, "REGISTRY\tCONTAINERS\tCPU (cores)\t\tMEMORY (%s)\t\n", capacity.MemoryUnit())
+			}
+			fmt.Fprintln(w, "\t\tRequests\tLimits\tRequests\tLimits")
+		}
+		for _, k := range sortedRegistryNames {
+			fmt.Fprintf(w, "%s\t", k)
+			fmt.Fprintf(w, "%d\t", registryCapacityData[k].TotalContainerCount)
+			if displayOptions.Default {
+				fmt.Fprintf(w, "%s\t%s\t", &registryCapacityData[k].TotalRequestsCPU, &registryCapacityData[k].TotalLimitsCPU)
+				fmt.Fprintf(w, "%s\t%s\t", &registryCapacityData[k].TotalRequestsMemory, &registryCapacityData[k].TotalLimitsMemory)
+			} else {
+				fmt.Fprintf(w, "%s\t%s\t", readable(registryCapacityData[k].TotalRequestsCPUCores, displayOptions), readable(registryCapacityData[k].TotalLimitsCPUCores, displayOptions))
+				fmt.Fprintf(w, "%s\t%s\t", readable(capacity.TableMem(registryCapacityData[k].TotalRequestsMemoryGiB), displayOptions), readable(capacity.TableMem(registryCapacityData[k].TotalLimitsMemoryGiB), displayOptions))
+			}
+			fmt.Fprintln(w, "")
+		}
+		w.Flush()
+	}
+}
+
+func DisplayImageData(out io.Writer, imageCapacityData map[string]*ImageCapacityData, sortedNodeNames []string, displayOptions DisplayOptions) {
+	switch displayOptions.Format {
+	case jsonDisplay:
+		jsonImageData, err := marshalJSON(withMetadata(&imageCapacityData, displayOptions), displayOptions.Compact)
+		if err != nil {
+			fmt.Fprintln(out, err)
+			return
+		}
+		fmt.Fprintln(out, string(jsonImageData))
+	case yamlDisplay:
+		yamlImageData, err := yaml.Marshal(withMetadata(imageCapacityData, displayOptions))
+		if err != nil {
+			fmt.Fprintln(out, err)
+			return
+		}
+		fmt.Fprint(out, string(yamlImageData))
+	case openMetricsDisplay, jsonlDisplay, customColumnsDisplay:
+		rows := make([]metricsRow, 0, len(sortedNodeNames))
+		for _, k := range sortedNodeNames {
+			rows = append(rows, metricsRow{labelValue: k, data: reflect.ValueOf(*imageCapacityData[k])})
+		}
+		writeRows(out, displayOptions, "image", "node", rows)
+	default:
+		w := newTableWriter(out, displayOptions, 1)
+		if displayOptions.Headers {
+			fmt.Fprintln(w, "NODE\tCONTAINERS\tUNIQUE IMAGES\t")
+		}
+		for _, k := range sortedNodeNames {
+			fmt.Fprintf(w, "%s\t%d\t%d\t\n", k, imageCapacityData[k].TotalContainerCount, imageCapacityData[k].UniqueImageCount)
+		}
+		w.Flush()
+	}
+}
+
+func DisplayStorageData(out io.Writer, storageCapacityData map[string]*StorageCapacityData, sortedStorageClassNames []string, displayOptions DisplayOptions) {
+	switch displayOptions.Format {
+	case jsonDisplay:
+		jsonStorageData, err := marshalJSON(withMetadata(&storageCapacityData, displayOptions), displayOptions.Compact)
+		if err != nil {
+			fmt.Fprintln(out, err)
+			return
+		}
+		fmt.Fprintln(out, string(jsonStorageData))
+	case yamlDisplay:
+		yamlStorageData, err := yaml.Marshal(withMetadata(storageCapacityData, displayOptions))
+		if err != nil {
+			fmt.Fprintln(out, err)
+			return
+		}
+		fmt.Fprint(out, string(yamlStorageData))
+	case openMetricsDisplay, jsonlDisplay, customColumnsDisplay:
+		rows := make([]metricsRow, 0, len(sortedStorageClassNames))
+		for _, k := range sortedStorageClassNames {
+			rows = append(rows, metricsRow{labelValue: k, data: reflect.ValueOf(*storageCapacityData[k])})
+		}
+		writeRows(out, displayOptions, "storage", "storage_class", rows)
+	default:
+		w := newTableWriter(out, displayOptions, 2)
+		if displayOptions.Headers {
+			if displayOptions.Default {
+				fmt.Fprintln(w, "STORAGECLASS\tPVCS\t\t\t\tPVS\t")
+			} else {
+				fmt.Fprintf(w, "STORAGECLASS\tPVCS (%s)\t\t\t\tPVS (%s)\t\n", capacity.StorageUnit(), capacity.StorageUnit())
+			}
+			fmt.Fprintln(w, "\tTotal\tBound\tPending\tRequests\tTotal\tCapacity")
+		}
+		for _, k := range sortedStorageClassNames {
+			fmt.Fprintf(w, "%s\t", k)
+			fmt.Fprintf(w, "%d\t%d\t%d\t", storageCapacityData[k].TotalPVCCount, storageCapacityData[k].BoundPVCCount, storageCapacityData[k].PendingPVCCount)
+			if displayOptions.Default {
+				fmt.Fprintf(w, "%s\t%d\t%s\t", &storageCapacityData[k].TotalRequestsStorage, storageCapacityData[k].TotalPVCount, &storageCapacityData[k].TotalCapacityStorage)
+			} else {
+				fmt.Fprintf(w, "%s\t%d\t%s\t", readable(capacity.TableStorage(storageCapacityData[k].TotalRequestsStorageGB), displayOptions), storageCapacityData[k].TotalPVCount, readable(capacity.TableStorage(storageCapacityData[k].TotalCapacityStorageGB), displayOptions))
+			}
+			fmt.Fprintln(w, "")
+		}
+		w.Flush()
+	}
+}
+
+func DisplayPodData(out io.Writer, podCapacityData map[string]*PodCapacityData, sortedPodNames []string, displayOptions DisplayOptions) {
+	switch displayOptions.Format {
+	case jsonDisplay:
+		jsonPodData, err := marshalJSON(withMetadata(&podCapacityData, displayOptions), displayOptions.Compact)
+		if err != nil {
+			fmt.Fprintln(out, err)
+			return
+		}
+		fmt.Fprintln(out, string(jsonPodData))
+	case yamlDisplay:
+		yamlPodData, err := yaml.Marshal(withMetadata(podCapacityData, displayOptions))
+		if err != nil {
+			fmt.Fprintln(out, err)
+			return
+		}
+		fmt.Fprint(out, string(yamlPodData))
+	case openMetricsDisplay, jsonlDisplay, customColumnsDisplay:
+		rows := make([]metricsRow, 0, len(sortedPodNames))
+		for _, k := range sortedPodNames {
+			rows = append(rows, metricsRow{labelValue: k, data: reflect.ValueOf(*podCapacityData[k])})
+		}
+		writeRows(out, displayOptions, "pod", "pod", rows)
+	default:
+		w := newTableWriter(out, displayOptions, 2)
+		if displayOptions.Headers {
+			if displayOptions.Default {
+				fmt.Fprintln(w, "NAMESPACE\tNAME\tNODE\tPHASE\tCPU\t\tMEMORY\t")
+			} else {
+				fmt.Fprintf(w, "NAMESPACE\tNAME\tNODE\tPHASE\tCPU (cores)\t\tMEMORY (%s)\t\n", capacity.MemoryUnit())
+			}
+			fmt.Fprintln(w, "\t\t\t\tRequests\tLimits\tRequests\tLimits")
+		}
+		for _, k := range sortedPodNames {
+			podData := podCapacityData[k]
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t", podData.Namespace, podData.Name, podData.Node, podData.Phase)
+			if displayOptions.Default {
+				fmt.Fprintf(w, "%s\t%s\t", &podData.TotalRequestsCPU, &podData.TotalLimitsCPU)
+				fmt.Fprintf(w, "%s\t%s\t", &podData.TotalRequestsMemory, &podData.TotalLimitsMemory)
+			} else {
+				fmt.Fprintf(w, "%s\t%s\t", readable(podData.TotalRequestsCPUCores, displayOptions), readable(podData.TotalLimitsCPUCores, displayOptions))
+				fmt.Fprintf(w, "%s\t%s\t", readable(capacity.TableMem(podData.TotalRequestsMemoryGiB), displayOptions), readable(capacity.TableMem(podData.TotalLimitsMemoryGiB), displayOptions))
+			}
+			fmt.Fprintln(w, "")
+		}
+		w.Flush()
+	}
+}
+
+func DisplayDeploymentData(out io.Writer, deploymentCapacityData map[string]*DeploymentCapacityData, sortedDeploymentNames []string, displayOptions DisplayOptions) {
+	switch displayOptions.Format {
+	case jsonDisplay:
+		jsonDeploymentData, err := marshalJSON(withMetadata(&deploymentCapacityData, displayOptions), displayOptions.Compact)
+		if err != nil {
+			fmt.Fprintln(out, err)
+			return
+		}
+		fmt.Fprintln(out, string(jsonDeploymentData))
+	case yamlDisplay:
+		yamlDeploymentData, err := yaml.Marshal(withMetadata(deploymentCapacityData, displayOptions))
+		if err != nil {
+			fmt.Fprintln(out, err)
+			return
+		}
+		fmt.Fprint(out, string(yamlDeploymentData))
+	case openMetricsDisplay, jsonlDisplay, customColumnsDisplay:
+		rows := make([]metricsRow, 0, len(sortedDeploymentNames))
+		for _, k := range sortedDeploymentNames {
+			rows = append(rows, metricsRow{labelValue: k, data: reflect.ValueOf(*deploymentCapacityData[k])})
+		}
+		writeRows(out, displayOptions, "deployment", "deployment", rows)
+	default:
+		w := newTableWriter(out, displayOptions, 2)
+		if displayOptions.Headers {
+			if displayOptions.Default {
+				fmt.Fprintln(w, "NAMESPACE\tNAME\tPODS\t\tCPU\t\tMEMORY\t")
+			} else {
+				fmt.Fprintf(w, "NAMESPACE\tNAME\tPODS\t\tCPU (cores)\t\tMEMORY (%s)\t\n", capacity.MemoryUnit())
+			}
+			fmt.Fprintln(w, "\t\tTotal\tNon-Term\tRequests\tLimits\tRequests\tLimits")
+		}
+		for _, k := range sortedDeploymentNames {
+			deploymentData := deploymentCapacityData[k]
+			fmt.Fprintf(w, "%s\t%s\t", deploymentData.Namespace, deploymentData.Name)
+			fmt.Fprintf(w, "%d\t%d\t", deploymentData.TotalPodCount, deploymentData.TotalNonTermPodCount)
+			if displayOptions.Default {
+				fmt.Fprintf(w, "%s\t%s\t", &deploymentData.TotalRequestsCPU, &deploymentData.TotalLimitsCPU)
+				fmt.Fprintf(w, "%s\t%s\t", &deploymentData.TotalRequestsMemory, &deploymentData.TotalLimitsMemory)
+			} else {
+				fmt.Fprintf(w, "%s\t%s\t", readable(deploymentData.TotalRequestsCPUCores, displayOptions), readable(deploymentData.TotalLimitsCPUCores, displayOptions))
+				fmt.Fprintf(w, "%s\t%s\t", readable(capacity.TableMem(deploymentData.TotalRequestsMemoryGiB), displayOptions), readable(capacity.TableMem(deploymentData.TotalLimitsMemoryGiB), displayOptions))
+			}
+			fmt.Fprintln(w, "")
+		}
+		w.Flush()
+	}
+}
+
+func DisplayWorkloadData(out io.Writer, workloadCapacityData map[string]*WorkloadCapacityData, sortedWorkloadNames []string, displayOptions DisplayOptions) {
+	switch displayOptions.Format {
+	case jsonDisplay:
+		jsonWorkloadData, err := marshalJSON(withMetadata(&workloadCapacityData, displayOptions), displayOptions.Compact)
+		if err != nil {
+			fmt.Fprintln(out, err)
+			return
+		}
+		fmt.Fprintln(out, string(jsonWorkloadData))
+	case yamlDisplay:
+		yamlWorkloadData, err := yaml.Marshal(withMetadata(workloadCapacityData, displayOptions))
+		if err != nil {
+			fmt.Fprintln(out, err)
+			return
+		}
+		fmt.Fprint(out, string(yamlWorkloadData))
+	case openMetricsDisplay, jsonlDisplay, customColumnsDisplay:
+		rows := make([]metricsRow, 0, len(sortedWorkloadNames))
+		for _, k := range sortedWorkloadNames {
+			rows = append(rows, metricsRow{labelValue: k, data: reflect.ValueOf(*workloadCapacityData[k])})
+		}
+		writeRows(out, displayOptions, "workload", "workload", rows)
+	default:
+		w := newTableWriter(out, displayOptions, 2)
+		if displayOptions.Headers {
+			if displayOptions.Default {
+				fmt.Fprintln(w, "NAMESPACE\tKIND\tNAME\tPODS\tCPU\tMEMORY")
+			} else {
+				fmt.Fprintf(w, "NAMESPACE\tKIND\tNAME\tPODS\tCPU (cores)\tMEMORY (%s)\n", capacity.MemoryUnit())
+			}
+			fmt.Fprintln(w, "\t\t\tNon-Term\tRequests\tRequests")
+		}
+		for _, k := range sortedWorkloadNames {
+			workloadData := workloadCapacityData[k]
+			fmt.Fprintf(w, "%s\t%s\t%s\t", workloadData.Namespace, workloadData.Kind, workloadData.Name)
+			fmt.Fprintf(w, "%d\t", workloadData.TotalPodCount)
+			if displayOptions.Default {
+				fmt.Fprintf(w, "%s\t%s\t", &workloadData.TotalRequestsCPU, &workloadData.TotalRequestsMemory)
+			} else {
+				fmt.Fprintf(w, "%s\t%s\t", readable(workloadData.TotalRequestsCPUCores, displayOptions), readable(capacity.TableMem(workloadData.TotalRequestsMemoryGiB), displayOptions))
+			}
+			fmt.Fprintln(w, "")
+		}
+		w.Flush()
+	}
+}
+
+func DisplayWindowData(out io.Writer, windowCapacityData WindowCapacityData, displayOptions DisplayOptions) {
+	switch displayOptions.Format {
+	case jsonDisplay:
+		jsonWindowData, err := marshalJSON(withMetadata(&windowCapacityData, displayOptions), displayOptions.Compact)
+		if err != nil {
+			fmt.Fprintln(out, err)
+			return
+		}
+		fmt.Fprintln(out, string(jsonWindowData))
+	case yamlDisplay:
+		yamlWindowData, err := yaml.Marshal(withMetadata(windowCapacityData, displayOptions))
+		if err != nil {
+			fmt.Fprintln(out, err)
+			return
+		}
+		fmt.Fprint(out, string(yamlWindowData))
+	case openMetricsDisplay, jsonlDisplay, customColumnsDisplay:
+		writeRows(out, displayOptions, "window", "", []metricsRow{{data: reflect.ValueOf(windowCapacityData)}})
+	default:
+		w := newTableWriter(out, displayOptions, 2)
+		if displayOptions.Headers {
+			if displayOptions.Default {
+				fmt.Fprintln(w, "WINDOW\tSNAPSHOTS\tNODES\tCPU\t\tMEMORY\t")
+			} else {
+				fmt.Fprintf(w, "WINDOW\tSNAPSHOTS\tNODES\tCPU (cores)\t\tMEMORY (%s)\t\n", capacity.MemoryUnit())
+			}
+			fmt.Fprintln(w, "\t\t\tAllocatable\tAvailable\tAllocatable\tAvailable")
+		}
+		wd := &windowCapacityData
+		if displayOptions.Default {
+			fmt.Fprintf(w, "min\t%d\t%d\t%s\t%s\t%s\t%s\n", wd.SnapshotCount, wd.MinNodeCount, &wd.MinAllocatableCPU, &wd.MinAvailableCPU, &wd.MinAllocatableMemory, &wd.MinAvailableMemory)
+			fmt.Fprintf(w, "max\t%d\t%d\t%s\t%s\t%s\t%s\n", wd.SnapshotCount, wd.MaxNodeCount, &wd.MaxAllocatableCPU, &wd.MaxAvailableCPU, &wd.MaxAllocatableMemory, &wd.MaxAvailableMemory)
+			fmt.Fprintf(w, "avg\t%d\t%.1f\t%s\t%s\t%s\t%s\n", wd.SnapshotCount, wd.AvgNodeCount, &wd.AvgAllocatableCPU, &wd.AvgAvailableCPU, &wd.AvgAllocatableMemory, &wd.AvgAvailableMemory)
+		} else {
+			fmt.Fprintf(w, "min\t%d\t%d\t%s\t%s\t%s\t%s\n", wd.SnapshotCount, wd.MinNodeCount, readable(wd.MinAllocatableCPUCores, displayOptions), readable(wd.MinAvailableCPUCores, displayOptions), readable(capacity.TableMem(wd.MinAllocatableMemoryGiB), displayOptions), readable(capacity.TableMem(wd.MinAvailableMemoryGiB), displayOptions))
+			fmt.Fprintf(w, "max\t%d\t%d\t%s\t%s\t%s\t%s\n", wd.SnapshotCount, wd.MaxNodeCount, readable(wd.MaxAllocatableCPUCores, displayOptions), readable(wd.MaxAvailableCPUCores, displayOptions), readable(capacity.TableMem(wd.MaxAllocatableMemoryGiB), displayOptions), readable(capacity.TableMem(wd.MaxAvailableMemoryGiB), displayOptions))
+			fmt.Fprintf(w, "avg\t%d\t%.1f\t%s\t%s\t%s\t%s\n", wd.SnapshotCount, wd.AvgNodeCount, readable(wd.AvgAllocatableCPUCores, displayOptions), readable(wd.AvgAvailableCPUCores, displayOptions), readable(capacity.TableMem(wd.AvgAllocatableMemoryGiB), displayOptions), readable(capacity.TableMem(wd.AvgAvailableMemoryGiB), displayOptions))
+		}
+		w.Flush()
+	}
+}
+
+func DisplayDiagnosisData(out io.Writer, podDiagnosisData map[string]*PodDiagnosisData, sortedPodNames []string, displayOptions DisplayOptions) {
+	switch displayOptions.Format {
+	case jsonDisplay:
+		jsonDiagnosisData, err := marshalJSON(withMetadata(&podDiagnosisData, displayOptions), displayOptions.Compact)
+		if err != nil {
+			fmt.Fprintln(out, err)
+			return
+		}
+		fmt.Fprintln(out, string(jsonDiagnosisData))
+	case yamlDisplay:
+		yamlDiagnosisData, err := yaml.Marshal(withMetadata(podDiagnosisData, displayOptions))
+		if err != nil {
+			fmt.Fprintln(out, err)
+			return
+		}
+		fmt.Fprint(out, string(yamlDiagnosisData))
+	case openMetricsDisplay, jsonlDisplay, customColumnsDisplay:
+		rows := make([]metricsRow, 0, len(sortedPodNames))
+		for _, k := range sortedPodNames {
+			rows = append(rows, metricsRow{labelValue: k, data: reflect.ValueOf(*podDiagnosisData[k])})
+		}
+		writeRows(out, displayOptions, "diagnose", "pod", rows)
+	default:
+		w := newTableWriter(out, displayOptions, 1)
+		if displayOptions.Headers {
+			if displayOptions.Default {
+				fmt.Fprintln(w, "NAMESPACE\tPOD\tCPU\tMEMORY\tHINTS")
+			} else {
+				fmt.Fprintf(w, "NAMESPACE\tPOD\tCPU (cores)\tMEMORY (%s)\tHINTS\n", capacity.MemoryUnit())
+			}
+		}
+		for _, k := range sortedPodNames {
+			podData := podDiagnosisData[k]
+			fmt.Fprintf(w, "%s\t%s\t", podData.Namespace, podData.Pod)
+			if displayOptions.Default {
+				fmt.Fprintf(w, "%s\t%s\t", &podData.RequestsCPU, &podData.RequestsMemory)
+			} else {
+				fmt.Fprintf(w, "%s\t%s\t", readable(podData.RequestsCPUCores, displayOptions), readable(capacity.TableMem(podData.RequestsMemoryGiB), displayOptions))
+			}
+			for i, hint := range podData.Hints {
+				if i > 0 {
+					fmt.Fprint(w, "\t\t\t\t")
+				}
+				fmt.Fprintln(w, hint)
+			}
+			if len(podData.Hints) == 0 {
+				fmt.Fprintln(w, "")
+			}
+		}
+		w.Flush()
+	}
+}
+
+func DisplayJobsData(out io.Writer, jobsData JobsData, sortedCronJobNames []string, sortedJobNames []string, displayOptions DisplayOptions) {
+	switch displayOptions.Format {
+	case jsonDisplay:
+		jsonJobsData, err := marshalJSON(withMetadata(&jobsData, displayOptions), displayOptions.Compact)
+		if err != nil {
+			fmt.Fprintln(out, err)
+			return
+		}
+		fmt.Fprintln(out, string(jsonJobsData))
+	case yamlDisplay:
+		yamlJobsData, err := yaml.Marshal(withMetadata(jobsData, displayOptions))
+		if err != nil {
+			fmt.Fprintln(out, err)
+			return
+		}
+		fmt.Fprint(out, string(yamlJobsData))
+	case openMetricsDisplay, jsonlDisplay:
+		cronJobRows := make([]metricsRow, 0, len(sortedCronJobNames))
+		for _, k := range sortedCronJobNames {
+			cronJobRows = append(cronJobRows, metricsRow{labelValue: k, data: reflect.ValueOf(*jobsData.CronJobs[k])})
+		}
+		jobRows := make([]metricsRow, 0, len(sortedJobNames))
+		for _, k := range sortedJobNames {
+			jobRows = append(jobRows, metricsRow{labelValue: k, data: reflect.ValueOf(*jobsData.Jobs[k])})
+		}
+		if displayOptions.Format == jsonlDisplay {
+			writeJSONLines(out, "cronjob", cronJobRows)
+			writeJSONLines(out, "job", jobRows)
+			return
+		}
+		writeMetricFamilies(out, "cronjob", "cronjob", cronJobRows)
+		writeMetricFamilies(out, "job", "job", jobRows)
+		fmt.Fprintln(out, "# EOF")
+	default:
+		w := newTableWriter(out, displayOptions, 1)
+		if displayOptions.Headers {
+			fmt.Fprintln(w, "NAMESPACE\tCRONJOB\tSCHEDULE\tSUSPENDED\tACTIVE\tLAST SCHEDULE")
+		}
+		for _, k := range sortedCronJobNames {
+			cronJob := jobsData.CronJobs[k]
+			lastSchedule := "<none>"
+			if cronJob.LastScheduleTime != nil {
+				lastSchedule = duration.HumanDuration(time.Since(*cronJob.LastScheduleTime))
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%t\t%d\t%s\n", cronJob.Namespace, cronJob.Name, cronJob.Schedule, cronJob.Suspended, cronJob.ActiveJobCount, lastSchedule)
+		}
+		w.Flush()
+
+		fmt.Fprintln(out, "")
+		w = newTableWriter(out, displayOptions, 1)
+		if displayOptions.Headers {
+			fmt.Fprintln(w, "NAMESPACE\tJOB\tCOMPLETIONS\tACTIVE\tSUCCEEDED\tFAILED")
+		}
+		for _, k := range sortedJobNames {
+			job := jobsData.Jobs[k]
+			completions := "-"
+			if job.Completions > 0 {
+				completions = strconv.Itoa(job.Completions)
 			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%d\t%d\n", job.Namespace, job.Name, completions, job.Active, job.Succeeded, job.Failed)
 		}
 		w.Flush()
 	}
 }
 
+// tableWriter renders the tab separated cells written by the display functions, tables are aligned by a tabwriter and
+// markdown is rendered once flushed
+type tableWriter interface {
+	io.Writer
+	Flush() error
+}
+
+// newTableWriter returns the tableWriter of the output format, headerRows is the number of header rows the display
+// function prints which markdown output merges into a single header row
+func newTableWriter(out io.Writer, displayOptions DisplayOptions, headerRows int) tableWriter {
+	if displayOptions.Format == markdownDisplay {
+		if !displayOptions.Headers {
+			headerRows = 0
+		}
+		return &markdownWriter{out: out, headerRows: headerRows}
+	}
+	w := new(tabwriter.Writer)
+	w.Init(out, 0, 5, 1, ' ', 0)
+	return w
+}
+
+// withMetadata wraps v in an Envelope unless metadata is disabled
+func withMetadata(v interface{}, displayOptions DisplayOptions) interface{} {
+	if displayOptions.Metadata == nil {
+		return v
+	}
+	return Envelope{Metadata: *displayOptions.Metadata, Data: v}
+}
+
+// UnwrapEnvelope returns the data of json output wrapped in an Envelope, json without an Envelope (Ex without --metadata)
+// is returned unchanged
+func UnwrapEnvelope(data []byte) ([]byte, error) {
+	envelope := struct {
+		Metadata *Metadata
+		Data     json.RawMessage
+	}{}
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return nil, err
+	}
+	if envelope.Metadata == nil || envelope.Data == nil {
+		return data, nil
+	}
+	return envelope.Data, nil
+}
+
+// ErrorData is the json and yaml output of a failed command, Code is the Kubernetes API status reason (Ex Forbidden)
+// or Unknown for errors not returned by the API server
+type ErrorData struct {
+	Error string `json:"error"`
+	Code  string `json:"code"`
+}
+
+// DisplayError writes err as an ErrorData object in the json or yaml format
+func DisplayError(out io.Writer, err error, format string) {
+	errorData := ErrorData{Error: err.Error(), Code: string(apierrors.ReasonForError(err))}
+	if errorData.Code == "" {
+		errorData.Code = "Unknown"
+	}
+	if format == yamlDisplay {
+		yamlErrorData, _ := yaml.Marshal(errorData)
+		fmt.Fprint(out, string(yamlErrorData))
+		return
+	}
+	jsonErrorData, _ := json.Marshal(errorData)
+	fmt.Fprintln(out, string(jsonErrorData))
+}
+
+func marshalJSON(v interface{}, compact bool) ([]byte, error) {
+	if compact {
+		return json.Marshal(v)
+	}
+	return json.MarshalIndent(v, "", "  ")
+}
+
 func ValidateOutput(cmd cobra.Command) error {
 	displayFormat, err := cmd.Flags().GetString("output")
 	if err != nil {
 		return fmt.Errorf("unable to get output display format")
 	}
-	validOutputs := []string{tableDisplay, jsonDisplay, yamlDisplay}
+	if strings.HasPrefix(displayFormat, customColumnsPrefix) {
+		return validateCustomColumns(cmd, strings.TrimPrefix(displayFormat, customColumnsPrefix))
+	}
+	validOutputs := []string{tableDisplay, jsonDisplay, yamlDisplay, jsonlDisplay, openMetricsDisplay, markdownDisplay, customColumnsPrefix + "..."}
 	for _, validOutputFormat := range validOutputs {
 		if displayFormat == validOutputFormat {
 			return nil
@@ -0,0 +1,377 @@
+/*
+Copyright © 2021 Alex Krzos akrzos@redhat.com
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// tableRow returns the whitespace separated fields of the table row starting with name
+func tableRow(t *testing.T, table, name string) []string {
+	t.Helper()
+	for _, line := range strings.Split(table, "\n") {
+		if fields := strings.Fields(line); len(fields) > 0 && fields[0] == name {
+			return fields
+		}
+	}
+	t.Fatalf("no %s row in table output:\n%s", name, table)
+	return nil
+}
+
+func TestDisplayNodeDataAllocatablePods(t *testing.T) {
+	nodesCapacityData := map[string]*NodeCapacityData{
+		"worker-0": {
+			Roles:                sets.NewString("worker"),
+			Ready:                true,
+			Schedulable:          true,
+			TotalCapacityPods:    resource.MustParse("250"),
+			TotalAllocatablePods: resource.MustParse("110"),
+			TotalNonTermPodCount: 10,
+			TotalPodCount:        12,
+			TotalAvailablePods:   100,
+		},
+	}
+	var out bytes.Buffer
+	DisplayNodeData(&out, nodesCapacityData, []string{"worker-0"}, nil, DisplayOptions{Format: tableDisplay, Headers: true})
+
+	// NAME STATUS ROLES Capacity Allocatable Total Non-Term Avail
+	row := tableRow(t, out.String(), "worker-0")
+	if row[3] != "250" {
+		t.Errorf("pods Capacity = %s, want 250", row[3])
+	}
+	if row[4] != "110" {
+		t.Errorf("pods Allocatable = %s, want 110", row[4])
+	}
+	if row[7] != "100" {
+		t.Errorf("pods Avail = %s, want 100", row[7])
+	}
+}
+
+func TestDisplayNamespaceDataMinPods(t *testing.T) {
+	namespaceCapacityData := map[string]*NamespaceCapacityData{
+		"small":   {TotalPodCount: 2, TotalNonTermPodCount: 2},
+		"large":   {TotalPodCount: 8, TotalNonTermPodCount: 8},
+		"*total*": {TotalPodCount: 10, TotalNonTermPodCount: 10},
+	}
+	var out bytes.Buffer
+	DisplayNamespaceData(&out, namespaceCapacityData, []string{"large", "small", "*total*"}, DisplayOptions{Format: tableDisplay, Headers: true, MinPods: 5})
+
+	if strings.Contains(out.String(), "small") {
+		t.Errorf("--min-pods 5 table output contains the 2 pod namespace:\n%s", out.String())
+	}
+	tableRow(t, out.String(), "large")
+	if row := tableRow(t, out.String(), "*total*"); row[1] != "10" {
+		t.Errorf("*total* pods = %s, want 10", row[1])
+	}
+}
+
+func TestDisplayNamespaceDataMarkdown(t *testing.T) {
+	namespaceCapacityData := map[string]*NamespaceCapacityData{
+		"default": {TotalPodCount: 3, TotalNonTermPodCount: 2, TotalRequestsCPUCores: 1.5},
+	}
+	var out bytes.Buffer
+	DisplayNamespaceData(&out, namespaceCapacityData, []string{"default"}, DisplayOptions{Format: markdownDisplay, Precision: 1, Headers: true})
+
+	lines := strings.Split(out.String(), "\n")
+	if len(lines) < 3 {
+		t.Fatalf("markdown output has %d lines, want at least 3:\n%s", len(lines), out.String())
+	}
+	if !strings.HasPrefix(lines[0], "| NAMESPACE | PODS Total | PODS Non-Term | PODS Unassigned | CPU (cores) Requests |") {
+		t.Errorf("markdown header = %q", lines[0])
+	}
+	if !strings.HasPrefix(lines[1], "| --- | --: | --: | --: | --: |") {
+		t.Errorf("markdown divider = %q", lines[1])
+	}
+	if !strings.HasPrefix(lines[2], "| default | 3 | 2 | 0 | 1.5 |") {
+		t.Errorf("markdown row = %q", lines[2])
+	}
+}
+
+func TestJSONSchema(t *testing.T) {
+	schema := JSONSchema(reflect.TypeOf(NodeCapacityData{}), true)
+	if schema["title"] != "NodeCapacityData" {
+		t.Errorf("title = %v, want NodeCapacityData", schema["title"])
+	}
+	node, ok := schema["additionalProperties"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("rows schema has no additionalProperties object schema: %v", schema)
+	}
+	properties := node["properties"].(map[string]interface{})
+	tests := []struct {
+		field string
+		want  interface{}
+	}{
+		{"TotalPodCount", "integer"},
+		{"Ready", "boolean"},
+		{"TotalCapacityCPU", "string"},
+		{"TotalCapacityCPUCores", "number"},
+	}
+	for _, tt := range tests {
+		property, ok := properties[tt.field].(map[string]interface{})
+		if !ok {
+			t.Errorf("no %s property", tt.field)
+			continue
+		}
+		if property["type"] != tt.want {
+			t.Errorf("%s type = %v, want %v", tt.field, property["type"], tt.want)
+		}
+	}
+}
+
+func TestDisplayNodeDataFullNodesFooter(t *testing.T) {
+	nodesCapacityData := map[string]*NodeCapacityData{
+		"worker-0": {RequestsCPUUtilization: 95, RequestsMemoryUtilization: 40},
+		"worker-1": {RequestsCPUUtilization: 50, RequestsMemoryUtilization: 91},
+		"worker-2": {RequestsCPUUtilization: 90, RequestsMemoryUtilization: 90},
+		"*total*":  {RequestsCPUUtilization: 99, RequestsMemoryUtilization: 99},
+	}
+	var out bytes.Buffer
+	DisplayNodeData(&out, nodesCapacityData, []string{"worker-0", "worker-1", "worker-2", "*total*"}, nil, DisplayOptions{Format: tableDisplay, Utilization: true, FullThreshold: 90})
+
+	if want := "Nearly full: 2 of 3 nodes above 90% cpu or memory requests\n"; !strings.HasSuffix(out.String(), want) {
+		t.Errorf("table output does not end with %q:\n%s", want, out.String())
+	}
+}
+
+func TestDisplayClusterDataWide(t *testing.T) {
+	clusterCapacityData := ClusterCapacityData{
+		TotalCapacityCPU:      resource.MustParse("4"),
+		TotalCapacityCPUCores: 4,
+		TotalCapacityMemory:   resource.MustParse("16Gi"),
+	}
+	var out bytes.Buffer
+	DisplayClusterData(&out, clusterCapacityData, DisplayOptions{Format: tableDisplay, Precision: 1, Headers: true, Wide: true})
+
+	lines := strings.Split(out.String(), "\n")
+	if header := strings.Join(strings.Fields(lines[0]), " "); !strings.HasPrefix(header, "NODES PODS CPU CPU (cores) MEMORY MEMORY (GiB)") {
+		t.Errorf("--wide header = %q", header)
+	}
+	// Total Ready Unready Unsch Capacity Allocatable Total Non-Term Avail, then the CPU quantities and readable values
+	fields := strings.Fields(lines[2])
+	if fields[9] != "4" || fields[14] != "4.0" {
+		t.Errorf("--wide cpu capacity = %s and %s, want 4 and 4.0 in row %q", fields[9], fields[14], lines[2])
+	}
+	if fields[19] != "16Gi" {
+		t.Errorf("--wide memory capacity = %s, want 16Gi in row %q", fields[19], lines[2])
+	}
+}
+
+func TestDisplayClusterDataFlat(t *testing.T) {
+	clusterCapacityData := ClusterCapacityData{
+		TotalNodeCount:            3,
+		TotalAllocatablePods:      resource.MustParse("330"),
+		TotalAllocatableCPU:       resource.MustParse("128"),
+		TotalAllocatableCPUCores:  128,
+		TotalAllocatableMemoryGiB: 512,
+		CPUOvercommitted:          true,
+	}
+	var out bytes.Buffer
+	DisplayClusterData(&out, clusterCapacityData, DisplayOptions{Format: jsonDisplay, Compact: true, Flat: true, Metadata: &Metadata{Context: "prod"}})
+
+	flat := make(map[string]interface{})
+	if err := json.Unmarshal(out.Bytes(), &flat); err != nil {
+		t.Fatalf("--flat output is not a json object: %v\n%s", err, out.String())
+	}
+	tests := []struct {
+		key  string
+		want interface{}
+	}{
+		{"total_node_count", 3.0},
+		{"total_allocatable_pods", 330.0},
+		{"total_allocatable_cpu_cores", 128.0},
+		{"total_allocatable_memory_gib", 512.0},
+		{"cpu_overcommitted", true},
+	}
+	for _, tt := range tests {
+		if got := flat[tt.key]; got != tt.want {
+			t.Errorf("%s = %v, want %v", tt.key, got, tt.want)
+		}
+	}
+	for key, value := range flat {
+		if _, ok := value.(map[string]interface{}); ok || key == "total_allocatable_cpu" || key == "Metadata" {
+			t.Errorf("--flat output has nested or duplicate key %s", key)
+		}
+	}
+}
+
+func TestDisplayError(t *testing.T) {
+	forbidden := apierrors.NewForbidden(schema.GroupResource{Resource: "nodes"}, "", errors.New("rbac"))
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{errors.Wrap(forbidden, "failed to list nodes"), "Forbidden"},
+		{errors.New("no kubeconfig found"), "Unknown"},
+	}
+	for _, tt := range tests {
+		var out bytes.Buffer
+		DisplayError(&out, tt.err, jsonDisplay)
+		errorData := make(map[string]string)
+		if err := json.Unmarshal(out.Bytes(), &errorData); err != nil {
+			t.Fatalf("error output is not a json object: %v\n%s", err, out.String())
+		}
+		if errorData["error"] != tt.err.Error() {
+			t.Errorf("error = %q, want %q", errorData["error"], tt.err.Error())
+		}
+		if errorData["code"] != tt.want {
+			t.Errorf("code = %q, want %q", errorData["code"], tt.want)
+		}
+	}
+}
+
+func TestDisplayNodeDataEfficiency(t *testing.T) {
+	nodesCapacityData := map[string]*NodeCapacityData{
+		"worker-0": {
+			Roles:                       sets.NewString("worker"),
+			Ready:                       true,
+			Schedulable:                 true,
+			TotalCapacityCPU:            resource.MustParse("8"),
+			TotalCapacityMemory:         resource.MustParse("32Gi"),
+			AllocatableCPUEfficiency:    87.5,
+			AllocatableMemoryEfficiency: 90,
+		},
+		"*unassigned*": {},
+	}
+	var out bytes.Buffer
+	DisplayNodeData(&out, nodesCapacityData, []string{"worker-0", "*unassigned*"}, nil, DisplayOptions{Format: tableDisplay, Headers: true, Efficiency: true})
+
+	row := tableRow(t, out.String(), "worker-0")
+	if cpu, memory := row[len(row)-2], row[len(row)-1]; cpu != "87.5" || memory != "90.0" {
+		t.Errorf("efficiency = %s and %s, want 87.5 and 90.0", cpu, memory)
+	}
+	if row := tableRow(t, out.String(), "*unassigned*"); row[len(row)-1] != "-" {
+		t.Errorf("*unassigned* memory efficiency = %s, want - without capacity", row[len(row)-1])
+	}
+}
+
+func TestDisplayNodeDataCustomColumns(t *testing.T) {
+	nodesCapacityData := map[string]*NodeCapacityData{
+		"worker-0": {
+			Roles:                  sets.NewString("infra", "worker"),
+			TotalAvailableCPU:      resource.MustParse("3500m"),
+			TotalAvailableCPUCores: 3.5,
+		},
+	}
+	_, columns, err := ParseFormat("custom-columns=NAME:.name,CPU_AVAIL:.TotalAvailableCPUCores,CPU:.TotalAvailableCPU,ROLES:.Roles,GROUP:.TotalNodeCount")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	DisplayNodeData(&out, nodesCapacityData, []string{"worker-0"}, nil, DisplayOptions{Format: customColumnsDisplay, Precision: 2, Headers: true, CustomColumns: columns})
+
+	lines := strings.Split(out.String(), "\n")
+	if header := strings.Join(strings.Fields(lines[0]), " "); header != "NAME CPU_AVAIL CPU ROLES GROUP" {
+		t.Errorf("custom-columns header = %q", header)
+	}
+	if row := strings.Join(strings.Fields(lines[1]), " "); row != "worker-0 3.50 3500m infra,worker <none>" {
+		t.Errorf("custom-columns row = %q", row)
+	}
+}
+
+func TestParseFormatCustomColumnsInvalid(t *testing.T) {
+	for _, format := range []string{"custom-columns=", "custom-columns=NAME", "custom-columns=:.name", "custom-columns=CPU:.Roles.Len"} {
+		if _, _, err := ParseFormat(format); err == nil {
+			t.Errorf("ParseFormat(%q) succeeded, want an error", format)
+		}
+	}
+}
+
+func TestDisplayNodeDataAge(t *testing.T) {
+	created := time.Now().Add(-12 * 24 * time.Hour)
+	nodesCapacityData := map[string]*NodeCapacityData{
+		"worker-0": {Roles: sets.NewString("worker"), Ready: true, Schedulable: true, CreationTimestamp: &created},
+		"*total*":  {},
+	}
+	var out bytes.Buffer
+	DisplayNodeData(&out, nodesCapacityData, []string{"worker-0", "*total*"}, nil, DisplayOptions{Format: tableDisplay, Headers: true, Age: true})
+
+	if header := strings.Fields(strings.Split(out.String(), "\n")[0]); header[3] != "AGE" {
+		t.Errorf("--age header = %v, want AGE after ROLES", header)
+	}
+	// NAME STATUS ROLES AGE
+	if row := tableRow(t, out.String(), "worker-0"); row[3] != "12d" {
+		t.Errorf("worker-0 age = %s, want 12d", row[3])
+	}
+	// The *total* row has an empty STATUS and ROLES
+	if row := tableRow(t, out.String(), "*total*"); row[1] != "-" {
+		t.Errorf("*total* age = %s, want -", row[1])
+	}
+}
+
+func TestDisplayNodeDataVersions(t *testing.T) {
+	nodesCapacityData := map[string]*NodeCapacityData{
+		"worker-0": {Roles: sets.NewString("worker"), KubeletVersion: "v1.21.1", OSImage: "Ubuntu 20.04.2 LTS", KernelVersion: "5.4.0-73-generic"},
+		"worker-1": {Roles: sets.NewString("worker"), KubeletVersion: "v1.20.4", OSImage: "Ubuntu 20.04.2 LTS", KernelVersion: "5.4.0-73-generic"},
+		"worker-2": {Roles: sets.NewString("worker"), KubeletVersion: "v1.21.1", OSImage: "Ubuntu 20.04.2 LTS", KernelVersion: "5.4.0-73-generic"},
+		"*total*":  {},
+	}
+	var out bytes.Buffer
+	DisplayNodeData(&out, nodesCapacityData, []string{"worker-0", "worker-1", "worker-2", "*total*"}, nil, DisplayOptions{Format: tableDisplay, Headers: true, Versions: true})
+
+	// NAME STATUS ROLES VERSION
+	if row := tableRow(t, out.String(), "worker-1"); row[3] != "v1.20.4" {
+		t.Errorf("worker-1 version = %s, want v1.20.4", row[3])
+	}
+	if want := "Kubelet versions: v1.21.1 (2 nodes), v1.20.4 (1 node)\n"; !strings.HasSuffix(out.String(), want) {
+		t.Errorf("table output does not end with %q:\n%s", want, out.String())
+	}
+}
+
+func TestDisplayNamespaceDataStats(t *testing.T) {
+	namespaceCapacityData := map[string]*NamespaceCapacityData{
+		"default": {TotalPodCount: 3, TotalNonTermPodCount: 3, TotalContainerCount: 4, AvgContainersPerPod: 4.0 / 3, AvgCPURequestPerPod: 0.25, AvgMemoryRequestPerPod: 0.5},
+	}
+	tests := []struct {
+		displayOptions DisplayOptions
+		want           []string
+	}{
+		{DisplayOptions{Format: tableDisplay, Precision: 3, Stats: true}, []string{"1.333", "0.250", "0.500"}},
+		{DisplayOptions{Format: tableDisplay, Precision: 1, Default: true, Stats: true}, []string{"1.3", "250m", "512Mi"}},
+	}
+	for _, tt := range tests {
+		var out bytes.Buffer
+		DisplayNamespaceData(&out, namespaceCapacityData, []string{"default"}, tt.displayOptions)
+
+		row := tableRow(t, out.String(), "default")
+		if got := row[len(row)-3:]; !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("--stats with precision %d and default format %v = %v, want %v", tt.displayOptions.Precision, tt.displayOptions.Default, got, tt.want)
+		}
+	}
+}
+
+func TestDisplayNamespaceDataClusterPercentPrecision(t *testing.T) {
+	namespaceCapacityData := map[string]*NamespaceCapacityData{
+		"default": {TotalPodCount: 1, TotalNonTermPodCount: 1, RequestsCPUClusterPct: 0.125, RequestsMemoryClusterPct: 12.5},
+	}
+	var out bytes.Buffer
+	DisplayNamespaceData(&out, namespaceCapacityData, []string{"default"}, DisplayOptions{Format: tableDisplay, Precision: 3, ClusterPercent: true})
+
+	row := tableRow(t, out.String(), "default")
+	if cpu, memory := row[len(row)-2], row[len(row)-1]; cpu != "0.125" || memory != "12.500" {
+		t.Errorf("--cluster-percent with --precision 3 = %s and %s, want 0.125 and 12.500", cpu, memory)
+	}
+}
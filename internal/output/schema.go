@@ -0,0 +1,119 @@
+/*
+Copyright © 2021 Alex Krzos akrzos@redhat.com
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package output
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+const jsonSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+var (
+	schemaQuantityType = reflect.TypeOf(resource.Quantity{})
+	schemaTimeType     = reflect.TypeOf(time.Time{})
+)
+
+// JSONSchema returns a JSON Schema document describing the json output of dataType, rows describes output which is a
+// map of dataType keyed by row name (Ex node or namespace name) rather than a single object
+func JSONSchema(dataType reflect.Type, rows bool) map[string]interface{} {
+	schema := typeSchema(dataType)
+	if rows {
+		schema = map[string]interface{}{
+			"type":                 "object",
+			"additionalProperties": schema,
+		}
+	}
+	schema["$schema"] = jsonSchemaDraft
+	schema["title"] = dataType.Name()
+	return schema
+}
+
+// DisplaySchema prints the JSON Schema document of dataType
+func DisplaySchema(out io.Writer, dataType reflect.Type, rows bool, displayOptions DisplayOptions) {
+	jsonSchema, err := marshalJSON(JSONSchema(dataType, rows), displayOptions.Compact)
+	if err != nil {
+		fmt.Fprintln(out, err)
+		return
+	}
+	fmt.Fprintln(out, string(jsonSchema))
+}
+
+// typeSchema returns the schema of a type as encoding/json marshals it, nil maps and slices marshal as null
+func typeSchema(t reflect.Type) map[string]interface{} {
+	switch t {
+	case schemaQuantityType:
+		return map[string]interface{}{"type": "string", "description": "Kubernetes resource quantity (Ex 500m or 1Gi)"}
+	case schemaTimeType:
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	}
+	switch t.Kind() {
+	case reflect.Ptr:
+		return typeSchema(t.Elem())
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": []string{"array", "null"}, "items": typeSchema(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": []string{"object", "null"}, "additionalProperties": typeSchema(t.Elem())}
+	case reflect.Struct:
+		properties := make(map[string]interface{})
+		required := make([]string, 0, t.NumField())
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if field.PkgPath != "" {
+				continue
+			}
+			name, omitEmpty := field.Name, false
+			if tag, ok := field.Tag.Lookup("json"); ok {
+				if tag == "-" {
+					continue
+				}
+				options := strings.Split(tag, ",")
+				if options[0] != "" {
+					name = options[0]
+				}
+				for _, option := range options[1:] {
+					omitEmpty = omitEmpty || option == "omitempty"
+				}
+			}
+			properties[name] = typeSchema(field.Type)
+			if !omitEmpty {
+				required = append(required, name)
+			}
+		}
+		return map[string]interface{}{
+			"type":                 "object",
+			"properties":           properties,
+			"required":             required,
+			"additionalProperties": false,
+		}
+	}
+	// interface{} and other kinds accept any value
+	return map[string]interface{}{}
+}